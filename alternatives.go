@@ -0,0 +1,48 @@
+package go3mf
+
+// An Alternative references an object that can replace the object it belongs to,
+// such as a lighter mesh for previews. It is part of the Production extension to 3MF.
+// An empty Path references an object of the same model file.
+type Alternative struct {
+	ObjectID        uint32
+	UUID            string
+	Path            string
+	ModelResolution SliceResolution
+}
+
+// FindAlternative returns the first alternative object of o with the target resolution.
+// If there is no such alternative in the model it returns o and false,
+// so o can be used as a fallback.
+func (m *Model) FindAlternative(o Object, resolution SliceResolution) (Object, bool) {
+	if r, ok := objectResource(o); ok {
+		for _, alt := range r.Alternatives {
+			if alt.ModelResolution != resolution {
+				continue
+			}
+			path := alt.Path
+			if path == "" {
+				path = r.ModelPath
+			}
+			if res, ok := m.FindResource(path, alt.ObjectID); ok {
+				if obj, ok := res.(Object); ok {
+					return obj, true
+				}
+			}
+		}
+	}
+	return o, false
+}
+
+func objectResource(o Object) (*ObjectResource, bool) {
+	switch o := o.(type) {
+	case *MeshResource:
+		return &o.ObjectResource, true
+	case *ComponentsResource:
+		return &o.ObjectResource, true
+	case *DisplacementMeshResource:
+		return &o.ObjectResource, true
+	case *BooleanShapeResource:
+		return &o.ObjectResource, true
+	}
+	return nil, false
+}
@@ -0,0 +1,44 @@
+package go3mf
+
+import "testing"
+
+func TestModel_FindAlternative(t *testing.T) {
+	low := &MeshResource{ObjectResource: ObjectResource{ID: 2, ModelPath: "/3D/low.model"}}
+	sameFile := &ComponentsResource{ObjectResource: ObjectResource{ID: 3, ModelPath: "/3D/3dmodel.model"}}
+	full := &MeshResource{ObjectResource: ObjectResource{ID: 1, ModelPath: "/3D/3dmodel.model", Alternatives: []Alternative{
+		{ObjectID: 5, Path: "/3D/low.model", ModelResolution: ResolutionLow},
+		{ObjectID: 2, Path: "/3D/low.model", ModelResolution: ResolutionLow},
+		{ObjectID: 3, ModelResolution: ResolutionFull},
+	}}}
+	base := &BaseMaterialsResource{ID: 4, ModelPath: "/3D/3dmodel.model"}
+	other := &DisplacementMeshResource{ObjectResource: ObjectResource{ID: 6, Alternatives: []Alternative{{ObjectID: 4, ModelResolution: ResolutionLow}}}}
+	mock := NewMockObject(true, true)
+	model := &Model{Path: "/3D/3dmodel.model", Resources: []Resource{full, low, sameFile, base, other}}
+	type args struct {
+		o          Object
+		resolution SliceResolution
+	}
+	tests := []struct {
+		name   string
+		args   args
+		want   Object
+		wantOk bool
+	}{
+		{"low", args{full, ResolutionLow}, low, true},
+		{"full", args{full, ResolutionFull}, sameFile, true},
+		{"none", args{low, ResolutionLow}, low, false},
+		{"nonObject", args{other, ResolutionLow}, other, false},
+		{"unknown", args{mock, ResolutionLow}, mock, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, gotOk := model.FindAlternative(tt.args.o, tt.args.resolution)
+			if got != tt.want {
+				t.Errorf("Model.FindAlternative() got = %v, want %v", got, tt.want)
+			}
+			if gotOk != tt.wantOk {
+				t.Errorf("Model.FindAlternative() gotOk = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package go3mf
+
+import (
+	"errors"
+
+	"github.com/qmuntal/go3mf/geo"
+)
+
+// BeamLatticeMesh returns a watertight triangle mesh of the beam lattice of r,
+// whose beam sections are approximated with polygons of the given number of segments.
+// If union is true the mesh of r is added to the result. If r clips the beams,
+// the clipping mesh is resolved in the model file of r and the beams are clipped following ClipMode.
+// It fails if the clipping mesh cannot be found.
+func (m *Model) BeamLatticeMesh(r *MeshResource, segments int, union bool) (*geo.Mesh, error) {
+	if r.Mesh == nil {
+		return new(geo.Mesh), nil
+	}
+	opts := geo.BeamMeshOptions{Segments: segments, Union: union}
+	if r.BeamLatticeAttributes.ClipMode != ClipNone {
+		res, ok := m.FindResource(r.ModelPath, r.BeamLatticeAttributes.ClippingMeshID)
+		if !ok {
+			return nil, errors.New("go3mf: beam lattice clipping mesh not found")
+		}
+		clip, ok := res.(*MeshResource)
+		if !ok || clip.Mesh == nil {
+			return nil, errors.New("go3mf: beam lattice clipping object is not a mesh")
+		}
+		opts.Clip, opts.ClipInside = clip.Mesh, r.BeamLatticeAttributes.ClipMode == ClipInside
+	}
+	return r.Mesh.BeamMesh(opts), nil
+}
@@ -0,0 +1,64 @@
+package go3mf
+
+import (
+	"testing"
+
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestModel_BeamLatticeMesh(t *testing.T) {
+	beams := func() *geo.Mesh {
+		m := new(geo.Mesh)
+		m.Nodes = []geo.Point3D{{-5, 5, 5}, {15, 5, 5}}
+		m.Beams = []geo.Beam{{NodeIndices: [2]uint32{0, 1}, Radius: [2]float64{1, 1}, CapMode: [2]geo.CapMode{geo.CapModeButt, geo.CapModeButt}}}
+		return m
+	}
+	cube := new(geo.Mesh)
+	cube.Nodes = []geo.Point3D{{0, 0, 0}, {10, 0, 0}, {10, 10, 0}, {0, 10, 0}, {0, 0, 10}, {10, 0, 10}, {10, 10, 10}, {0, 10, 10}}
+	for _, f := range [][3]uint32{
+		{3, 2, 1}, {1, 0, 3}, {4, 5, 6}, {6, 7, 4}, {0, 1, 5}, {5, 4, 0},
+		{1, 2, 6}, {6, 5, 1}, {2, 3, 7}, {7, 6, 2}, {3, 0, 4}, {4, 7, 3},
+	} {
+		cube.AddFace(f[0], f[1], f[2])
+	}
+	clipper := &MeshResource{ObjectResource: ObjectResource{ID: 1, ModelPath: "/3D/3dmodel.model"}, Mesh: cube}
+	other := &MeshResource{ObjectResource: ObjectResource{ID: 1, ModelPath: "/3D/other.model"}, Mesh: cube}
+	base := &BaseMaterialsResource{ID: 2, ModelPath: "/3D/3dmodel.model"}
+	model := &Model{Path: "/3D/3dmodel.model", Resources: []Resource{clipper, base, other}}
+	clipped := func(mode ClipMode, id uint32) *MeshResource {
+		return &MeshResource{ObjectResource: ObjectResource{ID: 3, ModelPath: "/3D/3dmodel.model"}, Mesh: beams(),
+			BeamLatticeAttributes: BeamLatticeAttributes{ClipMode: mode, ClippingMeshID: id}}
+	}
+	// The beam with 8 segments has 18 nodes and 32 faces, and it is split in two when clipping outside.
+	tests := []struct {
+		name      string
+		r         *MeshResource
+		union     bool
+		wantNodes int
+		wantFaces int
+		wantErr   bool
+	}{
+		{"empty", new(MeshResource), false, 0, 0, false},
+		{"none", clipped(ClipNone, 5), false, 18, 32, false},
+		{"union", &MeshResource{Mesh: cube}, true, 8, 12, false},
+		{"inside", clipped(ClipInside, 1), false, 18, 32, false},
+		{"outside", clipped(ClipOutside, 1), false, 36, 64, false},
+		{"notFound", clipped(ClipInside, 5), false, 0, 0, true},
+		{"notMesh", clipped(ClipOutside, 2), false, 0, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := model.BeamLatticeMesh(tt.r, 8, tt.union)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Model.BeamLatticeMesh() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if len(got.Nodes) != tt.wantNodes || len(got.Faces) != tt.wantFaces {
+				t.Errorf("Model.BeamLatticeMesh() = %d nodes and %d faces, want %d and %d", len(got.Nodes), len(got.Faces), tt.wantNodes, tt.wantFaces)
+			}
+		})
+	}
+}
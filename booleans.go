@@ -0,0 +1,75 @@
+package go3mf
+
+import "github.com/qmuntal/go3mf/geo"
+
+// BooleanOperation defines the operation applied to the operands of a boolean shape.
+type BooleanOperation uint8
+
+const (
+	// BooleanUnion merges the operands into the base object.
+	BooleanUnion BooleanOperation = iota
+	// BooleanDifference removes the operands from the base object.
+	BooleanDifference
+	// BooleanIntersection keeps the volume that is common to the base object and to all the operands.
+	BooleanIntersection
+)
+
+func (b BooleanOperation) String() string {
+	return map[BooleanOperation]string{
+		BooleanUnion:        "union",
+		BooleanDifference:   "difference",
+		BooleanIntersection: "intersection",
+	}[b]
+}
+
+// A Boolean is an operand of a boolean shape, which is transformed before applying the operation.
+type Boolean struct {
+	Object    Object
+	Transform geo.Matrix
+}
+
+// HasTransform returns true if the transform is different than the identity.
+func (b *Boolean) HasTransform() bool {
+	return b.Transform != geo.Matrix{} && b.Transform != geo.Identity()
+}
+
+// A BooleanShapeResource is an in memory representation of the 3MF boolean shape object,
+// which is part of the Boolean Operations extension to 3MF.
+// The shape is the result of applying the operation to the base object
+// and to each of the operands, in order.
+type BooleanShapeResource struct {
+	ObjectResource
+	Object    Object
+	Transform geo.Matrix
+	Operation BooleanOperation
+	Operands  []*Boolean
+}
+
+// HasTransform returns true if the transform of the base object is different than the identity.
+func (c *BooleanShapeResource) HasTransform() bool {
+	return c.Transform != geo.Matrix{} && c.Transform != geo.Identity()
+}
+
+// IsValid checks if the boolean shape is valid.
+// The base object must be a mesh or another boolean shape and the operands must be meshes.
+func (c *BooleanShapeResource) IsValid() bool {
+	switch c.Object.(type) {
+	case *MeshResource, *BooleanShapeResource:
+	default:
+		return false
+	}
+	if !c.Object.IsValid() {
+		return false
+	}
+	for _, op := range c.Operands {
+		if mesh, ok := op.Object.(*MeshResource); !ok || !mesh.IsValid() {
+			return false
+		}
+	}
+	return true
+}
+
+// IsValidForSlices checks if the boolean shape is valid to be used with slices.
+func (c *BooleanShapeResource) IsValidForSlices(t geo.Matrix) bool {
+	return c.SliceStackID == 0 || t[2] == 0 && t[6] == 0 && t[8] == 0 && t[9] == 0 && t[10] == 1
+}
@@ -0,0 +1,88 @@
+package go3mf
+
+import (
+	"testing"
+
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestBooleanOperation_String(t *testing.T) {
+	tests := []struct {
+		name string
+		b    BooleanOperation
+	}{
+		{"union", BooleanUnion},
+		{"difference", BooleanDifference},
+		{"intersection", BooleanIntersection},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.String(); got != tt.name {
+				t.Errorf("BooleanOperation.String() = %v, want %v", got, tt.name)
+			}
+		})
+	}
+}
+
+func TestBoolean_HasTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		b    *Boolean
+		want bool
+	}{
+		{"identity", &Boolean{Transform: geo.Identity()}, false},
+		{"base", &Boolean{Transform: geo.Matrix{2, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.HasTransform(); got != tt.want {
+				t.Errorf("Boolean.HasTransform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBooleanShapeResource_IsValid(t *testing.T) {
+	mesh := &MeshResource{Mesh: new(geo.Mesh), ObjectResource: ObjectResource{ObjectType: ObjectTypeSurface}}
+	invalidMesh := new(MeshResource)
+	tests := []struct {
+		name string
+		c    *BooleanShapeResource
+		want bool
+	}{
+		{"empty", new(BooleanShapeResource), false},
+		{"components", &BooleanShapeResource{Object: &ComponentsResource{Components: []*Component{{Object: mesh}}}}, false},
+		{"invalidBase", &BooleanShapeResource{Object: invalidMesh}, false},
+		{"componentsOperand", &BooleanShapeResource{Object: mesh, Operands: []*Boolean{{Object: &ComponentsResource{Components: []*Component{{Object: mesh}}}}}}, false},
+		{"invalidOperand", &BooleanShapeResource{Object: mesh, Operands: []*Boolean{{Object: mesh}, {Object: invalidMesh}}}, false},
+		{"mesh", &BooleanShapeResource{Object: mesh, Operands: []*Boolean{{Object: mesh}}}, true},
+		{"nested", &BooleanShapeResource{Object: &BooleanShapeResource{Object: mesh}, Operands: []*Boolean{{Object: mesh}}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.IsValid(); got != tt.want {
+				t.Errorf("BooleanShapeResource.IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBooleanShapeResource_IsValidForSlices(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *BooleanShapeResource
+		t    geo.Matrix
+		want bool
+	}{
+		{"empty", new(BooleanShapeResource), geo.Matrix{}, true},
+		{"valid", &BooleanShapeResource{ObjectResource: ObjectResource{SliceStackID: 1}}, geo.Identity(), true},
+		{"invalid", &BooleanShapeResource{ObjectResource: ObjectResource{SliceStackID: 1}}, geo.Matrix{0, 0, 1, 0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.IsValidForSlices(tt.t); got != tt.want {
+				t.Errorf("BooleanShapeResource.IsValidForSlices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
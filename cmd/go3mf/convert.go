@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/io3mf"
+	"github.com/qmuntal/go3mf/io3mf/stl"
+)
+
+// errUsage is returned when the command line is not valid, once the usage has been printed.
+var errUsage = errors.New("go3mf: invalid usage")
+
+type convertOptions struct {
+	units     go3mf.Units
+	name      string
+	weld      float64
+	thumbnail string
+	ascii     bool
+}
+
+func convert(args []string, stderr io.Writer) error {
+	fs := flag.NewFlagSet("convert", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprint(stderr, "usage: go3mf convert [flags] in.stl out.3mf\n       go3mf convert [flags] in.3mf out.stl\n\nflags:\n")
+		fs.PrintDefaults()
+	}
+	units := fs.String("units", go3mf.UnitMillimeter.String(), "units of the 3MF model: micron, millimeter, centimeter, inch, foot or meter")
+	var opts convertOptions
+	fs.StringVar(&opts.name, "name", "", "name of the 3MF objects whose STL solid has no name, defaults to the input file name")
+	fs.Float64Var(&opts.weld, "weld", 0, "merge the STL vertices closer than this tolerance, 0 only merges the coincident ones")
+	fs.StringVar(&opts.thumbnail, "thumbnail", "", "PNG image used as the 3MF package thumbnail")
+	fs.BoolVar(&opts.ascii, "ascii", false, "write an ASCII STL instead of a binary one")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != 2 {
+		fs.Usage()
+		return errUsage
+	}
+	var ok bool
+	if opts.units, ok = parseUnits(*units); !ok {
+		return fmt.Errorf("unknown units %q", *units)
+	}
+	if opts.weld < 0 {
+		return fmt.Errorf("negative weld tolerance %v", opts.weld)
+	}
+	in, out := fs.Arg(0), fs.Arg(1)
+	if opts.name == "" {
+		opts.name = strings.TrimSuffix(filepath.Base(in), filepath.Ext(in))
+	}
+	inExt, outExt := strings.ToLower(filepath.Ext(in)), strings.ToLower(filepath.Ext(out))
+	switch {
+	case inExt == ".stl" && outExt == ".3mf":
+		return stlTo3MF(in, out, opts)
+	case inExt == ".3mf" && outExt == ".stl":
+		return threeMFToSTL(in, out, opts)
+	default:
+		return fmt.Errorf("cannot convert from %q to %q, only STL to 3MF and 3MF to STL are supported", inExt, outExt)
+	}
+}
+
+func parseUnits(s string) (go3mf.Units, bool) {
+	for _, u := range []go3mf.Units{go3mf.UnitMillimeter, go3mf.UnitMicrometer, go3mf.UnitCentimeter, go3mf.UnitInch, go3mf.UnitFoot, go3mf.UnitMeter} {
+		if u.String() == s {
+			return u, true
+		}
+	}
+	return 0, false
+}
+
+// stlTo3MF decodes the STL solids and writes them as the build items of a new 3MF package.
+func stlTo3MF(in, out string, opts convertOptions) error {
+	f, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	model := &go3mf.Model{Path: "/3D/3dmodel.model", Units: opts.units}
+	dec := stl.NewDecoder(f)
+	dec.WeldTolerance = float32(opts.weld)
+	if err = dec.Decode(model); err != nil {
+		return fmt.Errorf("decoding %s: %v", in, err)
+	}
+	for _, r := range model.Resources {
+		mesh := r.(*go3mf.MeshResource)
+		if mesh.Name == "" {
+			mesh.Name = opts.name
+		}
+		model.BuildItems = append(model.BuildItems, &go3mf.BuildItem{Object: mesh})
+	}
+	if opts.thumbnail != "" {
+		t, err := os.Open(opts.thumbnail)
+		if err != nil {
+			return err
+		}
+		defer t.Close()
+		model.SetThumbnail(t)
+	}
+	w, err := io3mf.CreateWriter(out)
+	if err != nil {
+		return err
+	}
+	if err = w.Encode(model); err != nil {
+		w.Close()
+		return fmt.Errorf("encoding %s: %v", out, err)
+	}
+	return w.Close()
+}
+
+// threeMFToSTL flattens all the build items of the 3MF model in a single STL mesh.
+func threeMFToSTL(in, out string, opts convertOptions) error {
+	r, err := io3mf.OpenReader(in)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	model := new(go3mf.Model)
+	if err = r.Decode(model); err != nil {
+		return fmt.Errorf("decoding %s: %v", in, err)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	encodingType := stl.Binary
+	if opts.ascii {
+		encodingType = stl.ASCII
+	}
+	if err = stl.NewEncoderType(f, encodingType).EncodeModel(model); err != nil {
+		f.Close()
+		return fmt.Errorf("encoding %s: %v", out, err)
+	}
+	return f.Close()
+}
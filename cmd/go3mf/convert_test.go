@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+	"github.com/qmuntal/go3mf/io3mf"
+	"github.com/qmuntal/go3mf/io3mf/stl"
+)
+
+func cube() *geo.Mesh {
+	m := new(geo.Mesh)
+	m.Nodes = []geo.Point3D{{0, 0, 0}, {10, 0, 0}, {10, 10, 0}, {0, 10, 0}, {0, 0, 10}, {10, 0, 10}, {10, 10, 10}, {0, 10, 10}}
+	for _, f := range [][3]uint32{{3, 2, 1}, {1, 0, 3}, {4, 5, 6}, {6, 7, 4}, {0, 1, 5}, {5, 4, 0}, {1, 2, 6}, {6, 5, 1}, {2, 3, 7}, {7, 6, 2}, {3, 0, 4}, {4, 7, 3}} {
+		m.AddFace(f[0], f[1], f[2])
+	}
+	return m
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"empty", nil, 2},
+		{"unknown", []string{"other"}, 2},
+		{"noFiles", []string{"convert"}, 2},
+		{"badFlag", []string{"convert", "-other", "a.stl", "b.3mf"}, 2},
+		{"badUnits", []string{"convert", "-units", "mile", "a.stl", "b.3mf"}, 1},
+		{"badWeld", []string{"convert", "-weld", "-1", "a.stl", "b.3mf"}, 1},
+		{"badFormat", []string{"convert", "a.obj", "b.3mf"}, 1},
+		{"noInput", []string{"convert", "missing.stl", "b.3mf"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(tt.args, new(bytes.Buffer)); got != tt.want {
+				t.Errorf("run() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvert_roundTrip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go3mf")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	inSTL, out3MF, outSTL, thumbnail := filepath.Join(dir, "cube.stl"), filepath.Join(dir, "cube.3mf"), filepath.Join(dir, "out.stl"), filepath.Join(dir, "thumbnail.png")
+	buff := new(bytes.Buffer)
+	if err := stl.NewEncoder(buff).Encode(cube()); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(inSTL, buff.Bytes(), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(thumbnail, []byte{0x89, 'P', 'N', 'G'}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	stderr := new(bytes.Buffer)
+	if got := run([]string{"convert", "-units", "inch", "-weld", "0.01", "-thumbnail", thumbnail, inSTL, out3MF}, stderr); got != 0 {
+		t.Fatalf("run() = %v, stderr = %s", got, stderr)
+	}
+
+	r, err := io3mf.OpenReader(out3MF)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	model := new(go3mf.Model)
+	if err := r.Decode(model); err != nil {
+		t.Fatalf("Decode() unexpected error = %v", err)
+	}
+	if model.Units != go3mf.UnitInch {
+		t.Errorf("convert() units = %v, want %v", model.Units, go3mf.UnitInch)
+	}
+	if model.Thumbnail == nil {
+		t.Error("convert() expected thumbnail")
+	}
+	if len(model.BuildItems) != 1 {
+		t.Fatalf("convert() build items = %d, want 1", len(model.BuildItems))
+	}
+	mesh, ok := model.BuildItems[0].Object.(*go3mf.MeshResource)
+	if !ok {
+		t.Fatalf("convert() build item object = %T, want *go3mf.MeshResource", model.BuildItems[0].Object)
+	}
+	if mesh.Name != "cube" {
+		t.Errorf("convert() name = %s, want cube", mesh.Name)
+	}
+	if len(mesh.Mesh.Nodes) != 8 || len(mesh.Mesh.Faces) != 12 {
+		t.Errorf("convert() nodes = %d, faces = %d, want 8 and 12", len(mesh.Mesh.Nodes), len(mesh.Mesh.Faces))
+	}
+
+	if got := run([]string{"convert", out3MF, outSTL}, stderr); got != 0 {
+		t.Fatalf("run() = %v, stderr = %s", got, stderr)
+	}
+	f, err := os.Open(outSTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got := new(go3mf.Model)
+	if err := stl.NewDecoder(f).Decode(got); err != nil {
+		t.Fatalf("stl.Decode() unexpected error = %v", err)
+	}
+	gotMesh := got.Resources[0].(*go3mf.MeshResource).Mesh
+	if len(gotMesh.Nodes) != 8 || len(gotMesh.Faces) != 12 {
+		t.Errorf("convert() nodes = %d, faces = %d, want 8 and 12", len(gotMesh.Nodes), len(gotMesh.Faces))
+	}
+}
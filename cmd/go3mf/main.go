@@ -0,0 +1,51 @@
+// Command go3mf converts models between the STL and 3MF formats.
+//
+// Usage:
+//
+//	go3mf convert [flags] in.stl out.3mf
+//	go3mf convert [flags] in.3mf out.stl
+//
+// The direction of the conversion is taken from the file extensions.
+// Run go3mf convert -h to list the available flags.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+const usage = `usage: go3mf <command> [arguments]
+
+The commands are:
+
+	convert    convert a model between the STL and 3MF formats
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stderr))
+}
+
+// run executes the command line args and returns the process exit code.
+func run(args []string, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+	var err error
+	switch args[0] {
+	case "convert":
+		err = convert(args[1:], stderr)
+	default:
+		fmt.Fprintf(stderr, "go3mf: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+	if err == errUsage {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "go3mf: %v\n", err)
+		return 1
+	}
+	return 0
+}
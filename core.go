@@ -1,6 +1,7 @@
 package go3mf
 
 import (
+	"encoding/xml"
 	"fmt"
 	"image/color"
 	"io"
@@ -99,6 +100,17 @@ type Object interface {
 	Type() ObjectType
 }
 
+// ExtensionData holds the data decoded by third party extensions, indexed by their namespace.
+type ExtensionData map[string]interface{}
+
+// UnknownTokens holds the XML attributes and child elements of a model node
+// that belong to unsupported namespaces, so they can be written back unchanged.
+// Names are qualified by the namespace URI instead of by the prefix.
+type UnknownTokens struct {
+	Attr   []xml.Attr
+	Tokens []xml.Token
+}
+
 // Metadata item is an in memory representation of the 3MF metadata,
 // and can be attached to any 3MF model node.
 type Metadata struct {
@@ -140,6 +152,8 @@ type Model struct {
 	BuildItems            []*BuildItem
 	Attachments           []*Attachment
 	ProductionAttachments []*ProductionAttachment
+	KeyStore              *KeyStore
+	Unknown               UnknownTokens
 }
 
 // UnusedID returns the lowest unused ID.
@@ -197,14 +211,15 @@ type BaseMaterial struct {
 
 // ColorString returns the color as a hex string with the format #rrggbbaa.
 func (m *BaseMaterial) ColorString() string {
-	return fmt.Sprintf("#%x%x%x%x", m.Color.R, m.Color.G, m.Color.B, m.Color.A)
+	return fmt.Sprintf("#%02x%02x%02x%02x", m.Color.R, m.Color.G, m.Color.B, m.Color.A)
 }
 
 // BaseMaterialsResource defines a slice of BaseMaterial.
 type BaseMaterialsResource struct {
-	ID        uint32
-	ModelPath string
-	Materials []BaseMaterial
+	ID                  uint32
+	ModelPath           string
+	DisplayPropertiesID uint32
+	Materials           []BaseMaterial
 }
 
 // Identify returns the unique ID of the resource.
@@ -219,6 +234,7 @@ type BuildItem struct {
 	PartNumber string
 	UUID       string
 	Metadata   []Metadata
+	Unknown    UnknownTokens
 }
 
 // HasTransform returns true if the transform is different than the identity.
@@ -240,6 +256,9 @@ type ObjectResource struct {
 	DefaultPropertyIndex uint32
 	ObjectType           ObjectType
 	Metadata             []Metadata
+	Alternatives         []Alternative
+	Extensions           ExtensionData
+	Unknown              UnknownTokens
 }
 
 // Identify returns the unique ID of the resource.
@@ -289,6 +308,7 @@ type MeshResource struct {
 	ObjectResource
 	Mesh                  *geo.Mesh
 	BeamLatticeAttributes BeamLatticeAttributes
+	MeshUnknown           UnknownTokens
 }
 
 // IsValid checks if the mesh resource are valid.
@@ -103,6 +103,7 @@ func TestBaseMaterial_ColotString(t *testing.T) {
 		want string
 	}{
 		{"base", &BaseMaterial{Color: color.RGBA{200, 250, 60, 80}}, "#c8fa3c50"},
+		{"padding", &BaseMaterial{Color: color.RGBA{0, 0, 255, 8}}, "#0000ff08"},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,113 @@
+package go3mf
+
+import "github.com/qmuntal/go3mf/geo"
+
+// Channel defines the texture channel that holds the displacement values.
+type Channel uint8
+
+const (
+	// ChannelG uses the green channel.
+	ChannelG Channel = iota
+	// ChannelR uses the red channel.
+	ChannelR
+	// ChannelB uses the blue channel.
+	ChannelB
+	// ChannelA uses the alpha channel.
+	ChannelA
+)
+
+func (c Channel) String() string {
+	return map[Channel]string{
+		ChannelG: "G",
+		ChannelR: "R",
+		ChannelB: "B",
+		ChannelA: "A",
+	}[c]
+}
+
+// Displacement2DResource defines a displacement texture and is part of the Displacement extension to 3MF.
+type Displacement2DResource struct {
+	ID         uint32
+	ModelPath  string
+	Path       string
+	Channel    Channel
+	TileStyleU TileStyle
+	TileStyleV TileStyle
+	Filter     TextureFilter
+}
+
+// Identify returns the unique ID of the resource.
+func (d *Displacement2DResource) Identify() (string, uint32) {
+	return d.ModelPath, d.ID
+}
+
+// NormVectorGroupResource acts as a container for the displacement normal vectors.
+type NormVectorGroupResource struct {
+	ID        uint32
+	ModelPath string
+	Vectors   []geo.Point3D
+}
+
+// Identify returns the unique ID of the resource.
+func (n *NormVectorGroupResource) Identify() (string, uint32) {
+	return n.ModelPath, n.ID
+}
+
+// Disp2DCoord maps a vertex of a triangle to a position in the displacement texture.
+// N is the index of the normal vector and F the factor applied to it.
+type Disp2DCoord struct {
+	U, V float32
+	N    uint32
+	F    float32
+}
+
+// Disp2DGroupResource acts as a container for displacement coordinates.
+type Disp2DGroupResource struct {
+	ID                uint32
+	ModelPath         string
+	DisplacementID    uint32
+	NormVectorGroupID uint32
+	Height            float32
+	Offset            float32
+	Coords            []Disp2DCoord
+}
+
+// Identify returns the unique ID of the resource.
+func (d *Disp2DGroupResource) Identify() (string, uint32) {
+	return d.ModelPath, d.ID
+}
+
+// DisplacementTriangle defines the displacement of a triangle,
+// as an index into the coordinates of a Disp2DGroupResource for each node.
+type DisplacementTriangle struct {
+	DisplacementID uint32
+	Indices        [3]uint32
+}
+
+// A DisplacementMeshResource is an in memory representation of the 3MF displacement mesh object.
+// Displacements[i] holds the displacement of Mesh.Faces[i].
+type DisplacementMeshResource struct {
+	ObjectResource
+	Mesh                  *geo.Mesh
+	DefaultDisplacementID uint32
+	Displacements         []DisplacementTriangle
+}
+
+// IsValid checks if the displacement mesh resource is valid.
+func (c *DisplacementMeshResource) IsValid() bool {
+	if c.Mesh == nil || len(c.Mesh.Faces) != len(c.Displacements) {
+		return false
+	}
+	switch c.ObjectType {
+	case ObjectTypeModel, ObjectTypeSolidSupport:
+		return c.Mesh.IsManifoldAndOriented()
+	case ObjectTypeSupport, ObjectTypeSurface:
+		return true
+	}
+	return false
+}
+
+// IsValidForSlices checks if the displacement mesh resource is valid for slices.
+func (c *DisplacementMeshResource) IsValidForSlices(t geo.Matrix) bool {
+	return c.SliceStackID == 0 || t[2] == 0 && t[6] == 0 && t[8] == 0 && t[9] == 0 && t[10] == 1
+}
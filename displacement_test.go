@@ -0,0 +1,94 @@
+package go3mf
+
+import (
+	"testing"
+
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestChannel_String(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Channel
+	}{
+		{"G", ChannelG},
+		{"R", ChannelR},
+		{"B", ChannelB},
+		{"A", ChannelA},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.String(); got != tt.name {
+				t.Errorf("Channel.String() = %v, want %v", got, tt.name)
+			}
+		})
+	}
+}
+
+func TestDisplacementResources_Identify(t *testing.T) {
+	tests := []struct {
+		name string
+		r    Resource
+	}{
+		{"displacement2d", &Displacement2DResource{ID: 1, ModelPath: "3d/3dmodel.model"}},
+		{"normvectorgroup", &NormVectorGroupResource{ID: 1, ModelPath: "3d/3dmodel.model"}},
+		{"disp2dgroup", &Disp2DGroupResource{ID: 1, ModelPath: "3d/3dmodel.model"}},
+		{"displacementmesh", &DisplacementMeshResource{ObjectResource: ObjectResource{ID: 1, ModelPath: "3d/3dmodel.model"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.r.Identify()
+			if got != "3d/3dmodel.model" {
+				t.Errorf("Identify() got = %v, want %v", got, "3d/3dmodel.model")
+			}
+			if got1 != 1 {
+				t.Errorf("Identify() got1 = %v, want %v", got1, 1)
+			}
+		})
+	}
+}
+
+func TestDisplacementMeshResource_IsValid(t *testing.T) {
+	mesh := new(geo.Mesh)
+	mesh.Faces = append(mesh.Faces, geo.Face{})
+	tests := []struct {
+		name string
+		c    *DisplacementMeshResource
+		want bool
+	}{
+		{"empty", new(DisplacementMeshResource), false},
+		{"mismatch", &DisplacementMeshResource{Mesh: mesh, ObjectResource: ObjectResource{ObjectType: ObjectTypeSurface}}, false},
+		{"other", &DisplacementMeshResource{Mesh: new(geo.Mesh), ObjectResource: ObjectResource{ObjectType: ObjectTypeOther}}, false},
+		{"surface", &DisplacementMeshResource{Mesh: new(geo.Mesh), ObjectResource: ObjectResource{ObjectType: ObjectTypeSurface}}, true},
+		{"support", &DisplacementMeshResource{Mesh: new(geo.Mesh), ObjectResource: ObjectResource{ObjectType: ObjectTypeSupport}}, true},
+		{"solidsupport", &DisplacementMeshResource{Mesh: new(geo.Mesh), ObjectResource: ObjectResource{ObjectType: ObjectTypeSolidSupport}}, false},
+		{"model", &DisplacementMeshResource{Mesh: new(geo.Mesh), ObjectResource: ObjectResource{ObjectType: ObjectTypeModel}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.IsValid(); got != tt.want {
+				t.Errorf("DisplacementMeshResource.IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisplacementMeshResource_IsValidForSlices(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *DisplacementMeshResource
+		t    geo.Matrix
+		want bool
+	}{
+		{"empty", new(DisplacementMeshResource), geo.Matrix{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, true},
+		{"valid", &DisplacementMeshResource{ObjectResource: ObjectResource{SliceStackID: 1}}, geo.Matrix{1, 1, 0, 1, 1, 1, 0, 1, 0, 0, 1, 1, 1, 1, 1, 1}, true},
+		{"invalid", &DisplacementMeshResource{ObjectResource: ObjectResource{SliceStackID: 1}}, geo.Matrix{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.IsValidForSlices(tt.t); got != tt.want {
+				t.Errorf("DisplacementMeshResource.IsValidForSlices() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package go3mf
+
+import (
+	"errors"
+
+	"github.com/qmuntal/go3mf/geo"
+)
+
+// A WorldMesh is a copy of a mesh object of the build whose nodes are in world coordinates.
+type WorldMesh struct {
+	// Object is the mesh or displacement mesh object that has been copied.
+	Object Object
+	// Transform is the accumulated transform of the build item and of the components.
+	Transform geo.Matrix
+	// Mesh holds the transformed nodes and the faces, which keep their properties.
+	// The faces without property take the object default one and
+	// are reversed when Transform mirrors the mesh, so they keep facing outwards.
+	Mesh *geo.Mesh
+	// Properties maps the property ids of the faces to their resources,
+	// which are resolved in the model file of the object.
+	Properties map[uint32]Resource
+}
+
+// Flatten returns the world meshes of all the build items.
+func (m *Model) Flatten() ([]*WorldMesh, error) {
+	var meshes []*WorldMesh
+	for _, item := range m.BuildItems {
+		itemMeshes, err := m.WorldMeshes(item)
+		if err != nil {
+			return nil, err
+		}
+		meshes = append(meshes, itemMeshes...)
+	}
+	return meshes, nil
+}
+
+// WorldMeshes returns the world meshes of a build item, walking its nested components.
+// Boolean shapes are not evaluated, so they do not produce any mesh.
+// It fails if the components have a cyclic reference.
+func (m *Model) WorldMeshes(item *BuildItem) ([]*WorldMesh, error) {
+	f := flattener{model: m, visiting: make(map[Object]bool)}
+	t := geo.Identity()
+	if item.HasTransform() {
+		t = item.Transform
+	}
+	if err := f.appendObject(item.Object, t); err != nil {
+		return nil, err
+	}
+	return f.meshes, nil
+}
+
+type flattener struct {
+	model    *Model
+	meshes   []*WorldMesh
+	visiting map[Object]bool
+}
+
+func (f *flattener) appendObject(o Object, t geo.Matrix) error {
+	switch o := o.(type) {
+	case *MeshResource:
+		f.appendMesh(o, &o.ObjectResource, o.Mesh, t)
+	case *DisplacementMeshResource:
+		f.appendMesh(o, &o.ObjectResource, o.Mesh, t)
+	case *ComponentsResource:
+		if f.visiting[o] {
+			return errors.New("go3mf: components have a cyclic reference")
+		}
+		f.visiting[o] = true
+		defer delete(f.visiting, o)
+		for _, c := range o.Components {
+			ct := t
+			if c.HasTransform() {
+				ct = t.Mul(c.Transform)
+			}
+			if err := f.appendObject(c.Object, ct); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (f *flattener) appendMesh(o Object, r *ObjectResource, src *geo.Mesh, t geo.Matrix) {
+	if src == nil {
+		return
+	}
+	wm := &WorldMesh{Object: o, Transform: t, Mesh: new(geo.Mesh), Properties: make(map[uint32]Resource)}
+	wm.Mesh.Nodes = make([]geo.Point3D, len(src.Nodes))
+	for i, n := range src.Nodes {
+		wm.Mesh.Nodes[i] = transformPoint(t, n)
+	}
+	mirror := determinant(t) < 0
+	wm.Mesh.Faces = make([]geo.Face, len(src.Faces))
+	for i, face := range src.Faces {
+		if face.Resource == 0 {
+			face.Resource = r.DefaultPropertyID
+			face.ResourceIndices = [3]uint32{r.DefaultPropertyIndex, r.DefaultPropertyIndex, r.DefaultPropertyIndex}
+		}
+		if mirror {
+			face.NodeIndices[1], face.NodeIndices[2] = face.NodeIndices[2], face.NodeIndices[1]
+			face.ResourceIndices[1], face.ResourceIndices[2] = face.ResourceIndices[2], face.ResourceIndices[1]
+		}
+		wm.Mesh.Faces[i] = face
+		if _, ok := wm.Properties[face.Resource]; !ok && face.Resource != 0 {
+			if res, ok := f.model.FindResource(r.ModelPath, face.Resource); ok {
+				wm.Properties[face.Resource] = res
+			}
+		}
+	}
+	f.meshes = append(f.meshes, wm)
+}
+
+// transformPoint applies the transform t to p, which is treated as a row vector.
+func transformPoint(t geo.Matrix, p geo.Point3D) geo.Point3D {
+	return geo.Point3D{
+		p[0]*t[0] + p[1]*t[4] + p[2]*t[8] + t[12],
+		p[0]*t[1] + p[1]*t[5] + p[2]*t[9] + t[13],
+		p[0]*t[2] + p[1]*t[6] + p[2]*t[10] + t[14],
+	}
+}
+
+// determinant returns the determinant of the 3x3 linear part of t.
+func determinant(t geo.Matrix) float32 {
+	return t[0]*(t[5]*t[10]-t[6]*t[9]) - t[1]*(t[4]*t[10]-t[6]*t[8]) + t[2]*(t[4]*t[9]-t[5]*t[8])
+}
@@ -0,0 +1,96 @@
+package go3mf
+
+import (
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestModel_WorldMeshes(t *testing.T) {
+	triangle := func(resource uint32, indices [3]uint32) *geo.Mesh {
+		m := new(geo.Mesh)
+		m.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+		m.Faces = []geo.Face{{NodeIndices: [3]uint32{0, 1, 2}, Resource: resource, ResourceIndices: indices}}
+		return m
+	}
+	base := &BaseMaterialsResource{ID: 1, ModelPath: "/3D/3dmodel.model", Materials: []BaseMaterial{{Name: "red", Color: color.RGBA{255, 0, 0, 255}}}}
+	colors := &ColorGroupResource{ID: 1, ModelPath: "/3D/other.model", Colors: []color.RGBA{{0, 0, 255, 255}, {0, 255, 0, 255}, {255, 0, 0, 255}}}
+	mesh := &MeshResource{ObjectResource: ObjectResource{ID: 2, DefaultPropertyID: 1}, Mesh: triangle(0, [3]uint32{})}
+	other := &MeshResource{ObjectResource: ObjectResource{ID: 1, ModelPath: "/3D/other.model"}, Mesh: triangle(1, [3]uint32{0, 1, 2})}
+	displacement := &DisplacementMeshResource{ObjectResource: ObjectResource{ID: 4}, Mesh: triangle(9, [3]uint32{})}
+	components := &ComponentsResource{ObjectResource: ObjectResource{ID: 3}, Components: []*Component{
+		{Object: mesh, Transform: geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 5, 1}},
+		{Object: other, Transform: geo.Matrix{-1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}},
+		{Object: new(MeshResource)},
+	}}
+	cycle := &ComponentsResource{ObjectResource: ObjectResource{ID: 5}}
+	cycle.Components = []*Component{{Object: &ComponentsResource{Components: []*Component{{Object: cycle}}}}}
+	model := &Model{Path: "/3D/3dmodel.model", Resources: []Resource{base, mesh, components, displacement, cycle, colors, other}}
+	tests := []struct {
+		name    string
+		item    *BuildItem
+		want    []*WorldMesh
+		wantErr bool
+	}{
+		{"displacement", &BuildItem{Object: displacement}, []*WorldMesh{
+			{Object: displacement, Transform: geo.Identity(), Mesh: triangle(9, [3]uint32{}), Properties: map[uint32]Resource{}},
+		}, false},
+		{"components", &BuildItem{Object: components, Transform: geo.Matrix{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 1}}, []*WorldMesh{
+			{Object: mesh, Transform: geo.Matrix{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 0, 0, 10, 1}, Mesh: &geo.Mesh{}, Properties: map[uint32]Resource{1: base}},
+			{Object: other, Transform: geo.Matrix{-2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 1}, Mesh: &geo.Mesh{}, Properties: map[uint32]Resource{1: colors}},
+		}, false},
+		{"cycle", &BuildItem{Object: cycle}, nil, true},
+	}
+	tests[1].want[0].Mesh.Nodes = []geo.Point3D{{0, 0, 10}, {2, 0, 10}, {0, 2, 10}}
+	tests[1].want[0].Mesh.Faces = []geo.Face{{NodeIndices: [3]uint32{0, 1, 2}, Resource: 1}}
+	tests[1].want[1].Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {-2, 0, 0}, {0, 2, 0}}
+	tests[1].want[1].Mesh.Faces = []geo.Face{{NodeIndices: [3]uint32{0, 2, 1}, Resource: 1, ResourceIndices: [3]uint32{0, 2, 1}}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := model.WorldMeshes(tt.item)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Model.WorldMeshes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Model.WorldMeshes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := mesh.Mesh.Nodes[1]; got != (geo.Point3D{1, 0, 0}) {
+		t.Errorf("Model.WorldMeshes() modified the source mesh = %v", got)
+	}
+}
+
+func TestModel_Flatten(t *testing.T) {
+	mesh := &MeshResource{ObjectResource: ObjectResource{ID: 1}, Mesh: new(geo.Mesh)}
+	cycle := &ComponentsResource{ObjectResource: ObjectResource{ID: 2}}
+	cycle.Components = []*Component{{Object: cycle}}
+	tests := []struct {
+		name    string
+		m       *Model
+		want    []*WorldMesh
+		wantErr bool
+	}{
+		{"empty", new(Model), nil, false},
+		{"items", &Model{BuildItems: []*BuildItem{{Object: mesh}, {Object: mesh, Transform: geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 1, 2, 3, 1}}}}, []*WorldMesh{
+			{Object: mesh, Transform: geo.Identity(), Mesh: &geo.Mesh{Nodes: []geo.Point3D{}, Faces: []geo.Face{}}, Properties: map[uint32]Resource{}},
+			{Object: mesh, Transform: geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 1, 2, 3, 1}, Mesh: &geo.Mesh{Nodes: []geo.Point3D{}, Faces: []geo.Face{}}, Properties: map[uint32]Resource{}},
+		}, false},
+		{"cycle", &Model{BuildItems: []*BuildItem{{Object: mesh}, {Object: cycle}}}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.m.Flatten()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Model.Flatten() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Model.Flatten() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
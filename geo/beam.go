@@ -27,6 +27,32 @@ func (b CapMode) String() string {
 	}[b]
 }
 
+// A BallMode is an enumerable for the different modes of placing balls at the beam nodes.
+type BallMode int
+
+const (
+	// BallModeNone when there are no balls.
+	BallModeNone BallMode = iota
+	// BallModeMixed when there are balls only at the nodes listed in the balls.
+	BallModeMixed
+	// BallModeAll when there are balls at all the nodes of the beams.
+	BallModeAll
+)
+
+func (b BallMode) String() string {
+	return map[BallMode]string{
+		BallModeNone:  "none",
+		BallModeMixed: "mixed",
+		BallModeAll:   "all",
+	}[b]
+}
+
+// Ball defines a ball placed at a node of the beams.
+type Ball struct {
+	NodeIndex uint32  // Index of the node where the ball is placed.
+	Radius    float64 // Radius of the ball.
+}
+
 // Beam defines a single beam.
 type Beam struct {
 	NodeIndices [2]uint32  // Indices of the two nodes that defines the beam.
@@ -40,6 +66,12 @@ type beamLattice struct {
 	BeamSets                 []BeamSet
 	MinLength, DefaultRadius float64
 	CapMode                  CapMode
+	// Balls holds the balls with their own radius. When BallMode is BallModeAll
+	// the rest of the nodes of the beams also have a ball of DefaultBallRadius,
+	// and when it is BallModeNone there must be no balls.
+	Balls             []Ball
+	BallMode          BallMode
+	DefaultBallRadius float64
 }
 
 func (b *beamLattice) checkSanity(nodeCount uint32) bool {
@@ -52,5 +84,18 @@ func (b *beamLattice) checkSanity(nodeCount uint32) bool {
 			return false
 		}
 	}
+	if len(b.Balls) == 0 {
+		return true
+	}
+	beamNodes := make(map[uint32]struct{}, 2*len(b.Beams))
+	for _, beam := range b.Beams {
+		beamNodes[beam.NodeIndices[0]] = struct{}{}
+		beamNodes[beam.NodeIndices[1]] = struct{}{}
+	}
+	for _, ball := range b.Balls {
+		if _, ok := beamNodes[ball.NodeIndex]; !ok {
+			return false
+		}
+	}
 	return true
 }
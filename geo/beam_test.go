@@ -18,6 +18,8 @@ func Test_beamLattice_checkSanity(t *testing.T) {
 		{"high1", &beamLattice{Beams: []Beam{{NodeIndices: [2]uint32{2, 1}}}}, args{2}, false},
 		{"high2", &beamLattice{Beams: []Beam{{NodeIndices: [2]uint32{1, 2}}}}, args{2}, false},
 		{"good", &beamLattice{Beams: []Beam{{NodeIndices: [2]uint32{1, 2}}}}, args{3}, true},
+		{"ballNotInBeam", &beamLattice{Beams: []Beam{{NodeIndices: [2]uint32{1, 2}}}, Balls: []Ball{{NodeIndex: 0}}}, args{3}, false},
+		{"goodBall", &beamLattice{Beams: []Beam{{NodeIndices: [2]uint32{1, 2}}}, Balls: []Ball{{NodeIndex: 2}}}, args{3}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -45,3 +47,21 @@ func TestCapMode_String(t *testing.T) {
 		})
 	}
 }
+
+func TestBallMode_String(t *testing.T) {
+	tests := []struct {
+		name string
+		b    BallMode
+	}{
+		{"none", BallModeNone},
+		{"mixed", BallModeMixed},
+		{"all", BallModeAll},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.b.String(); got != tt.name {
+				t.Errorf("BallMode.String() = %v, want %v", got, tt.name)
+			}
+		})
+	}
+}
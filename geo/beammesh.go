@@ -0,0 +1,345 @@
+package geo
+
+import (
+	"math"
+	"sort"
+)
+
+// BeamMeshOptions defines how Mesh.BeamMesh converts the beams to triangles.
+type BeamMeshOptions struct {
+	// Segments is the number of sides of the polygons that approximate the beam sections,
+	// 16 is used when it is lower than 3.
+	Segments int
+	// Union adds the nodes and the faces of the mesh to the result.
+	Union bool
+	// Clip, if not nil, is a closed mesh that clips the beams and the balls.
+	Clip *Mesh
+	// ClipInside keeps the beams inside Clip instead of the ones outside it.
+	ClipInside bool
+}
+
+// BeamMesh returns a triangle mesh of the beams and the balls.
+//
+// Each beam is a closed shell, a tapered cylinder from the radius of its first node
+// to the radius of the second one whose ends are capped following its CapMode,
+// and each ball is a closed sphere. The shells overlap at the shared nodes,
+// which is a union under the positive fill rule of 3MF, so the result is watertight
+// without any boolean operation between them.
+//
+// When clipping, the beams are cut perpendicular to their axis where the axis crosses Clip,
+// with a butt cap at the cut, and the balls are kept if their center is kept.
+func (m *Mesh) BeamMesh(opts BeamMeshOptions) *Mesh {
+	b := newBeamMesher(opts.Segments)
+	if opts.Union {
+		b.mesh.Nodes = append(b.mesh.Nodes, m.Nodes...)
+		b.mesh.Faces = append(b.mesh.Faces, m.Faces...)
+	}
+	var clip *clipper
+	if opts.Clip != nil {
+		clip = &clipper{mesh: opts.Clip, inside: opts.ClipInside}
+	}
+	nodeCount := uint32(len(m.Nodes))
+	for _, beam := range m.Beams {
+		i0, i1 := beam.NodeIndices[0], beam.NodeIndices[1]
+		if i0 >= nodeCount || i1 >= nodeCount {
+			continue
+		}
+		p0, p1 := newVec3(m.Nodes[i0]), newVec3(m.Nodes[i1])
+		if clip == nil {
+			b.addBeam(p0, p1, beam.Radius, beam.CapMode)
+			continue
+		}
+		axis := p1.sub(p0)
+		for _, span := range clip.spans(p0, axis) {
+			radius, caps := beam.Radius, beam.CapMode
+			for i, t := range span {
+				if t > 0 && t < 1 {
+					radius[i] = beam.Radius[0] + (beam.Radius[1]-beam.Radius[0])*t
+					caps[i] = CapModeButt
+				}
+			}
+			b.addBeam(p0.add(axis.scale(span[0])), p0.add(axis.scale(span[1])), radius, caps)
+		}
+	}
+	for _, ball := range m.ballsByNode() {
+		if ball.NodeIndex >= nodeCount {
+			continue
+		}
+		c := newVec3(m.Nodes[ball.NodeIndex])
+		if clip == nil || clip.keeps(c) {
+			b.addSphere(c, ball.Radius)
+		}
+	}
+	return b.mesh
+}
+
+// ballsByNode returns the balls placed following the ball mode,
+// which with BallModeAll are the listed balls and a default one at the rest of the beam nodes.
+func (b *beamLattice) ballsByNode() []Ball {
+	switch b.BallMode {
+	case BallModeMixed:
+		return b.Balls
+	case BallModeAll:
+		listed := make(map[uint32]struct{}, len(b.Balls))
+		for _, ball := range b.Balls {
+			listed[ball.NodeIndex] = struct{}{}
+		}
+		balls := append([]Ball(nil), b.Balls...)
+		for _, beam := range b.Beams {
+			for _, i := range beam.NodeIndices {
+				if _, ok := listed[i]; !ok {
+					listed[i] = struct{}{}
+					balls = append(balls, Ball{NodeIndex: i, Radius: b.DefaultBallRadius})
+				}
+			}
+		}
+		return balls
+	}
+	return nil
+}
+
+type beamMesher struct {
+	mesh     *Mesh
+	cos, sin []float64
+	// latitudes is the number of rings of a hemisphere, including the base one.
+	latitudes int
+}
+
+func newBeamMesher(segments int) *beamMesher {
+	if segments < 3 {
+		segments = 16
+	}
+	b := &beamMesher{
+		mesh:      new(Mesh),
+		cos:       make([]float64, segments),
+		sin:       make([]float64, segments),
+		latitudes: segments / 4,
+	}
+	if b.latitudes < 1 {
+		b.latitudes = 1
+	}
+	for i := range b.cos {
+		b.sin[i], b.cos[i] = math.Sincos(2 * math.Pi * float64(i) / float64(segments))
+	}
+	return b
+}
+
+func (b *beamMesher) addBeam(p0, p1 vec3, radius [2]float64, caps [2]CapMode) {
+	axis := p1.sub(p0)
+	length := axis.len()
+	if length == 0 || radius[0] <= 0 && radius[1] <= 0 {
+		return
+	}
+	d := axis.scale(1 / length)
+	u, v := perpendicular(d)
+	ring0, ring1 := b.ring(p0, u, v, radius[0]), b.ring(p1, u, v, radius[1])
+	b.connect(ring0, ring1, false)
+	b.cap(ring0, p0, d.scale(-1), u, v, radius[0], caps[0], true)
+	b.cap(ring1, p1, d, u, v, radius[1], caps[1], false)
+}
+
+// cap closes the ring at the end c of a beam, where w points outwards.
+func (b *beamMesher) cap(ring []uint32, c, w, u, v vec3, r float64, mode CapMode, flip bool) {
+	switch mode {
+	case CapModeHemisphere:
+		b.hemisphere(ring, c, w, u, v, r, flip)
+	case CapModeSphere:
+		b.fan(ring, b.addNode(c), flip)
+		b.addSphere(c, r)
+	default:
+		b.fan(ring, b.addNode(c), flip)
+	}
+}
+
+func (b *beamMesher) addSphere(c vec3, r float64) {
+	if r <= 0 {
+		return
+	}
+	u, v, w := vec3{1, 0, 0}, vec3{0, 1, 0}, vec3{0, 0, 1}
+	equator := b.ring(c, u, v, r)
+	b.hemisphere(equator, c, w, u, v, r, false)
+	b.hemisphere(equator, c, w.scale(-1), u, v, r, true)
+}
+
+// hemisphere closes the ring, which is centered at c, with a hemisphere that points to w.
+func (b *beamMesher) hemisphere(ring []uint32, c, w, u, v vec3, r float64, flip bool) {
+	for k := 1; k < b.latitudes; k++ {
+		sin, cos := math.Sincos(math.Pi / 2 * float64(k) / float64(b.latitudes))
+		next := b.ring(c.add(w.scale(r*sin)), u, v, r*cos)
+		b.connect(ring, next, flip)
+		ring = next
+	}
+	b.fan(ring, b.addNode(c.add(w.scale(r))), flip)
+}
+
+// ring adds a polygon of radius r centered at c in the plane of u and v.
+func (b *beamMesher) ring(c, u, v vec3, r float64) []uint32 {
+	ring := make([]uint32, len(b.cos))
+	for i := range ring {
+		ring[i] = b.addNode(c.add(u.scale(r * b.cos[i])).add(v.scale(r * b.sin[i])))
+	}
+	return ring
+}
+
+// connect adds the side of the frustum between two rings.
+// The faces point outwards if u×v points from ring a to ring b, otherwise flip must be true.
+func (b *beamMesher) connect(a, c []uint32, flip bool) {
+	for i := range a {
+		j := (i + 1) % len(a)
+		if flip {
+			b.mesh.AddFace(a[i], c[j], a[j])
+			b.mesh.AddFace(a[i], c[i], c[j])
+		} else {
+			b.mesh.AddFace(a[i], a[j], c[j])
+			b.mesh.AddFace(a[i], c[j], c[i])
+		}
+	}
+}
+
+// fan closes a ring with the faces that join it with the apex,
+// which follow the same orientation rules as connect.
+func (b *beamMesher) fan(ring []uint32, apex uint32, flip bool) {
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		if flip {
+			b.mesh.AddFace(ring[j], ring[i], apex)
+		} else {
+			b.mesh.AddFace(ring[i], ring[j], apex)
+		}
+	}
+}
+
+func (b *beamMesher) addNode(p vec3) uint32 {
+	b.mesh.Nodes = append(b.mesh.Nodes, Point3D{float32(p[0]), float32(p[1]), float32(p[2])})
+	return uint32(len(b.mesh.Nodes) - 1)
+}
+
+// clipper classifies points and segments with respect to a closed mesh.
+type clipper struct {
+	mesh   *Mesh
+	inside bool
+}
+
+// rayDirection is the direction used to test if a point is inside the mesh,
+// (1, √2, √3) normalized, whose irrational ratios rarely hit an edge of the usual meshes.
+var rayDirection = vec3{0.4082482904638630, 0.5773502691896258, 0.7071067811865476}
+
+func (c *clipper) keeps(p vec3) bool {
+	return c.contains(p) == c.inside
+}
+
+// contains returns true if p is inside the mesh, counting the crossings of a ray.
+func (c *clipper) contains(p vec3) bool {
+	var inside bool
+	for _, t := range c.crossings(p, rayDirection) {
+		if t > 0 {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+// spans returns the intervals of the segment p+t*dir, with t from 0 to 1, that are kept.
+func (c *clipper) spans(p, dir vec3) [][2]float64 {
+	var spans [][2]float64
+	keep := c.keeps(p)
+	start := 0.0
+	for _, t := range c.crossings(p, dir) {
+		if t <= 0 || t >= 1 {
+			continue
+		}
+		if keep {
+			spans = append(spans, [2]float64{start, t})
+		}
+		keep, start = !keep, t
+	}
+	if keep {
+		spans = append(spans, [2]float64{start, 1})
+	}
+	return spans
+}
+
+// crossings returns the sorted parameters t where the line p+t*dir crosses the faces of the mesh.
+// The crossings through a shared edge or node are only returned once.
+func (c *clipper) crossings(p, dir vec3) []float64 {
+	var ts []float64
+	for i := range c.mesh.Faces {
+		n0, n1, n2 := c.mesh.FaceNodes(uint32(i))
+		if t, ok := intersect(p, dir, newVec3(*n0), newVec3(*n1), newVec3(*n2)); ok {
+			ts = append(ts, t)
+		}
+	}
+	sort.Float64s(ts)
+	unique := ts[:0]
+	for _, t := range ts {
+		if len(unique) == 0 || t-unique[len(unique)-1] > 1e-9 {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
+
+// intersect returns the parameter t where the line p+t*dir crosses the triangle,
+// using the Möller–Trumbore algorithm.
+func intersect(p, dir, v0, v1, v2 vec3) (float64, bool) {
+	const epsilon = 1e-12
+	e1, e2 := v1.sub(v0), v2.sub(v0)
+	h := dir.cross(e2)
+	det := e1.dot(h)
+	if math.Abs(det) < epsilon {
+		return 0, false
+	}
+	s := p.sub(v0)
+	a := s.dot(h) / det
+	if a < 0 || a > 1 {
+		return 0, false
+	}
+	q := s.cross(e1)
+	b := dir.dot(q) / det
+	if b < 0 || a+b > 1 {
+		return 0, false
+	}
+	return e2.dot(q) / det, true
+}
+
+// perpendicular returns two unit vectors u and v such that u, v and d form a right-handed basis.
+func perpendicular(d vec3) (vec3, vec3) {
+	a := vec3{1, 0, 0}
+	if math.Abs(d[0]) > 0.9 {
+		a = vec3{0, 1, 0}
+	}
+	u := a.cross(d)
+	u = u.scale(1 / u.len())
+	return u, d.cross(u)
+}
+
+// vec3 is a 3D vector with double precision, used to avoid accumulating errors.
+type vec3 [3]float64
+
+func newVec3(p Point3D) vec3 {
+	return vec3{float64(p[0]), float64(p[1]), float64(p[2])}
+}
+
+func (v1 vec3) add(v2 vec3) vec3 {
+	return vec3{v1[0] + v2[0], v1[1] + v2[1], v1[2] + v2[2]}
+}
+
+func (v1 vec3) sub(v2 vec3) vec3 {
+	return vec3{v1[0] - v2[0], v1[1] - v2[1], v1[2] - v2[2]}
+}
+
+func (v1 vec3) scale(f float64) vec3 {
+	return vec3{v1[0] * f, v1[1] * f, v1[2] * f}
+}
+
+func (v1 vec3) dot(v2 vec3) float64 {
+	return v1[0]*v2[0] + v1[1]*v2[1] + v1[2]*v2[2]
+}
+
+func (v1 vec3) cross(v2 vec3) vec3 {
+	return vec3{v1[1]*v2[2] - v1[2]*v2[1], v1[2]*v2[0] - v1[0]*v2[2], v1[0]*v2[1] - v1[1]*v2[0]}
+}
+
+func (v1 vec3) len() float64 {
+	return math.Sqrt(v1.dot(v1))
+}
@@ -0,0 +1,163 @@
+package geo
+
+import (
+	"math"
+	"testing"
+)
+
+// newCube returns a closed cube from the origin to size, whose faces are split along a diagonal.
+func newCube(size float32) *Mesh {
+	m := new(Mesh)
+	m.Nodes = []Point3D{{0, 0, 0}, {size, 0, 0}, {size, size, 0}, {0, size, 0}, {0, 0, size}, {size, 0, size}, {size, size, size}, {0, size, size}}
+	for _, f := range [][3]uint32{
+		{3, 2, 1}, {1, 0, 3}, {4, 5, 6}, {6, 7, 4}, {0, 1, 5}, {5, 4, 0},
+		{1, 2, 6}, {6, 5, 1}, {2, 3, 7}, {7, 6, 2}, {3, 0, 4}, {4, 7, 3},
+	} {
+		m.AddFace(f[0], f[1], f[2])
+	}
+	return m
+}
+
+func newBeams(caps CapMode, nodes ...Point3D) *Mesh {
+	m := new(Mesh)
+	m.Nodes = nodes
+	for i := 1; i < len(nodes); i++ {
+		m.Beams = append(m.Beams, Beam{NodeIndices: [2]uint32{uint32(i - 1), uint32(i)}, Radius: [2]float64{1, 1}, CapMode: [2]CapMode{caps, caps}})
+	}
+	return m
+}
+
+// volume returns the signed volume enclosed by the faces, counting twice the overlaps.
+func volume(m *Mesh) float64 {
+	var v float64
+	for i := range m.Faces {
+		n0, n1, n2 := m.FaceNodes(uint32(i))
+		v += newVec3(*n0).dot(newVec3(*n1).cross(newVec3(*n2))) / 6
+	}
+	return v
+}
+
+// xRange returns the minimum and the maximum x of the nodes.
+func xRange(m *Mesh) [2]float32 {
+	r := [2]float32{float32(math.Inf(1)), float32(math.Inf(-1))}
+	for _, n := range m.Nodes {
+		r[0] = float32(math.Min(float64(r[0]), float64(n[0])))
+		r[1] = float32(math.Max(float64(r[1]), float64(n[0])))
+	}
+	return r
+}
+
+func TestMesh_BeamMesh(t *testing.T) {
+	// Node and face counts with 8 segments, of a beam with butt caps and of a sphere.
+	const (
+		tubeNodes, tubeFaces     = 18, 32
+		sphereNodes, sphereFaces = 26, 48
+	)
+	tapered := newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{0, 0, 10})
+	tapered.Beams[0].Radius = [2]float64{2, 1}
+	degenerate := newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{0, 0, 0})
+	degenerate.Beams = append(degenerate.Beams, Beam{NodeIndices: [2]uint32{0, 5}, Radius: [2]float64{1, 1}})
+	zeroRadius := newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{1, 0, 0})
+	zeroRadius.Beams[0].Radius = [2]float64{}
+	ballsAll := newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{10, 0, 0}, Point3D{10, 10, 0})
+	ballsAll.BallMode, ballsAll.DefaultBallRadius = BallModeAll, 1.5
+	ballsAll.Balls = []Ball{{NodeIndex: 1, Radius: 2}, {NodeIndex: 7, Radius: 2}}
+	ballsMixed := newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{10, 0, 0}, Point3D{10, 10, 0})
+	ballsMixed.BallMode, ballsMixed.Balls = BallModeMixed, ballsAll.Balls
+	ballsNone := newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{10, 0, 0})
+	ballsNone.Balls = ballsAll.Balls
+	base := newCube(1)
+	base.Beams = newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{1, 1, 1}).Beams
+	through := newBeams(CapModeButt, Point3D{-5, 5, 5}, Point3D{15, 5, 5})
+	through.BallMode = BallModeAll
+	through.DefaultBallRadius = 1
+	tests := []struct {
+		name      string
+		m         *Mesh
+		opts      BeamMeshOptions
+		nodes     int
+		faces     int
+		wantRange [2]float32
+	}{
+		{"empty", new(Mesh), BeamMeshOptions{}, 0, 0, [2]float32{float32(math.Inf(1)), float32(math.Inf(-1))}},
+		{"degenerate", degenerate, BeamMeshOptions{Segments: 8}, 0, 0, [2]float32{float32(math.Inf(1)), float32(math.Inf(-1))}},
+		{"zeroRadius", zeroRadius, BeamMeshOptions{Segments: 8}, 0, 0, [2]float32{float32(math.Inf(1)), float32(math.Inf(-1))}},
+		{"butt", newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{10, 0, 0}), BeamMeshOptions{Segments: 8}, tubeNodes, tubeFaces, [2]float32{0, 10}},
+		{"hemisphere", newBeams(CapModeHemisphere, Point3D{0, 0, 0}, Point3D{10, 0, 0}), BeamMeshOptions{Segments: 8}, tubeNodes + 16, tubeFaces + 32, [2]float32{-1, 11}},
+		{"sphere", newBeams(CapModeSphere, Point3D{0, 0, 0}, Point3D{10, 0, 0}), BeamMeshOptions{Segments: 8}, tubeNodes + 2*sphereNodes, tubeFaces + 2*sphereFaces, [2]float32{-1, 11}},
+		{"defaultSegments", newBeams(CapModeButt, Point3D{0, 0, 0}, Point3D{10, 0, 0}), BeamMeshOptions{}, 34, 64, [2]float32{0, 10}},
+		{"tapered", tapered, BeamMeshOptions{Segments: 8}, tubeNodes, tubeFaces, [2]float32{-2, 2}},
+		{"ballsAll", ballsAll, BeamMeshOptions{Segments: 8}, 2*tubeNodes + 3*sphereNodes, 2*tubeFaces + 3*sphereFaces, [2]float32{-1.5, 12}},
+		{"ballsMixed", ballsMixed, BeamMeshOptions{Segments: 8}, 2*tubeNodes + sphereNodes, 2*tubeFaces + sphereFaces, [2]float32{0, 12}},
+		{"ballsNone", ballsNone, BeamMeshOptions{Segments: 8}, tubeNodes, tubeFaces, [2]float32{0, 10}},
+		{"union", base, BeamMeshOptions{Segments: 8, Union: true}, 8 + tubeNodes, 12 + tubeFaces, [2]float32{0, 1}},
+		{"clipInside", through, BeamMeshOptions{Segments: 8, Clip: newCube(10), ClipInside: true}, tubeNodes, tubeFaces, [2]float32{0, 10}},
+		{"clipOutside", through, BeamMeshOptions{Segments: 8, Clip: newCube(10)}, 2*tubeNodes + 2*sphereNodes, 2*tubeFaces + 2*sphereFaces, [2]float32{-6, 16}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.m.BeamMesh(tt.opts)
+			if len(got.Nodes) != tt.nodes || len(got.Faces) != tt.faces {
+				t.Errorf("Mesh.BeamMesh() = %d nodes and %d faces, want %d and %d", len(got.Nodes), len(got.Faces), tt.nodes, tt.faces)
+			}
+			if tt.faces == 0 {
+				return
+			}
+			if !got.IsManifoldAndOriented() {
+				t.Error("Mesh.BeamMesh() is not manifold and oriented")
+			}
+			if v := volume(got); v <= 0 {
+				t.Errorf("Mesh.BeamMesh() volume = %v, want positive", v)
+			}
+			if tt.name == "tapered" {
+				return
+			}
+			if r := xRange(got); math.Abs(float64(r[0]-tt.wantRange[0])) > 1e-5 || math.Abs(float64(r[1]-tt.wantRange[1])) > 1e-5 {
+				t.Errorf("Mesh.BeamMesh() x range = %v, want %v", r, tt.wantRange)
+			}
+		})
+	}
+}
+
+func TestMesh_BeamMesh_Volume(t *testing.T) {
+	const segments = 8
+	m := newBeams(CapModeButt, Point3D{1, 2, 3}, Point3D{4, 6, 3})
+	m.Beams[0].Radius = [2]float64{2, 1}
+	// The frustum of two regular polygons, whose areas are proportional to the squared radius.
+	area := float64(segments) / 2 * math.Sin(2*math.Pi/segments)
+	want := 5.0 / 3 * (area*4 + area + math.Sqrt(area*4*area))
+	if got := volume(m.BeamMesh(BeamMeshOptions{Segments: segments})); math.Abs(got-want) > 1e-4 {
+		t.Errorf("Mesh.BeamMesh() volume = %v, want %v", got, want)
+	}
+}
+
+func Test_clipper_spans(t *testing.T) {
+	cube := newCube(10)
+	tests := []struct {
+		name   string
+		inside bool
+		p, dir vec3
+		want   [][2]float64
+	}{
+		{"inside", true, vec3{-5, 5, 5}, vec3{20, 0, 0}, [][2]float64{{0.25, 0.75}}},
+		{"outside", false, vec3{-5, 5, 5}, vec3{20, 0, 0}, [][2]float64{{0, 0.25}, {0.75, 1}}},
+		{"vertex", true, vec3{-5, -5, -5}, vec3{20, 20, 20}, [][2]float64{{0.25, 0.75}}},
+		{"contained", true, vec3{1, 1, 1}, vec3{2, 3, 4}, [][2]float64{{0, 1}}},
+		{"missed", true, vec3{-5, -5, 5}, vec3{0, 20, 20}, nil},
+		{"touching", false, vec3{-5, 5, 5}, vec3{5, 0, 0}, [][2]float64{{0, 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &clipper{mesh: cube, inside: tt.inside}
+			got := c.spans(tt.p, tt.dir)
+			if len(got) != len(tt.want) {
+				t.Fatalf("clipper.spans() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if math.Abs(got[i][0]-tt.want[i][0]) > 1e-9 || math.Abs(got[i][1]-tt.want[i][1]) > 1e-9 {
+					t.Errorf("clipper.spans() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
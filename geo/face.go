@@ -7,8 +7,16 @@ type Face struct {
 	ResourceIndices [3]uint32 // Resource subindex of the three nodes that defines the face.
 }
 
+// TriangleSet defines a named group of faces, such as a painted region or a named surface.
+type TriangleSet struct {
+	Refs       []uint32 // Indices of the faces of the set, which the decoder sorts and deduplicates.
+	Name       string
+	Identifier string
+}
+
 type faceStructure struct {
-	Faces []Face
+	Faces        []Face
+	TriangleSets []TriangleSet
 }
 
 // AddFace adds a face to the mesh that has the target nodes.
@@ -29,5 +37,12 @@ func (f *faceStructure) checkSanity(nodeCount uint32) bool {
 			return false
 		}
 	}
+	for _, set := range f.TriangleSets {
+		for _, ref := range set.Refs {
+			if ref >= uint32(len(f.Faces)) {
+				return false
+			}
+		}
+	}
 	return true
 }
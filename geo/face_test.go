@@ -46,6 +46,8 @@ func Test_faceStructure_checkSanity(t *testing.T) {
 		{"i1big", &faceStructure{Faces: []Face{{NodeIndices: [3]uint32{0, 3, 2}}}}, args{3}, false},
 		{"i2big", &faceStructure{Faces: []Face{{NodeIndices: [3]uint32{0, 1, 3}}}}, args{3}, false},
 		{"good", &faceStructure{Faces: []Face{{NodeIndices: [3]uint32{0, 1, 2}}}}, args{3}, true},
+		{"setRefBig", &faceStructure{Faces: []Face{{NodeIndices: [3]uint32{0, 1, 2}}}, TriangleSets: []TriangleSet{{Refs: []uint32{0, 1}}}}, args{3}, false},
+		{"goodSet", &faceStructure{Faces: []Face{{NodeIndices: [3]uint32{0, 1, 2}}}, TriangleSets: []TriangleSet{{Refs: []uint32{0}}}}, args{3}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,95 @@
+package io3mf
+
+import (
+	"encoding/xml"
+
+	go3mf "github.com/qmuntal/go3mf"
+)
+
+type alternativesDecoder struct {
+	emptyDecoder
+	object             *go3mf.ObjectResource
+	alternativeDecoder alternativeDecoder
+}
+
+func (d *alternativesDecoder) Open() {
+	d.alternativeDecoder.object = d.object
+}
+
+func (d *alternativesDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsAlternativesSpec && name.Local == attrAlternative {
+		child = &d.alternativeDecoder
+	}
+	return
+}
+
+type alternativeDecoder struct {
+	emptyDecoder
+	object *go3mf.ObjectResource
+}
+
+func (d *alternativeDecoder) Attributes(attrs []xml.Attr) bool {
+	var (
+		alt                  go3mf.Alternative
+		hasObjectID, hasUUID bool
+	)
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrObjectID:
+			alt.ObjectID, ok = d.file.parser.ParseUint32Required(attrObjectID, a.Value)
+			hasObjectID = true
+		case attrProdUUID:
+			if err := validateUUID(a.Value); err != nil {
+				ok = d.file.parser.InvalidRequiredAttr(attrProdUUID, a.Value)
+			} else {
+				alt.UUID = a.Value
+			}
+			hasUUID = true
+		case attrPath:
+			alt.Path = a.Value
+		case attrModelResolution:
+			var valid bool
+			if alt.ModelResolution, valid = newSliceResolution(a.Value); !valid {
+				d.file.parser.InvalidOptionalAttr(attrModelResolution, a.Value)
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	if !hasObjectID {
+		return d.file.parser.MissingAttr(attrObjectID)
+	}
+	if !hasUUID {
+		ok = d.file.parser.MissingAttr(attrProdUUID)
+	}
+	if ok {
+		d.object.Alternatives = append(d.object.Alternatives, alt)
+	}
+	return ok
+}
+
+func (w *modelWriter) writeAlternatives(alts []go3mf.Alternative) {
+	if len(alts) == 0 {
+		return
+	}
+	w.startNS(nsAlternativesSpec, attrAlternatives)
+	for _, alt := range alts {
+		attrs := []xml.Attr{
+			w.attr(attrObjectID, formatUint32(alt.ObjectID)),
+			w.attr(attrProdUUID, alt.UUID),
+		}
+		if alt.Path != "" {
+			attrs = append(attrs, w.attr(attrPath, alt.Path))
+		}
+		if alt.ModelResolution != go3mf.ResolutionFull {
+			attrs = append(attrs, w.attr(attrModelResolution, alt.ModelResolution.String()))
+		}
+		w.elementNS(nsAlternativesSpec, attrAlternative, attrs...)
+	}
+	w.endNS(nsAlternativesSpec, attrAlternatives)
+}
@@ -0,0 +1,351 @@
+package amf
+
+import (
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"math"
+	"strconv"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+// Decoder can decode an AMF, which can be zipped, to a model.
+//
+// Each object is decoded as a MeshResource whose volumes share the same mesh,
+// and the faces of a volume reference its material, which is added to a BaseMaterialsResource.
+// Each constellation is decoded as a ComponentsResource that contains its instances.
+// The constellations that are not instantiated by other constellations are the build items,
+// or all the objects if there is no constellation.
+// The metadata of the amf element is added to the model metadata.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder creates a new decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: r,
+	}
+}
+
+// Decode creates the model from a read stream.
+func (d *Decoder) Decode(m *go3mf.Model) error {
+	return d.DecodeContext(context.Background(), m)
+}
+
+// DecodeContext creates the model from a read stream.
+func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
+	r, err := amfReader(d.r)
+	if err != nil {
+		return err
+	}
+	dec := amfDecoder{model: m}
+	if err = dec.decodeXML(ctx, xml.NewDecoder(r)); err != nil {
+		return err
+	}
+	return dec.build()
+}
+
+// amfReader returns a reader of the AMF XML document, which is the first file of the archive if r is zipped.
+func amfReader(r io.Reader) (io.Reader, error) {
+	b := bufio.NewReader(r)
+	if magic, err := b.Peek(2); err != nil || string(magic) != "PK" {
+		return b, nil
+	}
+	data, err := ioutil.ReadAll(b)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		data, err = ioutil.ReadAll(rc)
+		return bytes.NewReader(data), err
+	}
+	return nil, errors.New("go3mf: amf archive is empty")
+}
+
+type amfDecoder struct {
+	model          *go3mf.Model
+	objects        []xmlObject
+	materials      []xmlMaterial
+	constellations []xmlConstellation
+	resolved       map[string]go3mf.Object
+	resolving      map[string]bool
+	instantiated   map[string]bool
+}
+
+func (d *amfDecoder) decodeXML(ctx context.Context, x *xml.Decoder) error {
+	for {
+		t, err := x.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "amf":
+			err = d.decodeUnits(start)
+		case "metadata":
+			var md xmlMetadata
+			if err = x.DecodeElement(&md, &start); err == nil {
+				d.model.Metadata = append(d.model.Metadata, go3mf.Metadata{Name: md.Type, Value: md.Value})
+			}
+		case "object":
+			var o xmlObject
+			if err = x.DecodeElement(&o, &start); err == nil {
+				d.objects = append(d.objects, o)
+			}
+		case "material":
+			var mat xmlMaterial
+			if err = x.DecodeElement(&mat, &start); err == nil {
+				d.materials = append(d.materials, mat)
+			}
+		case "constellation":
+			var c xmlConstellation
+			if err = x.DecodeElement(&c, &start); err == nil {
+				d.constellations = append(d.constellations, c)
+			}
+		default:
+			err = x.Skip()
+		}
+		if err != nil {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default: // Default is must to avoid blocking
+		}
+	}
+}
+
+func (d *amfDecoder) decodeUnits(start xml.StartElement) error {
+	for _, a := range start.Attr {
+		if a.Name.Local != "unit" {
+			continue
+		}
+		units, ok := map[string]go3mf.Units{
+			"millimeter": go3mf.UnitMillimeter,
+			"micron":     go3mf.UnitMicrometer,
+			"micrometer": go3mf.UnitMicrometer,
+			"centimeter": go3mf.UnitCentimeter,
+			"inch":       go3mf.UnitInch,
+			"feet":       go3mf.UnitFoot,
+			"foot":       go3mf.UnitFoot,
+			"meter":      go3mf.UnitMeter,
+		}[a.Value]
+		if !ok {
+			return fmt.Errorf("go3mf: amf unit '%s' is not supported", a.Value)
+		}
+		d.model.Units = units
+	}
+	return nil
+}
+
+// build adds the decoded elements to the model, once all of them are known,
+// as the elements can reference the ones that are defined afterwards.
+func (d *amfDecoder) build() error {
+	materials := d.buildMaterials()
+	d.resolved = make(map[string]go3mf.Object)
+	d.resolving = make(map[string]bool)
+	d.instantiated = make(map[string]bool)
+	for i := range d.objects {
+		o, err := d.buildObject(&d.objects[i], materials)
+		if err != nil {
+			return err
+		}
+		d.resolved[d.objects[i].ID] = o
+	}
+	for _, c := range d.constellations {
+		if _, err := d.resolve(c.ID); err != nil {
+			return err
+		}
+	}
+	if len(d.constellations) == 0 {
+		for _, o := range d.objects {
+			d.model.BuildItems = append(d.model.BuildItems, &go3mf.BuildItem{Object: d.resolved[o.ID]})
+		}
+		return nil
+	}
+	for _, c := range d.constellations {
+		if !d.instantiated[c.ID] {
+			d.model.BuildItems = append(d.model.BuildItems, &go3mf.BuildItem{Object: d.resolved[c.ID]})
+		}
+	}
+	return nil
+}
+
+// materialRef is the base material of a volume.
+type materialRef struct {
+	id, index uint32
+}
+
+func (d *amfDecoder) buildMaterials() map[string]materialRef {
+	refs := make(map[string]materialRef, len(d.materials))
+	if len(d.materials) == 0 {
+		return refs
+	}
+	res := &go3mf.BaseMaterialsResource{ID: d.model.UnusedID(), ModelPath: d.model.Path}
+	for _, mat := range d.materials {
+		refs[mat.ID] = materialRef{id: res.ID, index: uint32(len(res.Materials))}
+		res.Materials = append(res.Materials, go3mf.BaseMaterial{Name: name(mat.Metadata), Color: materialColor(mat.Color)})
+	}
+	d.model.Resources = append(d.model.Resources, res)
+	return refs
+}
+
+// defaultColor is the color of the materials that do not have a constant color.
+var defaultColor = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+// materialColor converts the color of a material, the colors defined by formulas are not supported.
+func materialColor(c *xmlColor) color.RGBA {
+	if c == nil {
+		return defaultColor
+	}
+	var rgba [4]uint8
+	for i, s := range [4]string{c.R, c.G, c.B, c.A} {
+		if i == 3 && s == "" {
+			rgba[i] = 255
+			continue
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return defaultColor
+		}
+		rgba[i] = uint8(math.Max(0, math.Min(1, f))*255 + 0.5)
+	}
+	return color.RGBA{R: rgba[0], G: rgba[1], B: rgba[2], A: rgba[3]}
+}
+
+func (d *amfDecoder) buildObject(o *xmlObject, materials map[string]materialRef) (*go3mf.MeshResource, error) {
+	res := &go3mf.MeshResource{
+		ObjectResource: go3mf.ObjectResource{ID: d.model.UnusedID(), ModelPath: d.model.Path, Name: name(o.Metadata)},
+		Mesh:           new(geo.Mesh),
+	}
+	for _, md := range o.Metadata {
+		if md.Type != "name" {
+			res.Metadata = append(res.Metadata, go3mf.Metadata{Name: md.Type, Value: md.Value})
+		}
+	}
+	mesh := res.Mesh
+	mesh.Nodes = make([]geo.Point3D, len(o.Vertices))
+	for i, v := range o.Vertices {
+		mesh.Nodes[i] = geo.Point3D{v.X, v.Y, v.Z}
+	}
+	nodeCount := uint32(len(mesh.Nodes))
+	for _, volume := range o.Volumes {
+		mat, hasMaterial := materials[volume.MaterialID]
+		for _, t := range volume.Triangles {
+			if t.V1 >= nodeCount || t.V2 >= nodeCount || t.V3 >= nodeCount {
+				return nil, fmt.Errorf("go3mf: amf object %s has a triangle out of range", o.ID)
+			}
+			if t.V1 == t.V2 || t.V1 == t.V3 || t.V2 == t.V3 {
+				continue
+			}
+			f := mesh.AddFace(t.V1, t.V2, t.V3)
+			if hasMaterial {
+				f.Resource = mat.id
+				f.ResourceIndices = [3]uint32{mat.index, mat.index, mat.index}
+			}
+		}
+	}
+	d.model.Resources = append(d.model.Resources, res)
+	return res, nil
+}
+
+// resolve returns the object or the constellation with the target id,
+// the constellations are added to the model after all the constellations they instantiate.
+func (d *amfDecoder) resolve(id string) (go3mf.Object, error) {
+	if o, ok := d.resolved[id]; ok {
+		return o, nil
+	}
+	var c *xmlConstellation
+	for i := range d.constellations {
+		if d.constellations[i].ID == id {
+			c = &d.constellations[i]
+			break
+		}
+	}
+	if c == nil {
+		return nil, fmt.Errorf("go3mf: amf object %s not found", id)
+	}
+	if d.resolving[id] {
+		return nil, fmt.Errorf("go3mf: amf constellation %s instantiates itself", id)
+	}
+	d.resolving[id] = true
+	res := &go3mf.ComponentsResource{ObjectResource: go3mf.ObjectResource{ModelPath: d.model.Path, Name: name(c.Metadata)}}
+	for _, inst := range c.Instances {
+		o, err := d.resolve(inst.ObjectID)
+		if err != nil {
+			return nil, err
+		}
+		d.instantiated[inst.ObjectID] = true
+		res.Components = append(res.Components, &go3mf.Component{Object: o, Transform: instanceTransform(inst)})
+	}
+	res.ID = d.model.UnusedID()
+	d.model.Resources = append(d.model.Resources, res)
+	d.resolved[id] = res
+	return res, nil
+}
+
+// instanceTransform returns the 3MF transform of an instance, which is rotated,
+// in degrees, first around the x axis, then around the y axis and then around the z axis,
+// and finally translated.
+func instanceTransform(inst xmlInstance) geo.Matrix {
+	sx, cx := sincos(inst.RX)
+	sy, cy := sincos(inst.RY)
+	sz, cz := sincos(inst.RZ)
+	// r is Rz*Ry*Rx for column vectors, 3MF transforms row vectors so it is transposed.
+	r := [3][3]float64{
+		{cz * cy, cz*sy*sx - sz*cx, cz*sy*cx + sz*sx},
+		{sz * cy, sz*sy*sx + cz*cx, sz*sy*cx - cz*sx},
+		{-sy, cy * sx, cy * cx},
+	}
+	m := geo.Identity()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			// Adding zero turns the negative zeros into positive ones.
+			m[i*4+j] = float32(r[j][i]) + 0
+		}
+	}
+	m[12], m[13], m[14] = inst.DeltaX, inst.DeltaY, inst.DeltaZ
+	return m
+}
+
+// sincos returns the sine and the cosine of an angle in degrees,
+// snapping to zero the rounding errors of the multiples of 90 degrees.
+func sincos(degrees float64) (float64, float64) {
+	s, c := math.Sincos(degrees * math.Pi / 180)
+	if math.Abs(s) < 1e-12 {
+		s = 0
+	}
+	if math.Abs(c) < 1e-12 {
+		c = 0
+	}
+	return s, c
+}
@@ -0,0 +1,201 @@
+package amf
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"image/color"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+const amfFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<amf unit="inch" version="1.1">
+	<metadata type="name">Job</metadata>
+	<metadata type="cad">Other</metadata>
+	<constellation id="10">
+		<instance objectid="11"><deltax>5</deltax><deltay/><deltaz>0</deltaz></instance>
+		<instance objectid="0"><deltax>1</deltax><deltay>2</deltay><deltaz>3</deltaz><rz>90</rz></instance>
+	</constellation>
+	<object id="0">
+		<metadata type="name">Cube</metadata>
+		<metadata type="color">red</metadata>
+		<mesh>
+			<vertices>
+				<vertex><coordinates><x>0</x><y>0</y><z>0</z></coordinates></vertex>
+				<vertex><coordinates><x>1</x><y>0</y><z>0</z></coordinates></vertex>
+				<vertex><coordinates><x>0</x><y>1</y><z>0</z></coordinates><color><r>1</r><g>0</g><b>0</b></color></vertex>
+				<vertex><coordinates><x>0</x><y>0</y><z>1</z></coordinates></vertex>
+			</vertices>
+			<volume materialid="2">
+				<triangle><v1>0</v1><v2>2</v2><v3>1</v3></triangle>
+				<triangle><v1>0</v1><v2>1</v2><v3>1</v3></triangle>
+			</volume>
+			<volume>
+				<triangle><v1>0</v1><v2>1</v2><v3>3</v3></triangle>
+			</volume>
+			<volume materialid="1">
+				<triangle><v1>1</v1><v2>2</v2><v3>3</v3></triangle>
+			</volume>
+		</mesh>
+	</object>
+	<texture id="1" width="1" height="1" depth="1" type="grayscale">AA==</texture>
+	<constellation id="11">
+		<metadata type="name">Pair</metadata>
+		<instance objectid="0"/>
+	</constellation>
+	<material id="1">
+		<metadata type="name">Red</metadata>
+		<color><r>1</r><g>0</g><b>0</b><a>0.5</a></color>
+	</material>
+	<material id="2"></material>
+</amf>`
+
+func newAMFModel() *go3mf.Model {
+	base := &go3mf.BaseMaterialsResource{ID: 1, Materials: []go3mf.BaseMaterial{
+		{Name: "Red", Color: color.RGBA{255, 0, 0, 128}},
+		{Color: defaultColor},
+	}}
+	mesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 2, Name: "Cube", Metadata: []go3mf.Metadata{{Name: "color", Value: "red"}}}, Mesh: new(geo.Mesh)}
+	mesh.Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	mesh.Mesh.Faces = []geo.Face{
+		{NodeIndices: [3]uint32{0, 2, 1}, Resource: 1, ResourceIndices: [3]uint32{1, 1, 1}},
+		{NodeIndices: [3]uint32{0, 1, 3}},
+		{NodeIndices: [3]uint32{1, 2, 3}, Resource: 1},
+	}
+	pair := &go3mf.ComponentsResource{ObjectResource: go3mf.ObjectResource{ID: 3, Name: "Pair"}, Components: []*go3mf.Component{
+		{Object: mesh, Transform: geo.Identity()},
+	}}
+	job := &go3mf.ComponentsResource{ObjectResource: go3mf.ObjectResource{ID: 4}, Components: []*go3mf.Component{
+		{Object: pair, Transform: geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 5, 0, 0, 1}},
+		{Object: mesh, Transform: geo.Matrix{0, 1, 0, 0, -1, 0, 0, 0, 0, 0, 1, 0, 1, 2, 3, 1}},
+	}}
+	return &go3mf.Model{
+		Units:      go3mf.UnitInch,
+		Metadata:   []go3mf.Metadata{{Name: "name", Value: "Job"}, {Name: "cad", Value: "Other"}},
+		Resources:  []go3mf.Resource{base, mesh, pair, job},
+		BuildItems: []*go3mf.BuildItem{{Object: job}},
+	}
+}
+
+func zipped(name, content string) io.Reader {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	if name != "" {
+		w.Create("folder/")
+		f, _ := w.Create(name)
+		f.Write([]byte(content))
+	}
+	w.Close()
+	return buf
+}
+
+func TestNewDecoder(t *testing.T) {
+	r := new(bytes.Buffer)
+	if got, want := NewDecoder(r), (&Decoder{r: r}); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewDecoder() = %v, want %v", got, want)
+	}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	simple := new(go3mf.Model)
+	simpleMesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 1}, Mesh: new(geo.Mesh)}
+	simpleMesh.Mesh.Nodes = []geo.Point3D{{0, 0, 0}}
+	simple.Resources = []go3mf.Resource{simpleMesh}
+	simple.BuildItems = []*go3mf.BuildItem{{Object: simpleMesh}}
+	tests := []struct {
+		name    string
+		r       io.Reader
+		want    *go3mf.Model
+		wantErr bool
+	}{
+		{"empty", strings.NewReader(""), new(go3mf.Model), false},
+		{"full", strings.NewReader(amfFixture), newAMFModel(), false},
+		{"zipped", zipped("model.amf", amfFixture), newAMFModel(), false},
+		{"noConstellation", strings.NewReader(`<amf><object id="1"><mesh><vertices><vertex><coordinates><x>0</x></coordinates></vertex></vertices></mesh></object></amf>`), simple, false},
+		{"emptyZip", zipped("", ""), nil, true},
+		{"invalidZip", strings.NewReader("PK invalid"), nil, true},
+		{"invalidXML", strings.NewReader("<amf><object>"), nil, true},
+		{"invalidUnit", strings.NewReader(`<amf unit="league"></amf>`), nil, true},
+		{"invalidCoordinate", strings.NewReader(`<amf><object id="1"><mesh><vertices><vertex><coordinates><x>a</x></coordinates></vertex></vertices></mesh></object></amf>`), nil, true},
+		{"invalidMetadata", strings.NewReader(`<amf><metadata><a></metadata></amf>`), nil, true},
+		{"invalidMaterial", strings.NewReader(`<amf><material><a></material></amf>`), nil, true},
+		{"invalidConstellation", strings.NewReader(`<amf><constellation><instance><deltax>a</deltax></instance></constellation></amf>`), nil, true},
+		{"outOfRange", strings.NewReader(`<amf><object id="1"><mesh><volume><triangle><v1>0</v1><v2>1</v2><v3>2</v3></triangle></volume></mesh></object></amf>`), nil, true},
+		{"missingObject", strings.NewReader(`<amf><constellation id="1"><instance objectid="2"/></constellation></amf>`), nil, true},
+		{"cycle", strings.NewReader(`<amf><constellation id="1"><instance objectid="2"/></constellation><constellation id="2"><instance objectid="1"/></constellation></amf>`), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := new(go3mf.Model)
+			if err := NewDecoder(tt.r).Decode(got); (err != nil) != tt.wantErr {
+				t.Errorf("Decoder.Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			deep.CompareUnexportedFields = true
+			deep.MaxDepth = 20
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_DecodeContext_Cancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewDecoder(strings.NewReader(amfFixture)).DecodeContext(ctx, new(go3mf.Model)); err != context.Canceled {
+		t.Errorf("Decoder.DecodeContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func Test_materialColor(t *testing.T) {
+	tests := []struct {
+		name string
+		c    *xmlColor
+		want color.RGBA
+	}{
+		{"nil", nil, defaultColor},
+		{"opaque", &xmlColor{R: "0", G: "0.5", B: "1"}, color.RGBA{0, 128, 255, 255}},
+		{"alpha", &xmlColor{R: "2", G: "-1", B: "1", A: "0"}, color.RGBA{255, 0, 255, 0}},
+		{"formula", &xmlColor{R: "x*2", G: "0", B: "1"}, defaultColor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := materialColor(tt.c); got != tt.want {
+				t.Errorf("materialColor() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_instanceTransform(t *testing.T) {
+	tests := []struct {
+		name string
+		inst xmlInstance
+		want geo.Matrix
+	}{
+		{"identity", xmlInstance{}, geo.Identity()},
+		{"translation", xmlInstance{DeltaX: 1, DeltaY: 2, DeltaZ: 3}, geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 1, 2, 3, 1}},
+		{"rx", xmlInstance{RX: 90}, geo.Matrix{1, 0, 0, 0, 0, 0, 1, 0, 0, -1, 0, 0, 0, 0, 0, 1}},
+		{"ry", xmlInstance{RY: 90}, geo.Matrix{0, 0, -1, 0, 0, 1, 0, 0, 1, 0, 0, 0, 0, 0, 0, 1}},
+		{"rz", xmlInstance{RZ: 180}, geo.Matrix{-1, 0, 0, 0, 0, -1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1}},
+		{"rxrz", xmlInstance{RX: 90, RZ: 90}, geo.Matrix{0, 1, 0, 0, 0, 0, 1, 0, 1, 0, 0, 0, 0, 0, 0, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := instanceTransform(tt.inst); got != tt.want {
+				t.Errorf("instanceTransform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
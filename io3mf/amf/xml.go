@@ -0,0 +1,71 @@
+package amf
+
+// The types of this file map the elements of the AMF schema that are decoded.
+
+type xmlMetadata struct {
+	Type  string `xml:"type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type xmlObject struct {
+	ID       string        `xml:"id,attr"`
+	Metadata []xmlMetadata `xml:"metadata"`
+	Vertices []xmlVertex   `xml:"mesh>vertices>vertex"`
+	Volumes  []xmlVolume   `xml:"mesh>volume"`
+}
+
+type xmlVertex struct {
+	X float32 `xml:"coordinates>x"`
+	Y float32 `xml:"coordinates>y"`
+	Z float32 `xml:"coordinates>z"`
+}
+
+type xmlVolume struct {
+	MaterialID string        `xml:"materialid,attr"`
+	Triangles  []xmlTriangle `xml:"triangle"`
+}
+
+type xmlTriangle struct {
+	V1 uint32 `xml:"v1"`
+	V2 uint32 `xml:"v2"`
+	V3 uint32 `xml:"v3"`
+}
+
+type xmlMaterial struct {
+	ID       string        `xml:"id,attr"`
+	Metadata []xmlMetadata `xml:"metadata"`
+	Color    *xmlColor     `xml:"color"`
+}
+
+type xmlColor struct {
+	R string `xml:"r"`
+	G string `xml:"g"`
+	B string `xml:"b"`
+	A string `xml:"a"`
+}
+
+type xmlConstellation struct {
+	ID        string        `xml:"id,attr"`
+	Metadata  []xmlMetadata `xml:"metadata"`
+	Instances []xmlInstance `xml:"instance"`
+}
+
+type xmlInstance struct {
+	ObjectID string  `xml:"objectid,attr"`
+	DeltaX   float32 `xml:"deltax"`
+	DeltaY   float32 `xml:"deltay"`
+	DeltaZ   float32 `xml:"deltaz"`
+	RX       float64 `xml:"rx"`
+	RY       float64 `xml:"ry"`
+	RZ       float64 `xml:"rz"`
+}
+
+// name returns the value of the name metadata.
+func name(metadata []xmlMetadata) string {
+	for _, m := range metadata {
+		if m.Type == "name" {
+			return m.Value
+		}
+	}
+	return ""
+}
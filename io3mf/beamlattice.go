@@ -2,6 +2,7 @@ package io3mf
 
 import (
 	"encoding/xml"
+	"fmt"
 
 	"github.com/qmuntal/go3mf"
 	"github.com/qmuntal/go3mf/geo"
@@ -9,13 +10,19 @@ import (
 
 type beamLatticeDecoder struct {
 	emptyDecoder
-	resource *go3mf.MeshResource
+	resource  *go3mf.MeshResource
+	nodeCount uint32
 }
 
 func (d *beamLatticeDecoder) Attributes(attrs []xml.Attr) bool {
 	ok := true
-	var hasRadius, hasMinLength bool
+	var hasRadius, hasMinLength, hasBallRadius bool
 	for _, a := range attrs {
+		if a.Name.Space == nsBallsSpec {
+			hasBallRadius = hasBallRadius || a.Name.Local == attrBallRadius
+			d.parseBallsAttr(a)
+			continue
+		}
 		if a.Name.Space != "" {
 			continue
 		}
@@ -45,9 +52,24 @@ func (d *beamLatticeDecoder) Attributes(attrs []xml.Attr) bool {
 	if !hasMinLength {
 		ok = d.file.parser.MissingAttr(attrMinLength)
 	}
+	if !hasBallRadius && d.resource.Mesh.BallMode != geo.BallModeNone {
+		d.resource.Mesh.DefaultBallRadius = d.resource.Mesh.DefaultRadius
+	}
 	return ok
 }
 
+func (d *beamLatticeDecoder) parseBallsAttr(a xml.Attr) {
+	switch a.Name.Local {
+	case attrBallMode:
+		var ok bool
+		if d.resource.Mesh.BallMode, ok = newBallMode(a.Value); !ok {
+			d.file.parser.InvalidOptionalAttr(attrBallMode, a.Value)
+		}
+	case attrBallRadius:
+		d.resource.Mesh.DefaultBallRadius = d.file.parser.ParseFloat64Optional(attrBallRadius, a.Value)
+	}
+}
+
 func (d *beamLatticeDecoder) Child(name xml.Name) (child nodeDecoder) {
 	if name.Space == nsBeamLatticeSpec {
 		if name.Local == attrBeams {
@@ -55,6 +77,8 @@ func (d *beamLatticeDecoder) Child(name xml.Name) (child nodeDecoder) {
 		} else if name.Local == attrBeamSets {
 			child = &beamSetsDecoder{mesh: d.resource.Mesh}
 		}
+	} else if name.Space == nsBallsSpec && name.Local == attrBalls {
+		child = &ballsDecoder{mesh: d.resource.Mesh, nodeCount: d.nodeCount}
 	}
 	return
 }
@@ -137,6 +161,73 @@ func (d *beamDecoder) Attributes(attrs []xml.Attr) bool {
 	return ok
 }
 
+type ballsDecoder struct {
+	emptyDecoder
+	mesh        *geo.Mesh
+	nodeCount   uint32
+	ballDecoder ballDecoder
+}
+
+// Open collects the nodes used by the beams, which precede the balls.
+func (d *ballsDecoder) Open() {
+	d.ballDecoder.mesh = d.mesh
+	d.ballDecoder.nodeCount = d.nodeCount
+	d.ballDecoder.beamNodes = make(map[uint32]struct{}, 2*len(d.mesh.Beams))
+	for _, beam := range d.mesh.Beams {
+		d.ballDecoder.beamNodes[beam.NodeIndices[0]] = struct{}{}
+		d.ballDecoder.beamNodes[beam.NodeIndices[1]] = struct{}{}
+	}
+}
+
+func (d *ballsDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsBallsSpec && name.Local == attrBall {
+		child = &d.ballDecoder
+	}
+	return
+}
+
+type ballDecoder struct {
+	emptyDecoder
+	mesh      *geo.Mesh
+	nodeCount uint32
+	beamNodes map[uint32]struct{}
+}
+
+func (d *ballDecoder) Attributes(attrs []xml.Attr) bool {
+	ball := geo.Ball{Radius: d.mesh.DefaultBallRadius}
+	var hasVIndex bool
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrVIndex:
+			ball.NodeIndex, ok = d.file.parser.ParseUint32Required(attrVIndex, a.Value)
+			hasVIndex = true
+		case attrR:
+			ball.Radius = d.file.parser.ParseFloat64Optional(attrR, a.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if !hasVIndex {
+		return d.file.parser.MissingAttr(attrVIndex)
+	}
+	if ball.NodeIndex >= d.nodeCount {
+		return d.file.parser.GenericError(true, "ball vertex index is out of range")
+	}
+	if _, ok := d.beamNodes[ball.NodeIndex]; !ok {
+		return d.file.parser.GenericError(true, "ball vertex is not the end of any beam")
+	}
+	// Balls have no effect with ballmode none, so they are validated but not kept.
+	if d.mesh.BallMode != geo.BallModeNone {
+		d.mesh.Balls = append(d.mesh.Balls, ball)
+	}
+	return true
+}
+
 type beamSetsDecoder struct {
 	emptyDecoder
 	mesh *geo.Mesh
@@ -205,3 +296,108 @@ func (d *beamRefDecoder) Attributes(attrs []xml.Attr) bool {
 	}
 	return d.file.parser.MissingAttr(attrIndex)
 }
+
+func hasBeamLattice(r *go3mf.MeshResource) bool {
+	if r.Mesh == nil {
+		return false
+	}
+	return len(r.Mesh.Beams) > 0 || len(r.Mesh.BeamSets) > 0 || r.Mesh.DefaultRadius != 0 || r.Mesh.MinLength != 0
+}
+
+func hasBalls(r *go3mf.MeshResource) bool {
+	if r.Mesh == nil {
+		return false
+	}
+	return len(r.Mesh.Balls) > 0 || r.Mesh.BallMode != geo.BallModeNone
+}
+
+func (w *modelWriter) writeBeamLattice(r *go3mf.MeshResource) {
+	m := r.Mesh
+	attrs := []xml.Attr{
+		w.attr(attrRadius, formatFloat64(m.DefaultRadius)),
+		w.attr(attrMinLength, formatFloat64(m.MinLength)),
+		w.attr(attrCap, m.CapMode.String()),
+	}
+	if r.BeamLatticeAttributes.ClipMode != go3mf.ClipNone {
+		attrs = append(attrs, w.attr(attrClippingMode, r.BeamLatticeAttributes.ClipMode.String()))
+	}
+	if r.BeamLatticeAttributes.ClippingMeshID != 0 {
+		attrs = append(attrs, w.attr(attrClippingMesh, formatUint32(r.BeamLatticeAttributes.ClippingMeshID)))
+	}
+	if r.BeamLatticeAttributes.RepresentationMeshID != 0 {
+		attrs = append(attrs, w.attr(attrRepresentationMesh, formatUint32(r.BeamLatticeAttributes.RepresentationMeshID)))
+	}
+	if m.BallMode == geo.BallModeNone && len(m.Balls) > 0 {
+		w.err = fmt.Errorf("go3mf: mesh %d has balls with ballmode none", r.ID)
+		return
+	}
+	if hasBalls(r) {
+		attrs = append(attrs, w.attrNS(nsBallsSpec, attrBallMode, m.BallMode.String()))
+		attrs = append(attrs, w.attrNS(nsBallsSpec, attrBallRadius, formatFloat64(m.DefaultBallRadius)))
+	}
+	w.startNS(nsBeamLatticeSpec, attrBeamLattice, attrs...)
+	w.startNS(nsBeamLatticeSpec, attrBeams)
+	for _, b := range m.Beams {
+		w.writeBeam(m, b)
+	}
+	w.endNS(nsBeamLatticeSpec, attrBeams)
+	if len(m.Balls) > 0 {
+		w.startNS(nsBallsSpec, attrBalls)
+		for _, b := range m.Balls {
+			w.writeBall(m, b)
+		}
+		w.endNS(nsBallsSpec, attrBalls)
+	}
+	if len(m.BeamSets) > 0 {
+		w.startNS(nsBeamLatticeSpec, attrBeamSets)
+		for _, set := range m.BeamSets {
+			w.writeBeamSet(set)
+		}
+		w.endNS(nsBeamLatticeSpec, attrBeamSets)
+	}
+	w.endNS(nsBeamLatticeSpec, attrBeamLattice)
+}
+
+func (w *modelWriter) writeBeam(m *geo.Mesh, b geo.Beam) {
+	attrs := []xml.Attr{
+		w.attr(attrV1, formatUint32(b.NodeIndices[0])),
+		w.attr(attrV2, formatUint32(b.NodeIndices[1])),
+	}
+	// Only write the properties that differ from the defaults applied by the decoder.
+	if b.Radius[0] != m.DefaultRadius {
+		attrs = append(attrs, w.attr(attrR1, formatFloat64(b.Radius[0])))
+	}
+	if b.Radius[1] != b.Radius[0] {
+		attrs = append(attrs, w.attr(attrR2, formatFloat64(b.Radius[1])))
+	}
+	if b.CapMode[0] != m.CapMode {
+		attrs = append(attrs, w.attr(attrCap1, b.CapMode[0].String()))
+	}
+	if b.CapMode[1] != m.CapMode {
+		attrs = append(attrs, w.attr(attrCap2, b.CapMode[1].String()))
+	}
+	w.elementNS(nsBeamLatticeSpec, attrBeam, attrs...)
+}
+
+func (w *modelWriter) writeBall(m *geo.Mesh, b geo.Ball) {
+	attrs := []xml.Attr{w.attr(attrVIndex, formatUint32(b.NodeIndex))}
+	if b.Radius != m.DefaultBallRadius {
+		attrs = append(attrs, w.attr(attrR, formatFloat64(b.Radius)))
+	}
+	w.elementNS(nsBallsSpec, attrBall, attrs...)
+}
+
+func (w *modelWriter) writeBeamSet(set geo.BeamSet) {
+	var attrs []xml.Attr
+	if set.Name != "" {
+		attrs = append(attrs, w.attr(attrName, set.Name))
+	}
+	if set.Identifier != "" {
+		attrs = append(attrs, w.attr(attrIdentifier, set.Identifier))
+	}
+	w.startNS(nsBeamLatticeSpec, attrBeamSet, attrs...)
+	for _, ref := range set.Refs {
+		w.elementNS(nsBeamLatticeSpec, attrRef, w.attr(attrIndex, formatUint32(ref)))
+	}
+	w.endNS(nsBeamLatticeSpec, attrBeamSet)
+}
@@ -0,0 +1,176 @@
+package io3mf
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	go3mf "github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+type booleanShapeDecoder struct {
+	emptyDecoder
+	resource       go3mf.BooleanShapeResource
+	booleanDecoder booleanDecoder
+}
+
+func (d *booleanShapeDecoder) Open() {
+	d.booleanDecoder.resource = &d.resource
+}
+
+// Close adds the boolean shape only if its base object has been resolved,
+// a non-strict decoding drops the shapes with an invalid base object.
+func (d *booleanShapeDecoder) Close() bool {
+	if d.resource.Object != nil {
+		d.file.AddResource(&d.resource)
+	}
+	return true
+}
+
+func (d *booleanShapeDecoder) Attributes(attrs []xml.Attr) bool {
+	ref := booleanRef{transform: &d.resource.Transform}
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrOperation {
+			var ok bool
+			if d.resource.Operation, ok = newBooleanOperation(a.Value); !ok {
+				d.file.parser.InvalidOptionalAttr(attrOperation, a.Value)
+			}
+		} else if !ref.parseAttr(d.file, a) {
+			return false
+		}
+	}
+	o, ok := ref.object(d.file)
+	if !ok || o == nil {
+		return ok
+	}
+	switch o.(type) {
+	case *go3mf.MeshResource, *go3mf.BooleanShapeResource:
+		d.resource.Object = o
+		return true
+	}
+	return d.file.parser.GenericError(true, "boolean base object is not a mesh or a boolean shape")
+}
+
+func (d *booleanShapeDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsBooleanSpec && name.Local == attrBoolean {
+		child = &d.booleanDecoder
+	}
+	return
+}
+
+type booleanDecoder struct {
+	emptyDecoder
+	resource *go3mf.BooleanShapeResource
+}
+
+func (d *booleanDecoder) Attributes(attrs []xml.Attr) bool {
+	var operand go3mf.Boolean
+	ref := booleanRef{transform: &operand.Transform}
+	for _, a := range attrs {
+		if !ref.parseAttr(d.file, a) {
+			return false
+		}
+	}
+	o, ok := ref.object(d.file)
+	if !ok || o == nil {
+		return ok
+	}
+	if _, isMesh := o.(*go3mf.MeshResource); !isMesh {
+		return d.file.parser.GenericError(true, "boolean operand is not a mesh object")
+	}
+	operand.Object = o
+	d.resource.Operands = append(d.resource.Operands, &operand)
+	return true
+}
+
+// booleanRef holds the attributes that reference an object from a boolean shape or from an operand.
+type booleanRef struct {
+	transform *geo.Matrix
+	path      string
+	objectID  uint32
+	hasID     bool
+}
+
+func (r *booleanRef) parseAttr(file *modelFile, a xml.Attr) bool {
+	ok := true
+	switch a.Name.Space {
+	case nsProductionSpec:
+		if a.Name.Local == attrPath {
+			r.path = a.Value
+		}
+	case "":
+		if a.Name.Local == attrObjectID {
+			r.objectID, ok = file.parser.ParseUint32Required(attrObjectID, a.Value)
+			r.hasID = true
+		} else if a.Name.Local == attrTransform {
+			var err error
+			*r.transform, err = strToMatrix(a.Value)
+			if err != nil {
+				file.parser.InvalidOptionalAttr(attrTransform, a.Value)
+			}
+		}
+	}
+	return ok
+}
+
+// object returns the referenced object, which is nil if it cannot be found.
+func (r *booleanRef) object(file *modelFile) (go3mf.Object, bool) {
+	if !r.hasID {
+		return nil, file.parser.MissingAttr(attrObjectID)
+	}
+	if r.path != "" && !file.isRoot {
+		return nil, file.parser.GenericError(true, "path attribute in a non-root file is not supported")
+	}
+	resource, ok := file.FindResource(r.path, r.objectID)
+	if !ok {
+		return nil, file.parser.GenericError(true, "non-existent referenced object")
+	}
+	o, ok := resource.(go3mf.Object)
+	if !ok {
+		return nil, file.parser.GenericError(true, "non-object referenced resource")
+	}
+	return o, true
+}
+
+func (w *modelWriter) writeBooleanShapeObject(r *go3mf.BooleanShapeResource) {
+	if w.err != nil {
+		return
+	}
+	if r.Object == nil {
+		w.err = fmt.Errorf("go3mf: boolean shape %d without base object", r.ID)
+		return
+	}
+	for _, op := range r.Operands {
+		if op.Object == nil {
+			w.err = fmt.Errorf("go3mf: boolean shape %d with an operand without object", r.ID)
+			return
+		}
+	}
+	w.start(attrObject, w.objectAttrs(&r.ObjectResource)...)
+	w.writeMetadataGroup(r.Metadata)
+	attrs := w.booleanRefAttrs(r.Object, r.HasTransform(), r.Transform)
+	if r.Operation != go3mf.BooleanUnion {
+		attrs = append(attrs, w.attr(attrOperation, r.Operation.String()))
+	}
+	w.startNS(nsBooleanSpec, attrBooleanShape, attrs...)
+	for _, op := range r.Operands {
+		w.elementNS(nsBooleanSpec, attrBoolean, w.booleanRefAttrs(op.Object, op.HasTransform(), op.Transform)...)
+	}
+	w.endNS(nsBooleanSpec, attrBooleanShape)
+	w.writeAlternatives(r.Alternatives)
+	w.writeUnknownTokens(w.extensions[&r.ObjectResource])
+	w.writeUnknownTokens(r.Unknown)
+	w.end(attrObject)
+}
+
+func (w *modelWriter) booleanRefAttrs(o go3mf.Object, hasTransform bool, t geo.Matrix) []xml.Attr {
+	path, id := o.Identify()
+	attrs := []xml.Attr{w.attr(attrObjectID, formatUint32(id))}
+	if hasTransform {
+		attrs = append(attrs, w.attr(attrTransform, formatMatrix(t)))
+	}
+	if w.isExternal(o) {
+		attrs = append(attrs, w.attrNS(nsProductionSpec, attrPath, path))
+	}
+	return attrs
+}
@@ -51,6 +51,8 @@ func (d *buildItemDecoder) Close() bool {
 func (d *buildItemDecoder) Child(name xml.Name) (child nodeDecoder) {
 	if name.Space == nsCoreSpec && name.Local == attrMetadataGroup {
 		child = &metadataGroupDecoder{metadatas: &d.item.Metadata}
+	} else {
+		child = d.file.unknownElement(&d.item.Unknown, name)
 	}
 	return
 }
@@ -88,6 +90,8 @@ func (d *buildItemDecoder) Attributes(attrs []xml.Attr) bool {
 			}
 		case "":
 			ok = d.parseCoreAttr(a)
+		default:
+			d.file.unknownAttr(&d.item.Unknown, a)
 		}
 		if !ok {
 			return false
@@ -116,3 +120,37 @@ func (d *buildItemDecoder) parseCoreAttr(a xml.Attr) bool {
 	}
 	return ok
 }
+
+func (w *modelWriter) writeBuild() {
+	var attrs []xml.Attr
+	if w.model.UUID != "" {
+		attrs = append(attrs, w.attrNS(nsProductionSpec, attrProdUUID, w.model.UUID))
+	}
+	w.start(attrBuild, attrs...)
+	for _, item := range w.model.BuildItems {
+		w.writeBuildItem(item)
+	}
+	w.end(attrBuild)
+}
+
+func (w *modelWriter) writeBuildItem(item *go3mf.BuildItem) {
+	path, id := item.Object.Identify()
+	attrs := []xml.Attr{w.attr(attrObjectID, formatUint32(id))}
+	if item.HasTransform() {
+		attrs = append(attrs, w.attr(attrTransform, formatMatrix(item.Transform)))
+	}
+	if item.PartNumber != "" {
+		attrs = append(attrs, w.attr(attrPartNumber, item.PartNumber))
+	}
+	if item.UUID != "" {
+		attrs = append(attrs, w.attrNS(nsProductionSpec, attrProdUUID, item.UUID))
+	}
+	if w.isExternal(item.Object) {
+		attrs = append(attrs, w.attrNS(nsProductionSpec, attrPath, path))
+	}
+	attrs = append(attrs, w.unknownAttrs(item.Unknown.Attr)...)
+	w.start(attrItem, attrs...)
+	w.writeMetadataGroup(item.Metadata)
+	w.writeUnknownTokens(item.Unknown)
+	w.end(attrItem)
+}
@@ -0,0 +1,199 @@
+package io3mf
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+)
+
+const algC14N = "http://www.w3.org/TR/2001/REC-xml-c14n-20010315"
+
+// c14nScope holds the namespaces and xml attributes in scope of an element
+// and the namespaces already rendered by its ancestors in the canonical output.
+type c14nScope struct {
+	namespaces map[string]string
+	xmlAttrs   map[string]string
+	rendered   map[string]string
+}
+
+func (s *c14nScope) child(start xml.StartElement) *c14nScope {
+	c := &c14nScope{
+		namespaces: make(map[string]string, len(s.namespaces)),
+		xmlAttrs:   make(map[string]string, len(s.xmlAttrs)),
+		rendered:   s.rendered,
+	}
+	for k, v := range s.namespaces {
+		c.namespaces[k] = v
+	}
+	for k, v := range s.xmlAttrs {
+		c.xmlAttrs[k] = v
+	}
+	for _, a := range start.Attr {
+		if a.Name.Space == "" && a.Name.Local == attrXmlns {
+			c.namespaces[""] = a.Value
+		} else if a.Name.Space == attrXmlns {
+			c.namespaces[a.Name.Local] = a.Value
+		} else if a.Name.Space == "xml" {
+			c.xmlAttrs[a.Name.Local] = a.Value
+		}
+	}
+	return c
+}
+
+// canonicalize returns the inclusive canonical form, without comments,
+// of the first element with the target namespace, local name and Id attribute.
+// An empty local name selects the document element and an empty id matches any element.
+func canonicalize(data []byte, space, local, id string) ([]byte, error) {
+	x := xml.NewDecoder(bytes.NewReader(data))
+	scopes := []*c14nScope{{namespaces: map[string]string{}, xmlAttrs: map[string]string{}, rendered: map[string]string{}}}
+	var (
+		out   bytes.Buffer
+		depth int
+	)
+	for {
+		t, err := x.RawToken()
+		if err == io.EOF {
+			return nil, errors.New("go3mf: canonicalization target element not found")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			scope := scopes[len(scopes)-1].child(t)
+			scopes = append(scopes, scope)
+			if depth == 0 && !isC14NTarget(t, scope, space, local, id) {
+				continue
+			}
+			writeC14NStart(&out, t, scope, depth == 0)
+			depth++
+		case xml.EndElement:
+			scopes = scopes[:len(scopes)-1]
+			if depth == 0 {
+				continue
+			}
+			out.WriteString("</" + rawName(t.Name) + ">")
+			if depth--; depth == 0 {
+				return out.Bytes(), nil
+			}
+		case xml.CharData:
+			if depth > 0 {
+				out.WriteString(escapeC14NText(string(t)))
+			}
+		case xml.ProcInst:
+			if depth > 0 {
+				out.WriteString("<?" + t.Target)
+				if len(t.Inst) > 0 {
+					out.WriteString(" " + string(t.Inst))
+				}
+				out.WriteString("?>")
+			}
+		}
+	}
+}
+
+func isC14NTarget(t xml.StartElement, scope *c14nScope, space, local, id string) bool {
+	if local == "" {
+		return true
+	}
+	if t.Name.Local != local || scope.namespaces[t.Name.Space] != space {
+		return false
+	}
+	if id == "" {
+		return true
+	}
+	for _, a := range t.Attr {
+		if a.Name.Space == "" && a.Name.Local == "Id" {
+			return a.Value == id
+		}
+	}
+	return false
+}
+
+// writeC14NStart writes the start tag with the namespace declarations
+// not already rendered by an ancestor and the attributes sorted by namespace and name.
+// The apex element also receives the xml attributes inherited from its ancestors.
+func writeC14NStart(out *bytes.Buffer, t xml.StartElement, scope *c14nScope, apex bool) {
+	rendered := make(map[string]string, len(scope.rendered))
+	for k, v := range scope.rendered {
+		rendered[k] = v
+	}
+	var prefixes []string
+	for prefix, uri := range scope.namespaces {
+		if prev, ok := rendered[prefix]; prefix == "xml" || (ok && prev == uri) || (!ok && prefix == "" && uri == "") {
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+		rendered[prefix] = uri
+	}
+	scope.rendered = rendered
+	sort.Strings(prefixes)
+
+	type c14nAttr struct {
+		space, local, name, value string
+	}
+	var attrs []c14nAttr
+	own := make(map[string]bool)
+	for _, a := range t.Attr {
+		if a.Name.Space == attrXmlns || (a.Name.Space == "" && a.Name.Local == attrXmlns) {
+			continue
+		}
+		space := ""
+		if a.Name.Space == "xml" {
+			space = nsXML
+			own[a.Name.Local] = true
+		} else if a.Name.Space != "" {
+			space = scope.namespaces[a.Name.Space]
+		}
+		attrs = append(attrs, c14nAttr{space, a.Name.Local, rawName(a.Name), a.Value})
+	}
+	if apex {
+		for local, value := range scope.xmlAttrs {
+			if !own[local] {
+				attrs = append(attrs, c14nAttr{nsXML, local, "xml:" + local, value})
+			}
+		}
+	}
+	sort.Slice(attrs, func(i, j int) bool {
+		if attrs[i].space != attrs[j].space {
+			return attrs[i].space < attrs[j].space
+		}
+		return attrs[i].local < attrs[j].local
+	})
+
+	out.WriteString("<" + rawName(t.Name))
+	for _, prefix := range prefixes {
+		name := attrXmlns
+		if prefix != "" {
+			name += ":" + prefix
+		}
+		out.WriteString(" " + name + `="` + escapeC14NAttr(scope.namespaces[prefix]) + `"`)
+	}
+	for _, a := range attrs {
+		out.WriteString(" " + a.name + `="` + escapeC14NAttr(a.value) + `"`)
+	}
+	out.WriteString(">")
+}
+
+func rawName(n xml.Name) string {
+	if n.Space == "" {
+		return n.Local
+	}
+	return n.Space + ":" + n.Local
+}
+
+var (
+	c14nTextReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	c14nAttrReplacer = strings.NewReplacer("&", "&amp;", "<", "&lt;", `"`, "&quot;", "\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
+
+func escapeC14NText(s string) string {
+	return c14nTextReplacer.Replace(s)
+}
+
+func escapeC14NAttr(s string) string {
+	return c14nAttrReplacer.Replace(s)
+}
@@ -0,0 +1,49 @@
+package io3mf
+
+import (
+	"testing"
+)
+
+func Test_canonicalize(t *testing.T) {
+	type args struct {
+		data  string
+		space string
+		local string
+		id    string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{"document", args{`<?xml version="1.0"?><a b="1"/>`, "", "", ""}, `<a b="1"></a>`, false},
+		{"notFound", args{`<a/>`, "", "b", ""}, "", true},
+		{"invalid", args{`<a>`, "", "", ""}, "", true},
+		{"attributes", args{`<doc xmlns="http://a" xmlns:b="http://b"><b:e attr2="2" b:x="x" attr1="1"   /></doc>`, "http://b", "e", ""},
+			`<b:e xmlns="http://a" xmlns:b="http://b" attr1="1" attr2="2" b:x="x"></b:e>`, false},
+		{"redundantNS", args{`<a xmlns="u"><b xmlns="u" xmlns:p="v"><p:c xmlns=""/></b></a>`, "", "", ""},
+			`<a xmlns="u"><b xmlns:p="v"><p:c xmlns=""></p:c></b></a>`, false},
+		{"emptyDefault", args{`<a><b xmlns=""/></a>`, "", "", ""}, `<a><b></b></a>`, false},
+		{"xmlAttrs", args{`<a xml:lang="en" xml:space="preserve"><b xml:lang="es"><c/></b></a>`, "", "b", ""},
+			`<b xml:lang="es" xml:space="preserve"><c></c></b>`, false},
+		{"escape", args{"<a v='\"&lt;&#x9;&#xA;&#xD;'>1 &lt; 2 &amp; \"q\" &gt; 0&#xD;<![CDATA[<x>]]></a>", "", "", ""},
+			"<a v=\"&quot;&lt;&#x9;&#xA;&#xD;\">1 &lt; 2 &amp; \"q\" &gt; 0&#xD;&lt;x&gt;</a>", false},
+		{"comments", args{`<r><o Id="x"><!--c--><?pi data?><p/></o><o Id="y"><?pi?></o></r>`, "", "o", "y"}, `<o Id="y"><?pi?></o>`, false},
+		{"id", args{`<r xmlns="n"><o Id="x"><!--c--><?pi data?><p/></o><o Id="y"/></r>`, "n", "o", "x"}, `<o xmlns="n" Id="x"><?pi data?><p></p></o>`, false},
+		{"otherNS", args{`<r xmlns="n"><o Id="x"/></r>`, "m", "o", "x"}, "", true},
+		{"whitespace", args{"<a>\n  <b />\n</a>", "", "", ""}, "<a>\n  <b></b>\n</a>", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := canonicalize([]byte(tt.args.data), tt.args.space, tt.args.local, tt.args.id)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("canonicalize() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if string(got) != tt.want {
+				t.Errorf("canonicalize() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
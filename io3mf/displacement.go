@@ -0,0 +1,503 @@
+package io3mf
+
+import (
+	"encoding/xml"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+type displacement2DDecoder struct {
+	emptyDecoder
+	resource go3mf.Displacement2DResource
+}
+
+func (d *displacement2DDecoder) Open() {
+	d.resource.ModelPath = d.file.path
+}
+
+func (d *displacement2DDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return d.file.parser.CloseResource()
+}
+
+func (d *displacement2DDecoder) Attributes(attrs []xml.Attr) bool {
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrID:
+			d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
+		case attrPath:
+			d.resource.Path = a.Value
+		case attrChannel:
+			d.resource.Channel, _ = newChannel(a.Value)
+		case attrTileStyleU:
+			d.resource.TileStyleU, _ = newTileStyle(a.Value)
+		case attrTileStyleV:
+			d.resource.TileStyleV, _ = newTileStyle(a.Value)
+		case attrFilter:
+			d.resource.Filter, _ = newTextureFilter(a.Value)
+		}
+		if !ok {
+			break
+		}
+	}
+	if d.resource.Path == "" {
+		return d.file.parser.MissingAttr(attrPath)
+	}
+	return ok
+}
+
+type normVectorGroupDecoder struct {
+	emptyDecoder
+	resource          go3mf.NormVectorGroupResource
+	normVectorDecoder normVectorDecoder
+}
+
+func (d *normVectorGroupDecoder) Open() {
+	d.resource.ModelPath = d.file.path
+	d.normVectorDecoder.resource = &d.resource
+}
+
+func (d *normVectorGroupDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return d.file.parser.CloseResource()
+}
+
+func (d *normVectorGroupDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsDisplacementSpec && name.Local == attrNormVector {
+		child = &d.normVectorDecoder
+	}
+	return
+}
+
+func (d *normVectorGroupDecoder) Attributes(attrs []xml.Attr) bool {
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrID {
+			d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
+			break
+		}
+	}
+	return ok
+}
+
+type normVectorDecoder struct {
+	emptyDecoder
+	resource *go3mf.NormVectorGroupResource
+}
+
+func (d *normVectorDecoder) Attributes(attrs []xml.Attr) bool {
+	var x, y, z float32
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrX:
+			x, ok = d.file.parser.ParseFloat32Required(attrX, a.Value)
+		case attrY:
+			y, ok = d.file.parser.ParseFloat32Required(attrY, a.Value)
+		case attrZ:
+			z, ok = d.file.parser.ParseFloat32Required(attrZ, a.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	d.resource.Vectors = append(d.resource.Vectors, geo.Point3D{x, y, z})
+	return ok
+}
+
+type disp2DGroupDecoder struct {
+	emptyDecoder
+	resource           go3mf.Disp2DGroupResource
+	disp2DCoordDecoder disp2DCoordDecoder
+}
+
+func (d *disp2DGroupDecoder) Open() {
+	d.resource.ModelPath = d.file.path
+	d.disp2DCoordDecoder.resource = &d.resource
+}
+
+func (d *disp2DGroupDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return d.file.parser.CloseResource()
+}
+
+func (d *disp2DGroupDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsDisplacementSpec && name.Local == attrDisp2DCoord {
+		child = &d.disp2DCoordDecoder
+	}
+	return
+}
+
+func (d *disp2DGroupDecoder) Attributes(attrs []xml.Attr) bool {
+	var hasDispID, hasNID, hasHeight bool
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrID:
+			d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
+		case attrDispID:
+			d.resource.DisplacementID, ok = d.file.parser.ParseUint32Required(attrDispID, a.Value)
+			hasDispID = true
+		case attrNID:
+			d.resource.NormVectorGroupID, ok = d.file.parser.ParseUint32Required(attrNID, a.Value)
+			hasNID = true
+		case attrHeight:
+			d.resource.Height, ok = d.file.parser.ParseFloat32Required(attrHeight, a.Value)
+			hasHeight = true
+		case attrOffset:
+			d.resource.Offset = d.file.parser.ParseFloat32Optional(attrOffset, a.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if !hasDispID {
+		ok = d.file.parser.MissingAttr(attrDispID)
+	}
+	if ok && !hasNID {
+		ok = d.file.parser.MissingAttr(attrNID)
+	}
+	if ok && !hasHeight {
+		ok = d.file.parser.MissingAttr(attrHeight)
+	}
+	return ok
+}
+
+type disp2DCoordDecoder struct {
+	emptyDecoder
+	resource *go3mf.Disp2DGroupResource
+}
+
+func (d *disp2DCoordDecoder) Attributes(attrs []xml.Attr) bool {
+	coord := go3mf.Disp2DCoord{F: 1}
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrU:
+			coord.U, ok = d.file.parser.ParseFloat32Required(attrU, a.Value)
+		case attrV:
+			coord.V, ok = d.file.parser.ParseFloat32Required(attrV, a.Value)
+		case attrN:
+			coord.N, ok = d.file.parser.ParseUint32Required(attrN, a.Value)
+		case attrF:
+			coord.F = d.file.parser.ParseFloat32Optional(attrF, a.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	d.resource.Coords = append(d.resource.Coords, coord)
+	return ok
+}
+
+type displacementMeshDecoder struct {
+	emptyDecoder
+	resource go3mf.DisplacementMeshResource
+}
+
+func (d *displacementMeshDecoder) Open() {
+	d.resource.Mesh = new(geo.Mesh)
+}
+
+func (d *displacementMeshDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return true
+}
+
+func (d *displacementMeshDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsDisplacementSpec {
+		if name.Local == attrVertices {
+			child = &dispVerticesDecoder{resource: &d.resource}
+		} else if name.Local == attrTriangles {
+			child = &dispTrianglesDecoder{resource: &d.resource}
+		}
+	}
+	return
+}
+
+type dispVerticesDecoder struct {
+	emptyDecoder
+	resource          *go3mf.DisplacementMeshResource
+	dispVertexDecoder dispVertexDecoder
+}
+
+func (d *dispVerticesDecoder) Open() {
+	d.dispVertexDecoder.resource = d.resource
+}
+
+func (d *dispVerticesDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsDisplacementSpec && name.Local == attrVertex {
+		child = &d.dispVertexDecoder
+	}
+	return
+}
+
+type dispVertexDecoder struct {
+	emptyDecoder
+	resource *go3mf.DisplacementMeshResource
+}
+
+func (d *dispVertexDecoder) Attributes(attrs []xml.Attr) bool {
+	var x, y, z float32
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrX:
+			x, ok = d.file.parser.ParseFloat32Required(attrX, a.Value)
+		case attrY:
+			y, ok = d.file.parser.ParseFloat32Required(attrY, a.Value)
+		case attrZ:
+			z, ok = d.file.parser.ParseFloat32Required(attrZ, a.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	d.resource.Mesh.Nodes = append(d.resource.Mesh.Nodes, geo.Point3D{x, y, z})
+	return true
+}
+
+type dispTrianglesDecoder struct {
+	emptyDecoder
+	resource            *go3mf.DisplacementMeshResource
+	dispTriangleDecoder dispTriangleDecoder
+}
+
+func (d *dispTrianglesDecoder) Open() {
+	d.dispTriangleDecoder.resource = d.resource
+	if nodes := len(d.resource.Mesh.Nodes); len(d.resource.Mesh.Faces) == 0 && nodes > 0 {
+		d.resource.Mesh.Faces = make([]geo.Face, 0, nodes-1)
+		d.resource.Displacements = make([]go3mf.DisplacementTriangle, 0, nodes-1)
+	}
+}
+
+func (d *dispTrianglesDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrDID {
+			d.resource.DefaultDisplacementID = d.file.parser.ParseUint32Optional(attrDID, a.Value)
+			break
+		}
+	}
+	return true
+}
+
+func (d *dispTrianglesDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsDisplacementSpec && name.Local == attrTriangle {
+		child = &d.dispTriangleDecoder
+	}
+	return
+}
+
+type dispTriangleDecoder struct {
+	emptyDecoder
+	resource *go3mf.DisplacementMeshResource
+}
+
+func (d *dispTriangleDecoder) Attributes(attrs []xml.Attr) bool {
+	var (
+		v1, v2, v3, pid, p1, p2, p3, did, d1, d2, d3      uint32
+		hasPID, hasP1, hasP2, hasP3, hasDID, hasD2, hasD3 bool
+	)
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrV1:
+			v1, ok = d.file.parser.ParseUint32Required(attrV1, a.Value)
+		case attrV2:
+			v2, ok = d.file.parser.ParseUint32Required(attrV2, a.Value)
+		case attrV3:
+			v3, ok = d.file.parser.ParseUint32Required(attrV3, a.Value)
+		case attrPID:
+			pid = d.file.parser.ParseUint32Optional(attrPID, a.Value)
+			hasPID = true
+		case attrP1:
+			p1 = d.file.parser.ParseUint32Optional(attrP1, a.Value)
+			hasP1 = true
+		case attrP2:
+			p2 = d.file.parser.ParseUint32Optional(attrP2, a.Value)
+			hasP2 = true
+		case attrP3:
+			p3 = d.file.parser.ParseUint32Optional(attrP3, a.Value)
+			hasP3 = true
+		case attrDID:
+			did = d.file.parser.ParseUint32Optional(attrDID, a.Value)
+			hasDID = true
+		case attrD1:
+			d1 = d.file.parser.ParseUint32Optional(attrD1, a.Value)
+		case attrD2:
+			d2 = d.file.parser.ParseUint32Optional(attrD2, a.Value)
+			hasD2 = true
+		case attrD3:
+			d3 = d.file.parser.ParseUint32Optional(attrD3, a.Value)
+			hasD3 = true
+		}
+		if !ok {
+			return false
+		}
+	}
+
+	p1 = applyDefault(p1, d.resource.DefaultPropertyIndex, hasP1)
+	p2 = applyDefault(p2, p1, hasP2)
+	p3 = applyDefault(p3, p1, hasP3)
+	pid = applyDefault(pid, d.resource.DefaultPropertyID, hasPID)
+	d2 = applyDefault(d2, d1, hasD2)
+	d3 = applyDefault(d3, d1, hasD3)
+	did = applyDefault(did, d.resource.DefaultDisplacementID, hasDID)
+
+	if v1 == v2 || v1 == v3 || v2 == v3 {
+		return d.file.parser.GenericError(true, "duplicated triangle indices")
+	}
+	nodeCount := uint32(len(d.resource.Mesh.Nodes))
+	if v1 >= nodeCount || v2 >= nodeCount || v3 >= nodeCount {
+		return d.file.parser.GenericError(true, "triangle indices are out of range")
+	}
+	d.resource.Mesh.Faces = append(d.resource.Mesh.Faces, geo.Face{
+		NodeIndices:     [3]uint32{v1, v2, v3},
+		Resource:        pid,
+		ResourceIndices: [3]uint32{p1, p2, p3},
+	})
+	d.resource.Displacements = append(d.resource.Displacements, go3mf.DisplacementTriangle{
+		DisplacementID: did,
+		Indices:        [3]uint32{d1, d2, d3},
+	})
+	return true
+}
+
+func (w *modelWriter) writeDisplacement2D(r *go3mf.Displacement2DResource) {
+	w.elementNS(nsDisplacementSpec, attrDisplacement2D,
+		w.attr(attrID, formatUint32(r.ID)),
+		w.attr(attrPath, r.Path),
+		w.attr(attrChannel, r.Channel.String()),
+		w.attr(attrTileStyleU, r.TileStyleU.String()),
+		w.attr(attrTileStyleV, r.TileStyleV.String()),
+		w.attr(attrFilter, r.Filter.String()),
+	)
+}
+
+func (w *modelWriter) writeNormVectorGroup(r *go3mf.NormVectorGroupResource) {
+	w.startNS(nsDisplacementSpec, attrNormVectorGroup, w.attr(attrID, formatUint32(r.ID)))
+	for _, n := range r.Vectors {
+		w.elementNS(nsDisplacementSpec, attrNormVector,
+			w.attr(attrX, formatFloat32(n.X())),
+			w.attr(attrY, formatFloat32(n.Y())),
+			w.attr(attrZ, formatFloat32(n.Z())),
+		)
+	}
+	w.endNS(nsDisplacementSpec, attrNormVectorGroup)
+}
+
+func (w *modelWriter) writeDisp2DGroup(r *go3mf.Disp2DGroupResource) {
+	attrs := []xml.Attr{
+		w.attr(attrID, formatUint32(r.ID)),
+		w.attr(attrDispID, formatUint32(r.DisplacementID)),
+		w.attr(attrNID, formatUint32(r.NormVectorGroupID)),
+		w.attr(attrHeight, formatFloat32(r.Height)),
+	}
+	if r.Offset != 0 {
+		attrs = append(attrs, w.attr(attrOffset, formatFloat32(r.Offset)))
+	}
+	w.startNS(nsDisplacementSpec, attrDisp2DGroup, attrs...)
+	for _, c := range r.Coords {
+		attrs := []xml.Attr{
+			w.attr(attrU, formatFloat32(c.U)),
+			w.attr(attrV, formatFloat32(c.V)),
+			w.attr(attrN, formatUint32(c.N)),
+		}
+		if c.F != 1 {
+			attrs = append(attrs, w.attr(attrF, formatFloat32(c.F)))
+		}
+		w.elementNS(nsDisplacementSpec, attrDisp2DCoord, attrs...)
+	}
+	w.endNS(nsDisplacementSpec, attrDisp2DGroup)
+}
+
+func (w *modelWriter) writeDisplacementMeshObject(r *go3mf.DisplacementMeshResource) {
+	w.start(attrObject, w.objectAttrs(&r.ObjectResource)...)
+	w.writeMetadataGroup(r.Metadata)
+	w.startNS(nsDisplacementSpec, attrDisplacementMesh)
+	if r.Mesh != nil {
+		w.startNS(nsDisplacementSpec, attrVertices)
+		for _, n := range r.Mesh.Nodes {
+			w.elementNS(nsDisplacementSpec, attrVertex,
+				w.attr(attrX, formatFloat32(n.X())),
+				w.attr(attrY, formatFloat32(n.Y())),
+				w.attr(attrZ, formatFloat32(n.Z())),
+			)
+		}
+		w.endNS(nsDisplacementSpec, attrVertices)
+		var attrs []xml.Attr
+		if r.DefaultDisplacementID != 0 {
+			attrs = append(attrs, w.attr(attrDID, formatUint32(r.DefaultDisplacementID)))
+		}
+		w.startNS(nsDisplacementSpec, attrTriangles, attrs...)
+		for i, f := range r.Mesh.Faces {
+			var disp go3mf.DisplacementTriangle
+			if i < len(r.Displacements) {
+				disp = r.Displacements[i]
+			}
+			w.writeDispTriangle(r, f, disp)
+		}
+		w.endNS(nsDisplacementSpec, attrTriangles)
+	}
+	w.endNS(nsDisplacementSpec, attrDisplacementMesh)
+	w.writeAlternatives(r.Alternatives)
+	w.writeUnknownTokens(w.extensions[&r.ObjectResource])
+	w.writeUnknownTokens(r.Unknown)
+	w.end(attrObject)
+}
+
+func (w *modelWriter) writeDispTriangle(r *go3mf.DisplacementMeshResource, f geo.Face, disp go3mf.DisplacementTriangle) {
+	attrs := []xml.Attr{
+		w.attr(attrV1, formatUint32(f.NodeIndices[0])),
+		w.attr(attrV2, formatUint32(f.NodeIndices[1])),
+		w.attr(attrV3, formatUint32(f.NodeIndices[2])),
+	}
+	// Only write the properties that differ from the defaults applied by the decoder.
+	if f.Resource != r.DefaultPropertyID {
+		attrs = append(attrs, w.attr(attrPID, formatUint32(f.Resource)))
+	}
+	p1, p2, p3 := f.ResourceIndices[0], f.ResourceIndices[1], f.ResourceIndices[2]
+	if p1 != r.DefaultPropertyIndex {
+		attrs = append(attrs, w.attr(attrP1, formatUint32(p1)))
+	}
+	if p2 != p1 {
+		attrs = append(attrs, w.attr(attrP2, formatUint32(p2)))
+	}
+	if p3 != p1 {
+		attrs = append(attrs, w.attr(attrP3, formatUint32(p3)))
+	}
+	if disp.DisplacementID != r.DefaultDisplacementID {
+		attrs = append(attrs, w.attr(attrDID, formatUint32(disp.DisplacementID)))
+	}
+	d1, d2, d3 := disp.Indices[0], disp.Indices[1], disp.Indices[2]
+	if d1 != 0 {
+		attrs = append(attrs, w.attr(attrD1, formatUint32(d1)))
+	}
+	if d2 != d1 {
+		attrs = append(attrs, w.attr(attrD2, formatUint32(d2)))
+	}
+	if d3 != d1 {
+		attrs = append(attrs, w.attr(attrD3, formatUint32(d3)))
+	}
+	w.elementNS(nsDisplacementSpec, attrTriangle, attrs...)
+}
@@ -0,0 +1,261 @@
+package io3mf
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	go3mf "github.com/qmuntal/go3mf"
+)
+
+// An Extension adds the support of a 3MF extension namespace to the Decoder,
+// so third party namespaces can be decoded without modifying this package.
+// A registered extension is accepted in the requiredextensions attribute of the model
+// and is also handed the elements and attributes of its namespace
+// when it implements ResourceDecoder or ObjectDecoder.
+// The Encoder writes back the data decoded by an extension when it implements
+// ResourceEncoder or ObjectEncoder, and fails otherwise.
+// The extension namespace is only listed as required when it implements RequiredExtension.
+type Extension interface {
+	// Namespace returns the namespace URI of the extension.
+	Namespace() string
+}
+
+// A ResourceDecoder is an Extension that decodes its own resources.
+type ResourceDecoder interface {
+	Extension
+	// DecodeResource decodes the extension element start found inside the resources element.
+	// x returns all the tokens of the element, from start up to and including its end element,
+	// so xml.NewTokenDecoder(x).Decode can be used to unmarshal it.
+	// The returned resource, if not nil, is added to the model and can be referenced by its ID.
+	DecodeResource(x xml.TokenReader, start xml.StartElement, modelPath string) (go3mf.Resource, error)
+}
+
+// An ObjectDecoder is an Extension that decodes per-object data,
+// which is usually stored in the object Extensions under the extension namespace.
+type ObjectDecoder interface {
+	Extension
+	// DecodeObjectAttr decodes an object attribute that belongs to the extension namespace.
+	DecodeObjectAttr(o *go3mf.ObjectResource, attr xml.Attr) error
+	// DecodeObjectElement decodes a child element of an object that belongs to the extension namespace.
+	// x behaves as in ResourceDecoder.DecodeResource.
+	DecodeObjectElement(o *go3mf.ObjectResource, x xml.TokenReader, start xml.StartElement) error
+}
+
+// A ResourceEncoder is an Extension that encodes its own resources.
+type ResourceEncoder interface {
+	Extension
+	// EncodeResource returns the tokens of the element that represents r, from its start element
+	// up to and including its end element, or false if r does not belong to the extension.
+	// The token names use namespace URIs, not prefixes, as the ones given to DecodeResource.
+	EncodeResource(r go3mf.Resource) (tokens []xml.Token, ok bool, err error)
+}
+
+// An ObjectEncoder is an Extension that encodes per-object data.
+type ObjectEncoder interface {
+	Extension
+	// EncodeObject returns the object attributes and the object child elements
+	// that represent the data stored in the object Extensions under the extension namespace.
+	// The names use namespace URIs, as in ResourceEncoder.EncodeResource.
+	EncodeObject(o *go3mf.ObjectResource) (attrs []xml.Attr, tokens []xml.Token, err error)
+}
+
+// A RequiredExtension is an Extension that can be required by the models that use it.
+type RequiredExtension interface {
+	Extension
+	// Required returns true if the Encoder has to list the extension namespace
+	// in the requiredextensions attribute of the models with data encoded by the extension.
+	Required() bool
+}
+
+// nativeNamespaces are the namespaces supported by this package.
+var nativeNamespaces = []string{nsCoreSpec, nsMaterialSpec, nsProductionSpec, nsAlternativesSpec, nsBeamLatticeSpec, nsBallsSpec, nsSliceSpec, nsDisplacementSpec, nsTriangleSetsSpec, nsBooleanSpec}
+
+var (
+	extensionsMu sync.RWMutex
+	extensions   = make(map[string]Extension)
+)
+
+// RegisterExtension makes an extension available to all the Decoders.
+// It panics if ext is nil, if its namespace is one of the namespaces supported natively
+// or if an extension with the same namespace is already registered.
+func RegisterExtension(ext Extension) {
+	if ext == nil {
+		panic("go3mf: register extension is nil")
+	}
+	ns := ext.Namespace()
+	for _, native := range nativeNamespaces {
+		if ns == native {
+			panic(fmt.Sprintf("go3mf: register extension with native namespace '%s'", ns))
+		}
+	}
+	extensionsMu.Lock()
+	defer extensionsMu.Unlock()
+	if _, dup := extensions[ns]; dup {
+		panic(fmt.Sprintf("go3mf: register extension called twice for namespace '%s'", ns))
+	}
+	extensions[ns] = ext
+}
+
+func registeredExtension(ns string) (ext Extension, ok bool) {
+	extensionsMu.RLock()
+	ext, ok = extensions[ns]
+	extensionsMu.RUnlock()
+	return
+}
+
+// extensionSupported returns true if ns is a native namespace or it has a registered extension.
+func extensionSupported(ns string) bool {
+	for _, native := range nativeNamespaces {
+		if ns == native {
+			return true
+		}
+	}
+	_, ok := registeredExtension(ns)
+	return ok
+}
+
+func resourceExtension(ns string) (ResourceDecoder, bool) {
+	ext, ok := registeredExtension(ns)
+	if !ok {
+		return nil, false
+	}
+	rd, ok := ext.(ResourceDecoder)
+	return rd, ok
+}
+
+// encodeExtensionResource encodes r with the first registered ResourceEncoder that accepts it
+// and returns the namespace of that extension.
+func encodeExtensionResource(r go3mf.Resource) (string, []xml.Token, error) {
+	extensionsMu.RLock()
+	namespaces := make([]string, 0, len(extensions))
+	for ns := range extensions {
+		namespaces = append(namespaces, ns)
+	}
+	extensionsMu.RUnlock()
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		ext, _ := registeredExtension(ns)
+		if re, ok := ext.(ResourceEncoder); ok {
+			tokens, ok, err := re.EncodeResource(r)
+			if err != nil || ok {
+				return ns, tokens, err
+			}
+		}
+	}
+	return "", nil, fmt.Errorf("go3mf: cannot encode resource of type %T", r)
+}
+
+// encodeExtensionObject encodes the extension data of o with the registered ObjectEncoders.
+func encodeExtensionObject(o *go3mf.ObjectResource) (go3mf.UnknownTokens, error) {
+	var u go3mf.UnknownTokens
+	namespaces := make([]string, 0, len(o.Extensions))
+	for ns := range o.Extensions {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		ext, _ := registeredExtension(ns)
+		oe, ok := ext.(ObjectEncoder)
+		if !ok {
+			return u, fmt.Errorf("go3mf: cannot encode object extension data of namespace '%s'", ns)
+		}
+		attrs, tokens, err := oe.EncodeObject(o)
+		if err != nil {
+			return u, err
+		}
+		u.Attr = append(u.Attr, attrs...)
+		u.Tokens = append(u.Tokens, tokens...)
+	}
+	return u, nil
+}
+
+// requiredExtension returns true if ns belongs to a registered RequiredExtension that is required.
+func requiredExtension(ns string) bool {
+	ext, _ := registeredExtension(ns)
+	re, ok := ext.(RequiredExtension)
+	return ok && re.Required()
+}
+
+func objectExtension(ns string) (ObjectDecoder, bool) {
+	ext, ok := registeredExtension(ns)
+	if !ok {
+		return nil, false
+	}
+	od, ok := ext.(ObjectDecoder)
+	return od, ok
+}
+
+// tokenRecorder records the tokens of an element and all its children.
+// The top level recorder calls done with the recorded tokens once the element is closed.
+type tokenRecorder struct {
+	emptyDecoder
+	name   xml.Name
+	tokens *[]xml.Token
+	done   func(start xml.StartElement, x xml.TokenReader) bool
+}
+
+func newTokenRecorder(name xml.Name, done func(xml.StartElement, xml.TokenReader) bool) *tokenRecorder {
+	return &tokenRecorder{name: name, tokens: new([]xml.Token), done: done}
+}
+
+func (d *tokenRecorder) Attributes(attrs []xml.Attr) bool {
+	*d.tokens = append(*d.tokens, xml.StartElement{Name: d.name, Attr: attrs})
+	return true
+}
+
+func (d *tokenRecorder) Text(txt []byte) bool {
+	*d.tokens = append(*d.tokens, xml.CharData(txt).Copy())
+	return true
+}
+
+func (d *tokenRecorder) Child(name xml.Name) nodeDecoder {
+	return &tokenRecorder{name: name, tokens: d.tokens}
+}
+
+func (d *tokenRecorder) Close() bool {
+	*d.tokens = append(*d.tokens, xml.EndElement{Name: d.name})
+	if d.done == nil {
+		return true
+	}
+	tokens := *d.tokens
+	return d.done(tokens[0].(xml.StartElement), &tokenReader{tokens: tokens})
+}
+
+// tokenReader implements xml.TokenReader over a recorded slice of tokens.
+type tokenReader struct {
+	tokens []xml.Token
+}
+
+func (r *tokenReader) Token() (xml.Token, error) {
+	if len(r.tokens) == 0 {
+		return nil, io.EOF
+	}
+	t := r.tokens[0]
+	r.tokens = r.tokens[1:]
+	return t, nil
+}
+
+func (d *resourceDecoder) extensionResource(rd ResourceDecoder, name xml.Name) nodeDecoder {
+	return newTokenRecorder(name, func(start xml.StartElement, x xml.TokenReader) bool {
+		r, err := rd.DecodeResource(x, start, d.file.path)
+		if err != nil {
+			return d.file.parser.GenericError(true, err.Error())
+		}
+		if r != nil {
+			d.file.AddResource(r)
+		}
+		return true
+	})
+}
+
+func (d *objectDecoder) extensionElement(od ObjectDecoder, name xml.Name) nodeDecoder {
+	return newTokenRecorder(name, func(start xml.StartElement, x xml.TokenReader) bool {
+		if err := od.DecodeObjectElement(d.object, x, start); err != nil {
+			return d.file.parser.GenericError(true, err.Error())
+		}
+		return true
+	})
+}
@@ -0,0 +1,281 @@
+package io3mf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	go3mf "github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+const nsVendorSpec = "http://www.vendor.com/3mf/printparams/2020"
+
+type printProfile struct {
+	ID        uint32
+	ModelPath string
+	Name      string  `xml:"name,attr"`
+	Speed     float32 `xml:"speed"`
+}
+
+func (p *printProfile) Identify() (string, uint32) {
+	return p.ModelPath, p.ID
+}
+
+type objectParams struct {
+	Profile uint32
+	Infill  string
+}
+
+type vendorExtension struct{}
+
+func (vendorExtension) Namespace() string {
+	return nsVendorSpec
+}
+
+func (vendorExtension) DecodeResource(x xml.TokenReader, start xml.StartElement, modelPath string) (go3mf.Resource, error) {
+	if start.Name.Local != "profile" {
+		return nil, nil
+	}
+	p := &printProfile{ModelPath: modelPath, ID: 30}
+	if err := xml.NewTokenDecoder(x).Decode(p); err != nil {
+		return nil, err
+	}
+	if p.Name == "" {
+		return nil, errors.New("missing profile name")
+	}
+	return p, nil
+}
+
+func (vendorExtension) DecodeObjectAttr(o *go3mf.ObjectResource, attr xml.Attr) error {
+	if attr.Name.Local != "profile" {
+		return errors.New("unknown attribute")
+	}
+	params(o).Profile = 30
+	return nil
+}
+
+func (vendorExtension) DecodeObjectElement(o *go3mf.ObjectResource, x xml.TokenReader, start xml.StartElement) error {
+	var infill struct {
+		Value string `xml:",chardata"`
+	}
+	err := xml.NewTokenDecoder(x).Decode(&infill)
+	params(o).Infill = infill.Value
+	return err
+}
+
+func (vendorExtension) EncodeResource(r go3mf.Resource) ([]xml.Token, bool, error) {
+	p, ok := r.(*printProfile)
+	if !ok {
+		return nil, false, nil
+	}
+	profile, speed := xml.Name{Space: nsVendorSpec, Local: "profile"}, xml.Name{Space: nsVendorSpec, Local: "speed"}
+	return []xml.Token{
+		xml.StartElement{Name: profile, Attr: []xml.Attr{{Name: xml.Name{Local: "name"}, Value: p.Name}}},
+		xml.StartElement{Name: speed}, xml.CharData(formatFloat32(p.Speed)), xml.EndElement{Name: speed},
+		xml.EndElement{Name: profile},
+	}, true, nil
+}
+
+func (vendorExtension) EncodeObject(o *go3mf.ObjectResource) ([]xml.Attr, []xml.Token, error) {
+	p := params(o)
+	var (
+		attrs  []xml.Attr
+		tokens []xml.Token
+	)
+	if p.Profile != 0 {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Space: nsVendorSpec, Local: "profile"}, Value: formatUint32(p.Profile)})
+	}
+	if p.Infill != "" {
+		infill := xml.Name{Space: nsVendorSpec, Local: "infill"}
+		tokens = append(tokens, xml.StartElement{Name: infill}, xml.CharData(p.Infill), xml.EndElement{Name: infill})
+	}
+	return attrs, tokens, nil
+}
+
+func (vendorExtension) Required() bool {
+	return true
+}
+
+func params(o *go3mf.ObjectResource) *objectParams {
+	if o.Extensions == nil {
+		o.Extensions = make(go3mf.ExtensionData)
+	}
+	p, ok := o.Extensions[nsVendorSpec].(*objectParams)
+	if !ok {
+		p = new(objectParams)
+		o.Extensions[nsVendorSpec] = p
+	}
+	return p
+}
+
+// registerVendorExtension registers the vendor extension and returns a function that unregisters it.
+func registerVendorExtension() func() {
+	RegisterExtension(vendorExtension{})
+	return func() {
+		extensionsMu.Lock()
+		delete(extensions, nsVendorSpec)
+		extensionsMu.Unlock()
+	}
+}
+
+func TestRegisterExtension_Panics(t *testing.T) {
+	defer registerVendorExtension()()
+	tests := []struct {
+		name string
+		ext  Extension
+	}{
+		{"nil", nil},
+		{"native", nativeExtension{}},
+		{"duplicated", vendorExtension{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); r == nil {
+					t.Error("RegisterExtension() expected panic")
+				}
+			}()
+			RegisterExtension(tt.ext)
+		})
+	}
+}
+
+type nativeExtension struct{}
+
+func (nativeExtension) Namespace() string {
+	return nsMaterialSpec
+}
+
+func TestDecoder_processRootModel_Extension(t *testing.T) {
+	rootModel := new(modelBuilder).withElement(`<model xmlns="` + nsCoreSpec + `" xmlns:v="` + nsVendorSpec + `" requiredextensions="v">
+		<resources>
+			<v:profile name="fast"><v:speed>80</v:speed></v:profile>
+			<v:unknown />
+			<object id="1" type="model" v:profile="30">
+				<v:infill>gyroid</v:infill>
+				<mesh>
+					<vertices>
+						<vertex x="0" y="0" z="0" /> <vertex x="1" y="0" z="0" /> <vertex x="0" y="1" z="0" />
+					</vertices>
+					<triangles>
+						<triangle v1="0" v2="1" v3="2" />
+					</triangles>
+				</mesh>
+				<v:infill>lines</v:infill>
+			</object>
+		</resources>
+		<build />
+	</model>`)
+
+	profile := &printProfile{ID: 30, ModelPath: "/3d/3dmodel.model", Name: "fast", Speed: 80}
+	mesh := &go3mf.MeshResource{
+		ObjectResource: go3mf.ObjectResource{ID: 1, ModelPath: "/3d/3dmodel.model", Extensions: go3mf.ExtensionData{
+			nsVendorSpec: &objectParams{Profile: 30, Infill: "lines"},
+		}},
+		Mesh: new(geo.Mesh),
+	}
+	mesh.Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	mesh.Mesh.Faces = []geo.Face{{NodeIndices: [3]uint32{0, 1, 2}}}
+	want := &go3mf.Model{Path: "/3d/3dmodel.model", Resources: []go3mf.Resource{profile, mesh}}
+
+	t.Run("unregistered", func(t *testing.T) {
+		d := &Decoder{Strict: true}
+		err := d.processRootModel(context.Background(), rootModel.build(), &go3mf.Model{Path: "/3d/3dmodel.model"})
+		if err == nil {
+			t.Error("Decoder.processRootModel() expected unsupported extension error")
+		}
+	})
+	t.Run("registered", func(t *testing.T) {
+		defer registerVendorExtension()()
+		got := &go3mf.Model{Path: "/3d/3dmodel.model"}
+		d := &Decoder{Strict: true}
+		if err := d.processRootModel(context.Background(), rootModel.build(), got); err != nil {
+			t.Errorf("Decoder.processRootModel() unexpected error = %v", err)
+			return
+		}
+		deep.CompareUnexportedFields = true
+		deep.MaxDepth = 20
+		if diff := deep.Equal(got, want); diff != nil {
+			t.Errorf("Decoder.processRootModel() = %v", diff)
+		}
+	})
+}
+
+func TestDecoder_processRootModel_ExtensionWarns(t *testing.T) {
+	defer registerVendorExtension()()
+	rootFile := new(modelBuilder).withElement(`<model xmlns="` + nsCoreSpec + `" xmlns:v="` + nsVendorSpec + `">
+		<resources>
+			<v:profile />
+			<object id="1" type="model" v:other="1">
+				<v:infill>gyroid</v:infill>
+			</object>
+		</resources>
+	</model>`).build()
+	want := []error{
+		GenericError{ResourceID: 0, Element: "profile", ModelPath: "/3d/3dmodel.model", Message: "missing profile name"},
+		GenericError{ResourceID: 1, Element: "object", ModelPath: "/3d/3dmodel.model", Message: "unknown attribute"},
+	}
+	d := new(Decoder)
+	d.Strict = false
+	if err := d.processRootModel(context.Background(), rootFile, &go3mf.Model{Path: "/3d/3dmodel.model"}); err != nil {
+		t.Errorf("Decoder.processRootModel() unexpected error = %v", err)
+	}
+	if diff := deep.Equal(d.Warnings, want); diff != nil {
+		t.Errorf("Decoder.processRootModel() = %v", diff)
+	}
+}
+
+func TestEncoder_roundTrip_Extension(t *testing.T) {
+	profile := &printProfile{ID: 30, ModelPath: "/3d/3dmodel.model", Name: "fast", Speed: 80.5}
+	mesh := &go3mf.MeshResource{
+		ObjectResource: go3mf.ObjectResource{ID: 1, ModelPath: "/3d/3dmodel.model", Extensions: go3mf.ExtensionData{
+			nsVendorSpec: &objectParams{Profile: 30, Infill: "gyroid"},
+		}},
+		Mesh: new(geo.Mesh),
+	}
+	mesh.Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	mesh.Mesh.Faces = []geo.Face{{NodeIndices: [3]uint32{0, 1, 2}}}
+	want := &go3mf.Model{Path: "/3d/3dmodel.model", Resources: []go3mf.Resource{profile, mesh}}
+
+	t.Run("unregistered", func(t *testing.T) {
+		for _, model := range []*go3mf.Model{
+			{Path: "/3d/3dmodel.model", Resources: []go3mf.Resource{profile}},
+			{Path: "/3d/3dmodel.model", Resources: []go3mf.Resource{mesh}},
+		} {
+			mw := modelWriter{model: model, path: model.Path, isRoot: true}
+			if err := mw.Encode(context.Background(), new(bytes.Buffer)); err == nil {
+				t.Error("modelWriter.Encode() expected error")
+			}
+		}
+	})
+	t.Run("registered", func(t *testing.T) {
+		defer registerVendorExtension()()
+		buff := new(bytes.Buffer)
+		mw := modelWriter{model: want, path: want.Path, isRoot: true}
+		if err := mw.Encode(context.Background(), buff); err != nil {
+			t.Fatalf("modelWriter.Encode() unexpected error = %v", err)
+		}
+		if s := buff.String(); !strings.Contains(s, `xmlns:ns0="`+nsVendorSpec+`"`) || !strings.Contains(s, `requiredextensions="ns0"`) {
+			t.Errorf("modelWriter.Encode() = %s, want the vendor namespace required", s)
+		}
+		f := new(mockFile)
+		f.On("Name").Return(want.Path).Maybe()
+		f.On("Open").Return(ioutil.NopCloser(buff), nil).Maybe()
+		got := &go3mf.Model{Path: "/3d/3dmodel.model"}
+		d := &Decoder{Strict: true}
+		if err := d.processRootModel(context.Background(), f, got); err != nil {
+			t.Fatalf("Decoder.processRootModel() unexpected error = %v", err)
+		}
+		deep.CompareUnexportedFields = true
+		deep.MaxDepth = 20
+		if diff := deep.Equal(got, want); diff != nil {
+			t.Errorf("Encoder.Encode() = %v", diff)
+		}
+	})
+}
@@ -0,0 +1,430 @@
+package gltf
+
+import (
+	"encoding/binary"
+	"errors"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+// primitiveKind is the type of property shared by the faces of a primitive.
+type primitiveKind uint8
+
+const (
+	kindNone primitiveKind = iota
+	kindBase
+	kindColor
+	kindTexture
+)
+
+// primitiveKey groups the faces that are written in the same primitive.
+type primitiveKey struct {
+	kind  primitiveKind
+	res   go3mf.Resource
+	index uint32
+}
+
+// materialKey identifies a glTF material by the 3MF resource it comes from,
+// a nil res is the material used by the vertex colors.
+type materialKey struct {
+	res   go3mf.Resource
+	index uint32
+}
+
+type builder struct {
+	model     *go3mf.Model
+	doc       document
+	bin       []byte
+	meshes    map[go3mf.Object]int
+	materials map[materialKey]int
+	textures  map[*go3mf.Texture2DResource]int
+	visiting  map[go3mf.Object]bool
+}
+
+func newBuilder(model *go3mf.Model) *builder {
+	return &builder{
+		model:     model,
+		doc:       document{Asset: asset{Version: "2.0", Generator: "go3mf"}, Scenes: []scene{{Nodes: []int{0}}}},
+		meshes:    make(map[go3mf.Object]int),
+		materials: make(map[materialKey]int),
+		textures:  make(map[*go3mf.Texture2DResource]int),
+		visiting:  make(map[go3mf.Object]bool),
+	}
+}
+
+// unitScale is the size of each unit in meters, which is the glTF unit.
+var unitScale = map[go3mf.Units]float32{
+	go3mf.UnitMicrometer: 0.000001,
+	go3mf.UnitMillimeter: 0.001,
+	go3mf.UnitCentimeter: 0.01,
+	go3mf.UnitInch:       0.0254,
+	go3mf.UnitFoot:       0.3048,
+	go3mf.UnitMeter:      1,
+}
+
+// build adds a root node that scales the model to meters and rotates it from the 3MF +Z up axis
+// to the glTF +Y up axis, with one child node for each build item.
+func (b *builder) build() error {
+	s := unitScale[b.model.Units]
+	if s == 0 {
+		s = unitScale[go3mf.UnitMillimeter]
+	}
+	b.doc.Nodes = append(b.doc.Nodes, node{Matrix: &[16]float32{s, 0, 0, 0, 0, 0, -s, 0, 0, s, 0, 0, 0, 0, 0, 1}})
+	for _, item := range b.model.BuildItems {
+		child, err := b.objectNode(item.Object, transform(item.HasTransform(), item.Transform))
+		if err != nil {
+			return err
+		}
+		b.doc.Nodes[0].Children = append(b.doc.Nodes[0].Children, child)
+	}
+	if len(b.bin) > 0 {
+		b.doc.Buffers = []buffer{{ByteLength: len(b.bin)}}
+	}
+	return nil
+}
+
+// transform converts a 3MF transform to a glTF matrix.
+// Both share the same memory layout, as 3MF transforms row vectors in row major order
+// and glTF transforms column vectors in column major order.
+func transform(hasTransform bool, t geo.Matrix) *[16]float32 {
+	if !hasTransform {
+		return nil
+	}
+	m := [16]float32(t)
+	return &m
+}
+
+// objectNode adds a node for the object, and for its components, and returns its index.
+func (b *builder) objectNode(o go3mf.Object, matrix *[16]float32) (int, error) {
+	index := len(b.doc.Nodes)
+	b.doc.Nodes = append(b.doc.Nodes, node{Matrix: matrix})
+	switch o := o.(type) {
+	case *go3mf.MeshResource:
+		b.doc.Nodes[index].Name = o.Name
+		b.meshNode(index, o, &o.ObjectResource, o.Mesh)
+	case *go3mf.DisplacementMeshResource:
+		b.doc.Nodes[index].Name = o.Name
+		b.meshNode(index, o, &o.ObjectResource, o.Mesh)
+	case *go3mf.ComponentsResource:
+		b.doc.Nodes[index].Name = o.Name
+		if b.visiting[o] {
+			return 0, errors.New("go3mf: gltf components have a cyclic reference")
+		}
+		b.visiting[o] = true
+		defer delete(b.visiting, o)
+		for _, c := range o.Components {
+			child, err := b.objectNode(c.Object, transform(c.HasTransform(), c.Transform))
+			if err != nil {
+				return 0, err
+			}
+			b.doc.Nodes[index].Children = append(b.doc.Nodes[index].Children, child)
+		}
+	}
+	return index, nil
+}
+
+// meshNode sets the mesh of the node, meshes are converted once and shared by all their nodes.
+func (b *builder) meshNode(index int, o go3mf.Object, r *go3mf.ObjectResource, m *geo.Mesh) {
+	if m == nil || len(m.Faces) == 0 {
+		return
+	}
+	meshIndex, ok := b.meshes[o]
+	if !ok {
+		meshIndex = len(b.doc.Meshes)
+		b.doc.Meshes = append(b.doc.Meshes, mesh{Name: r.Name, Primitives: b.primitives(r, m)})
+		b.meshes[o] = meshIndex
+	}
+	b.doc.Nodes[index].Mesh = &meshIndex
+}
+
+// faceProperty resolves the property of a face, applying the object defaults if it has none.
+func (b *builder) faceProperty(r *go3mf.ObjectResource, f *geo.Face) (primitiveKey, [3]uint32) {
+	pid, indices := f.Resource, f.ResourceIndices
+	if pid == 0 {
+		pid = r.DefaultPropertyID
+		indices = [3]uint32{r.DefaultPropertyIndex, r.DefaultPropertyIndex, r.DefaultPropertyIndex}
+	}
+	if pid == 0 {
+		return primitiveKey{}, indices
+	}
+	res, ok := b.model.FindResource(r.ModelPath, pid)
+	if !ok {
+		return primitiveKey{}, indices
+	}
+	switch res := res.(type) {
+	case *go3mf.BaseMaterialsResource:
+		if int(indices[0]) < len(res.Materials) {
+			return primitiveKey{kind: kindBase, res: res, index: indices[0]}, indices
+		}
+	case *go3mf.ColorGroupResource:
+		if inRange(indices, len(res.Colors)) {
+			return primitiveKey{kind: kindColor, res: res}, indices
+		}
+	case *go3mf.Texture2DGroupResource:
+		if inRange(indices, len(res.Coords)) {
+			return primitiveKey{kind: kindTexture, res: res}, indices
+		}
+	}
+	return primitiveKey{}, indices
+}
+
+func inRange(indices [3]uint32, n int) bool {
+	return int(indices[0]) < n && int(indices[1]) < n && int(indices[2]) < n
+}
+
+// primitiveData holds the vertices of a primitive, which are split by property index.
+type primitiveData struct {
+	key      primitiveKey
+	vertices map[[2]uint32]uint32
+	nodes    [][2]uint32
+	indices  []uint32
+}
+
+func (p *primitiveData) vertex(node, property uint32) uint32 {
+	v := [2]uint32{node, property}
+	index, ok := p.vertices[v]
+	if !ok {
+		index = uint32(len(p.nodes))
+		p.nodes = append(p.nodes, v)
+		p.vertices[v] = index
+	}
+	return index
+}
+
+// primitives groups the faces by property in primitives.
+func (b *builder) primitives(r *go3mf.ObjectResource, m *geo.Mesh) []primitive {
+	var groups []*primitiveData
+	byKey := make(map[primitiveKey]*primitiveData)
+	for i := range m.Faces {
+		f := &m.Faces[i]
+		key, properties := b.faceProperty(r, f)
+		if key.kind != kindColor && key.kind != kindTexture {
+			properties = [3]uint32{}
+		}
+		p, ok := byKey[key]
+		if !ok {
+			p = &primitiveData{key: key, vertices: make(map[[2]uint32]uint32)}
+			byKey[key] = p
+			groups = append(groups, p)
+		}
+		for j, n := range f.NodeIndices {
+			p.indices = append(p.indices, p.vertex(n, properties[j]))
+		}
+	}
+	primitives := make([]primitive, len(groups))
+	for i, p := range groups {
+		primitives[i] = b.primitive(m, p)
+	}
+	return primitives
+}
+
+func (b *builder) primitive(m *geo.Mesh, p *primitiveData) primitive {
+	positions := make([]float32, 0, 3*len(p.nodes))
+	min, max := []float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}, []float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+	for _, v := range p.nodes {
+		n := m.Nodes[v[0]]
+		for j := 0; j < 3; j++ {
+			positions = append(positions, n[j])
+			min[j], max[j] = float32(math.Min(float64(min[j]), float64(n[j]))), float32(math.Max(float64(max[j]), float64(n[j])))
+		}
+	}
+	prim := primitive{
+		Attributes: map[string]int{"POSITION": b.addAccessor(float32Bytes(positions), componentFloat32, len(p.nodes), "VEC3", targetArrayBuffer, min, max)},
+		Indices:    b.addAccessor(uint32Bytes(p.indices), componentUint32, len(p.indices), "SCALAR", targetElementArrayBuffer, nil, nil),
+	}
+	var mat int
+	switch p.key.kind {
+	case kindBase:
+		mat = b.baseMaterial(p.key.res.(*go3mf.BaseMaterialsResource), p.key.index)
+	case kindColor:
+		colors := p.key.res.(*go3mf.ColorGroupResource).Colors
+		values := make([]float32, 0, 4*len(p.nodes))
+		var transparent bool
+		for _, v := range p.nodes {
+			c := linearColor(colors[v[1]])
+			values = append(values, c[:]...)
+			transparent = transparent || c[3] < 1
+		}
+		prim.Attributes["COLOR_0"] = b.addAccessor(float32Bytes(values), componentFloat32, len(p.nodes), "VEC4", targetArrayBuffer, nil, nil)
+		mat = b.vertexColorMaterial(transparent)
+	case kindTexture:
+		group := p.key.res.(*go3mf.Texture2DGroupResource)
+		values := make([]float32, 0, 2*len(p.nodes))
+		for _, v := range p.nodes {
+			// glTF places the origin of the texture coordinates at the top left corner instead of at the bottom left one.
+			c := group.Coords[v[1]]
+			values = append(values, c.U(), 1-c.V())
+		}
+		prim.Attributes["TEXCOORD_0"] = b.addAccessor(float32Bytes(values), componentFloat32, len(p.nodes), "VEC2", targetArrayBuffer, nil, nil)
+		mat = b.textureMaterial(group)
+	default:
+		return prim
+	}
+	prim.Material = &mat
+	return prim
+}
+
+func (b *builder) baseMaterial(res *go3mf.BaseMaterialsResource, index uint32) int {
+	key := materialKey{res: res, index: index}
+	if i, ok := b.materials[key]; ok {
+		return i
+	}
+	base := res.Materials[index]
+	factor := linearColor(base.Color)
+	return b.addMaterial(key, material{Name: base.Name, PBR: pbr{BaseColorFactor: &factor}, AlphaMode: alphaMode(factor[3] < 1)})
+}
+
+// vertexColorMaterial returns a white material whose color is given by the vertex colors.
+func (b *builder) vertexColorMaterial(transparent bool) int {
+	var key materialKey
+	if transparent {
+		key.index = 1
+	}
+	if i, ok := b.materials[key]; ok {
+		return i
+	}
+	return b.addMaterial(key, material{AlphaMode: alphaMode(transparent)})
+}
+
+func (b *builder) textureMaterial(group *go3mf.Texture2DGroupResource) int {
+	key := materialKey{res: group}
+	if i, ok := b.materials[key]; ok {
+		return i
+	}
+	mat := material{}
+	if res, ok := b.model.FindResource(group.ModelPath, group.TextureID); ok {
+		if tex, ok := res.(*go3mf.Texture2DResource); ok {
+			mat.PBR.BaseColorTexture = &textureInfo{Index: b.texture(tex)}
+		}
+	}
+	return b.addMaterial(key, mat)
+}
+
+func (b *builder) addMaterial(key materialKey, mat material) int {
+	i := len(b.doc.Materials)
+	b.doc.Materials = append(b.doc.Materials, mat)
+	b.materials[key] = i
+	return i
+}
+
+func alphaMode(transparent bool) string {
+	if transparent {
+		return "BLEND"
+	}
+	return ""
+}
+
+// texture adds the texture, its sampler and its image, which is embedded from the model attachments.
+// The texture has no image if no attachment matches its path.
+func (b *builder) texture(res *go3mf.Texture2DResource) int {
+	if i, ok := b.textures[res]; ok {
+		return i
+	}
+	s := sampler{WrapS: wrapMode(res.TileStyleU), WrapT: wrapMode(res.TileStyleV)}
+	switch res.Filter {
+	case go3mf.TextureFilterLinear:
+		s.MagFilter, s.MinFilter = filterLinear, filterLinear
+	case go3mf.TextureFilterNearest:
+		s.MagFilter, s.MinFilter = filterNearest, filterNearest
+	default:
+		s.MagFilter, s.MinFilter = filterLinear, filterLinearMipmaps
+	}
+	tex := texture{Sampler: len(b.doc.Samplers)}
+	b.doc.Samplers = append(b.doc.Samplers, s)
+	if data, ok := b.attachment(res.Path); ok {
+		source := len(b.doc.Images)
+		b.doc.Images = append(b.doc.Images, image{BufferView: b.addView(data, 0), MimeType: res.ContentType.String()})
+		tex.Source = &source
+	}
+	i := len(b.doc.Textures)
+	b.doc.Textures = append(b.doc.Textures, tex)
+	b.textures[res] = i
+	return i
+}
+
+func wrapMode(t go3mf.TileStyle) int {
+	switch t {
+	case go3mf.TileMirror:
+		return wrapMirror
+	case go3mf.TileClamp, go3mf.TileNone:
+		return wrapClamp
+	}
+	return wrapRepeat
+}
+
+// attachment reads the content of the attachment with the target path.
+func (b *builder) attachment(path string) ([]byte, bool) {
+	for _, a := range b.model.Attachments {
+		if a.Path != path || a.Stream == nil {
+			continue
+		}
+		r := a.Stream
+		// Avoid draining the stream when it supports random access.
+		if ra, ok := r.(interface {
+			io.ReaderAt
+			Size() int64
+		}); ok {
+			r = io.NewSectionReader(ra, 0, ra.Size())
+		}
+		data, err := ioutil.ReadAll(r)
+		return data, err == nil && len(data) > 0
+	}
+	return nil, false
+}
+
+// addView appends the data to the binary buffer, aligned to 4 bytes, and returns its buffer view.
+func (b *builder) addView(data []byte, target int) int {
+	for len(b.bin)%4 != 0 {
+		b.bin = append(b.bin, 0)
+	}
+	i := len(b.doc.BufferViews)
+	b.doc.BufferViews = append(b.doc.BufferViews, bufferView{ByteOffset: len(b.bin), ByteLength: len(data), Target: target})
+	b.bin = append(b.bin, data...)
+	return i
+}
+
+func (b *builder) addAccessor(data []byte, componentType, count int, typ string, target int, min, max []float32) int {
+	i := len(b.doc.Accessors)
+	b.doc.Accessors = append(b.doc.Accessors, accessor{
+		BufferView:    b.addView(data, target),
+		ComponentType: componentType,
+		Count:         count,
+		Type:          typ,
+		Min:           min,
+		Max:           max,
+	})
+	return i
+}
+
+func float32Bytes(values []float32) []byte {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], math.Float32bits(v))
+	}
+	return data
+}
+
+func uint32Bytes(values []uint32) []byte {
+	data := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], v)
+	}
+	return data
+}
+
+// linearColor converts a sRGB color to the linear RGBA factors used by glTF.
+func linearColor(c color.RGBA) [4]float32 {
+	linear := func(v uint8) float32 {
+		f := float64(v) / 255
+		if f <= 0.04045 {
+			return float32(f / 12.92)
+		}
+		return float32(math.Pow((f+0.055)/1.055, 2.4))
+	}
+	return [4]float32{linear(c.R), linear(c.G), linear(c.B), float32(c.A) / 255}
+}
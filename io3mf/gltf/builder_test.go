@@ -0,0 +1,147 @@
+package gltf
+
+import (
+	"bytes"
+	"image/color"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+// newTestModel returns a model with a mesh that has a face of each kind of property,
+// which is built directly and through a components object.
+func newTestModel() *go3mf.Model {
+	base := &go3mf.BaseMaterialsResource{ID: 1, Materials: []go3mf.BaseMaterial{
+		{Name: "red", Color: color.RGBA{255, 0, 0, 255}},
+		{Name: "green", Color: color.RGBA{0, 255, 0, 128}},
+	}}
+	colors := &go3mf.ColorGroupResource{ID: 2, Colors: []color.RGBA{{0, 0, 255, 255}, {255, 255, 255, 255}}}
+	tex := &go3mf.Texture2DResource{ID: 3, Path: "/3D/Texture/a.png", ContentType: go3mf.TextureTypePNG, TileStyleU: go3mf.TileMirror, TileStyleV: go3mf.TileClamp}
+	group := &go3mf.Texture2DGroupResource{ID: 4, TextureID: 3, Coords: []go3mf.TextureCoord{{0, 0}, {1, 0}, {0, 1}}}
+	mesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 5, Name: "mesh", DefaultPropertyID: 1, DefaultPropertyIndex: 1}, Mesh: new(geo.Mesh)}
+	mesh.Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	mesh.Mesh.Faces = []geo.Face{
+		{NodeIndices: [3]uint32{0, 1, 2}, Resource: 1},
+		{NodeIndices: [3]uint32{0, 1, 2}, Resource: 2, ResourceIndices: [3]uint32{0, 1, 1}},
+		{NodeIndices: [3]uint32{1, 2, 3}, Resource: 2},
+		{NodeIndices: [3]uint32{0, 2, 3}, Resource: 4, ResourceIndices: [3]uint32{0, 1, 2}},
+		{NodeIndices: [3]uint32{1, 2, 3}},
+		{NodeIndices: [3]uint32{3, 2, 1}, Resource: 9},
+	}
+	components := &go3mf.ComponentsResource{ObjectResource: go3mf.ObjectResource{ID: 6, Name: "assembly"}, Components: []*go3mf.Component{
+		{Object: mesh, Transform: geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 10, 0, 0, 1}},
+		{Object: mesh},
+	}}
+	return &go3mf.Model{
+		Units:     go3mf.UnitCentimeter,
+		Resources: []go3mf.Resource{base, colors, tex, group, mesh, components},
+		BuildItems: []*go3mf.BuildItem{
+			{Object: components, Transform: geo.Matrix{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 1}},
+			{Object: mesh},
+		},
+		Attachments: []*go3mf.Attachment{{Path: "/3D/Texture/a.png", Stream: bytes.NewReader([]byte("image"))}},
+	}
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func Test_builder_build(t *testing.T) {
+	b := newBuilder(newTestModel())
+	if err := b.build(); err != nil {
+		t.Fatalf("builder.build() unexpected error = %v", err)
+	}
+	wantNodes := []node{
+		{Matrix: &[16]float32{0.01, 0, 0, 0, 0, 0, -0.01, 0, 0, 0.01, 0, 0, 0, 0, 0, 1}, Children: []int{1, 4}},
+		{Name: "assembly", Matrix: &[16]float32{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 1}, Children: []int{2, 3}},
+		{Name: "mesh", Matrix: &[16]float32{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 10, 0, 0, 1}, Mesh: intPtr(0)},
+		{Name: "mesh", Mesh: intPtr(0)},
+		{Name: "mesh", Mesh: intPtr(0)},
+	}
+	if diff := deep.Equal(b.doc.Nodes, wantNodes); diff != nil {
+		t.Errorf("builder.build() nodes = %v", diff)
+	}
+	wantMaterials := []material{
+		{Name: "red", PBR: pbr{BaseColorFactor: &[4]float32{1, 0, 0, 1}}},
+		{},
+		{PBR: pbr{BaseColorTexture: &textureInfo{Index: 0}}},
+		{Name: "green", PBR: pbr{BaseColorFactor: &[4]float32{0, 1, 0, 128.0 / 255}}, AlphaMode: "BLEND"},
+	}
+	if diff := deep.Equal(b.doc.Materials, wantMaterials); diff != nil {
+		t.Errorf("builder.build() materials = %v", diff)
+	}
+	wantPrimitives := []primitive{
+		{Attributes: map[string]int{"POSITION": 0}, Indices: 1, Material: intPtr(0)},
+		{Attributes: map[string]int{"POSITION": 2, "COLOR_0": 4}, Indices: 3, Material: intPtr(1)},
+		{Attributes: map[string]int{"POSITION": 5, "TEXCOORD_0": 7}, Indices: 6, Material: intPtr(2)},
+		{Attributes: map[string]int{"POSITION": 8}, Indices: 9, Material: intPtr(3)},
+		{Attributes: map[string]int{"POSITION": 10}, Indices: 11},
+	}
+	if len(b.doc.Meshes) != 1 {
+		t.Fatalf("builder.build() meshes = %d, want 1", len(b.doc.Meshes))
+	}
+	if diff := deep.Equal(b.doc.Meshes[0].Primitives, wantPrimitives); diff != nil {
+		t.Errorf("builder.build() primitives = %v", diff)
+	}
+	if diff := deep.Equal(b.doc.Textures, []texture{{Sampler: 0, Source: intPtr(0)}}); diff != nil {
+		t.Errorf("builder.build() textures = %v", diff)
+	}
+	if diff := deep.Equal(b.doc.Samplers, []sampler{{MagFilter: filterLinear, MinFilter: filterLinearMipmaps, WrapS: wrapMirror, WrapT: wrapClamp}}); diff != nil {
+		t.Errorf("builder.build() samplers = %v", diff)
+	}
+	if diff := deep.Equal(b.doc.Images, []image{{BufferView: 8, MimeType: "image/png"}}); diff != nil {
+		t.Errorf("builder.build() images = %v", diff)
+	}
+	// The color primitive splits the vertices 1 and 2, as each one has two different colors.
+	wantCounts := []int{3, 3, 6, 6, 6, 3, 3, 3, 3, 3, 3, 3}
+	for i, want := range wantCounts {
+		if got := b.doc.Accessors[i].Count; got != want {
+			t.Errorf("builder.build() accessor %d count = %d, want %d", i, got, want)
+		}
+	}
+	if diff := deep.Equal(b.doc.Accessors[10].Min, []float32{0, 0, 0}); diff != nil {
+		t.Errorf("builder.build() min = %v", diff)
+	}
+	if diff := deep.Equal(b.doc.Accessors[10].Max, []float32{1, 1, 1}); diff != nil {
+		t.Errorf("builder.build() max = %v", diff)
+	}
+	if view := b.doc.BufferViews[8]; string(b.bin[view.ByteOffset:view.ByteOffset+view.ByteLength]) != "image" {
+		t.Errorf("builder.build() image = %s, want image", b.bin[view.ByteOffset:view.ByteOffset+view.ByteLength])
+	}
+	if diff := deep.Equal(b.doc.Buffers, []buffer{{ByteLength: len(b.bin)}}); diff != nil {
+		t.Errorf("builder.build() buffers = %v", diff)
+	}
+}
+
+func Test_builder_build_cycle(t *testing.T) {
+	c1 := &go3mf.ComponentsResource{ObjectResource: go3mf.ObjectResource{ID: 1}}
+	c2 := &go3mf.ComponentsResource{ObjectResource: go3mf.ObjectResource{ID: 2}, Components: []*go3mf.Component{{Object: c1}}}
+	c1.Components = []*go3mf.Component{{Object: c2}}
+	b := newBuilder(&go3mf.Model{BuildItems: []*go3mf.BuildItem{{Object: c1}}})
+	if err := b.build(); err == nil {
+		t.Error("builder.build() expected cycle error")
+	}
+}
+
+func Test_linearColor(t *testing.T) {
+	tests := []struct {
+		name string
+		c    color.RGBA
+		want [4]float32
+	}{
+		{"black", color.RGBA{0, 0, 0, 0}, [4]float32{0, 0, 0, 0}},
+		{"white", color.RGBA{255, 255, 255, 255}, [4]float32{1, 1, 1, 1}},
+		{"dark", color.RGBA{10, 10, 10, 255}, [4]float32{0.003035269835488375, 0.003035269835488375, 0.003035269835488375, 1}},
+		{"mid", color.RGBA{128, 128, 128, 255}, [4]float32{0.21586050011389926, 0.21586050011389926, 0.21586050011389926, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(linearColor(tt.c), tt.want); diff != nil {
+				t.Errorf("linearColor() = %v", diff)
+			}
+		})
+	}
+}
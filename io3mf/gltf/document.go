@@ -0,0 +1,116 @@
+package gltf
+
+// The types of this file are the subset of the glTF 2.0 JSON schema used by the Encoder.
+
+const (
+	componentUint32  = 5125
+	componentFloat32 = 5126
+
+	targetArrayBuffer        = 34962
+	targetElementArrayBuffer = 34963
+
+	filterNearest       = 9728
+	filterLinear        = 9729
+	filterLinearMipmaps = 9987
+
+	wrapClamp  = 33071
+	wrapMirror = 33648
+	wrapRepeat = 10497
+)
+
+type document struct {
+	Asset       asset        `json:"asset"`
+	Scene       int          `json:"scene"`
+	Scenes      []scene      `json:"scenes"`
+	Nodes       []node       `json:"nodes,omitempty"`
+	Meshes      []mesh       `json:"meshes,omitempty"`
+	Materials   []material   `json:"materials,omitempty"`
+	Textures    []texture    `json:"textures,omitempty"`
+	Images      []image      `json:"images,omitempty"`
+	Samplers    []sampler    `json:"samplers,omitempty"`
+	Accessors   []accessor   `json:"accessors,omitempty"`
+	BufferViews []bufferView `json:"bufferViews,omitempty"`
+	Buffers     []buffer     `json:"buffers,omitempty"`
+}
+
+type asset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+
+type scene struct {
+	Nodes []int `json:"nodes"`
+}
+
+type node struct {
+	Name     string       `json:"name,omitempty"`
+	Matrix   *[16]float32 `json:"matrix,omitempty"`
+	Mesh     *int         `json:"mesh,omitempty"`
+	Children []int        `json:"children,omitempty"`
+}
+
+type mesh struct {
+	Name       string      `json:"name,omitempty"`
+	Primitives []primitive `json:"primitives"`
+}
+
+type primitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   *int           `json:"material,omitempty"`
+}
+
+type material struct {
+	Name        string `json:"name,omitempty"`
+	PBR         pbr    `json:"pbrMetallicRoughness"`
+	AlphaMode   string `json:"alphaMode,omitempty"`
+	DoubleSided bool   `json:"doubleSided,omitempty"`
+}
+
+type pbr struct {
+	BaseColorFactor  *[4]float32  `json:"baseColorFactor,omitempty"`
+	BaseColorTexture *textureInfo `json:"baseColorTexture,omitempty"`
+	MetallicFactor   float32      `json:"metallicFactor"`
+}
+
+type textureInfo struct {
+	Index int `json:"index"`
+}
+
+type texture struct {
+	Sampler int  `json:"sampler"`
+	Source  *int `json:"source,omitempty"`
+}
+
+type image struct {
+	BufferView int    `json:"bufferView"`
+	MimeType   string `json:"mimeType"`
+}
+
+type sampler struct {
+	MagFilter int `json:"magFilter,omitempty"`
+	MinFilter int `json:"minFilter,omitempty"`
+	WrapS     int `json:"wrapS"`
+	WrapT     int `json:"wrapT"`
+}
+
+type accessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+
+type bufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target,omitempty"`
+}
+
+type buffer struct {
+	ByteLength int    `json:"byteLength"`
+	URI        string `json:"uri,omitempty"`
+}
@@ -0,0 +1,99 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+
+	"github.com/qmuntal/go3mf"
+)
+
+// EncodingType is the type of encoding used in the file.
+type EncodingType int
+
+const (
+	// Binary when the model is encoded as a binary glTF (.glb) file.
+	Binary EncodingType = iota
+	// JSON when the model is encoded as a glTF (.gltf) file, with the binary data embedded as a data URI.
+	JSON
+)
+
+const (
+	glbMagic     = 0x46546C67 // glTF
+	glbVersion   = 2
+	glbChunkJSON = 0x4E4F534A // JSON
+	glbChunkBIN  = 0x004E4942 // BIN
+)
+
+// Encoder can encode a model as a glTF 2.0 scene.
+//
+// Every build item is a node of the scene and the components are child nodes with their transforms.
+// The mesh objects are written as meshes shared by all the nodes that reference them,
+// with a primitive for each kind of property of their faces:
+// base materials are glTF materials, color groups are vertex colors and
+// texture groups are texture coordinates of a material whose image is embedded from the model attachments.
+// The scene is scaled from the model units to meters and rotated to be +Y up.
+type Encoder struct {
+	w            io.Writer
+	encodingType EncodingType
+}
+
+// NewEncoder creates a new binary encoder.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w:            w,
+		encodingType: Binary,
+	}
+}
+
+// NewEncoderType creates a new encoder of the desired type.
+func NewEncoderType(w io.Writer, encodingType EncodingType) *Encoder {
+	return &Encoder{
+		w:            w,
+		encodingType: encodingType,
+	}
+}
+
+// Encode encodes the build of the model to the writer.
+func (e *Encoder) Encode(m *go3mf.Model) error {
+	b := newBuilder(m)
+	if err := b.build(); err != nil {
+		return err
+	}
+	if e.encodingType == JSON {
+		if len(b.doc.Buffers) > 0 {
+			b.doc.Buffers[0].URI = "data:application/octet-stream;base64," + base64.StdEncoding.EncodeToString(b.bin)
+		}
+		return json.NewEncoder(e.w).Encode(b.doc)
+	}
+	doc, err := json.Marshal(b.doc)
+	if err != nil {
+		return err
+	}
+	doc = pad(doc, ' ')
+	bin := pad(b.bin, 0)
+	length := 12 + 8 + len(doc)
+	if len(bin) > 0 {
+		length += 8 + len(bin)
+	}
+	buf := new(bytes.Buffer)
+	binary.Write(buf, binary.LittleEndian, [3]uint32{glbMagic, glbVersion, uint32(length)})
+	binary.Write(buf, binary.LittleEndian, [2]uint32{uint32(len(doc)), glbChunkJSON})
+	buf.Write(doc)
+	if len(bin) > 0 {
+		binary.Write(buf, binary.LittleEndian, [2]uint32{uint32(len(bin)), glbChunkBIN})
+		buf.Write(bin)
+	}
+	_, err = buf.WriteTo(e.w)
+	return err
+}
+
+// pad fills data with c up to a multiple of 4 bytes, as required by the GLB chunks.
+func pad(data []byte, c byte) []byte {
+	for len(data)%4 != 0 {
+		data = append(data, c)
+	}
+	return data
+}
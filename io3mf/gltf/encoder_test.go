@@ -0,0 +1,98 @@
+package gltf
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/qmuntal/go3mf"
+)
+
+func TestNewEncoder(t *testing.T) {
+	w := new(bytes.Buffer)
+	if got, want := NewEncoder(w), (&Encoder{w: w, encodingType: Binary}); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewEncoder() = %v, want %v", got, want)
+	}
+	if got, want := NewEncoderType(w, JSON), (&Encoder{w: w, encodingType: JSON}); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewEncoderType() = %v, want %v", got, want)
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("")
+}
+
+func TestEncoder_Encode_Binary(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   *go3mf.Model
+		wantBin bool
+	}{
+		{"empty", new(go3mf.Model), false},
+		{"model", newTestModel(), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			if err := NewEncoder(w).Encode(tt.model); err != nil {
+				t.Fatalf("Encoder.Encode() unexpected error = %v", err)
+			}
+			glb := w.Bytes()
+			var header [5]uint32
+			if err := binary.Read(bytes.NewReader(glb), binary.LittleEndian, &header); err != nil {
+				t.Fatal(err)
+			}
+			if header[0] != glbMagic || header[1] != glbVersion || int(header[2]) != len(glb) || header[4] != glbChunkJSON {
+				t.Fatalf("Encoder.Encode() header = %v", header)
+			}
+			var doc document
+			if err := json.Unmarshal(glb[20:20+header[3]], &doc); err != nil {
+				t.Fatalf("Encoder.Encode() json chunk error = %v", err)
+			}
+			rest := glb[20+header[3]:]
+			if !tt.wantBin {
+				if len(rest) != 0 || len(doc.Buffers) != 0 {
+					t.Errorf("Encoder.Encode() unexpected binary chunk")
+				}
+				return
+			}
+			var chunk [2]uint32
+			binary.Read(bytes.NewReader(rest), binary.LittleEndian, &chunk)
+			if chunk[1] != glbChunkBIN || int(chunk[0]) != len(rest)-8 || chunk[0]%4 != 0 || int(chunk[0]) < doc.Buffers[0].ByteLength {
+				t.Errorf("Encoder.Encode() binary chunk = %v, buffer = %v", chunk, doc.Buffers)
+			}
+		})
+	}
+	if err := NewEncoder(errWriter{}).Encode(newTestModel()); err == nil {
+		t.Error("Encoder.Encode() expected error")
+	}
+}
+
+func TestEncoder_Encode_JSON(t *testing.T) {
+	w := new(bytes.Buffer)
+	if err := NewEncoderType(w, JSON).Encode(newTestModel()); err != nil {
+		t.Fatalf("Encoder.Encode() unexpected error = %v", err)
+	}
+	var doc document
+	if err := json.Unmarshal(w.Bytes(), &doc); err != nil {
+		t.Fatalf("Encoder.Encode() json error = %v", err)
+	}
+	if doc.Asset.Version != "2.0" || len(doc.Buffers) != 1 {
+		t.Fatalf("Encoder.Encode() = %v", doc)
+	}
+	const prefix = "data:application/octet-stream;base64,"
+	if !strings.HasPrefix(doc.Buffers[0].URI, prefix) {
+		t.Fatalf("Encoder.Encode() uri = %s", doc.Buffers[0].URI)
+	}
+	bin, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(doc.Buffers[0].URI, prefix))
+	if err != nil || len(bin) != doc.Buffers[0].ByteLength {
+		t.Errorf("Encoder.Encode() buffer length = %d, want %d, err = %v", len(bin), doc.Buffers[0].ByteLength, err)
+	}
+}
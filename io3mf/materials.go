@@ -2,6 +2,8 @@ package io3mf
 
 import (
 	"encoding/xml"
+	"image/color"
+	"strconv"
 	"strings"
 
 	go3mf "github.com/qmuntal/go3mf"
@@ -33,8 +35,16 @@ func (d *colorGroupDecoder) Child(name xml.Name) (child nodeDecoder) {
 func (d *colorGroupDecoder) Attributes(attrs []xml.Attr) bool {
 	ok := true
 	for _, a := range attrs {
-		if a.Name.Space == "" && a.Name.Local == attrID {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrID:
 			d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
+		case attrDisplayPropsID:
+			d.resource.DisplayPropertiesID, ok = d.file.parser.ParseUint32Required(attrDisplayPropsID, a.Value)
+		}
+		if !ok {
 			break
 		}
 	}
@@ -119,6 +129,8 @@ func (d *tex2DGroupDecoder) Attributes(attrs []xml.Attr) bool {
 			d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
 		case attrTexID:
 			d.resource.TextureID, ok = d.file.parser.ParseUint32Required(attrTexID, a.Value)
+		case attrDisplayPropsID:
+			d.resource.DisplayPropertiesID, ok = d.file.parser.ParseUint32Required(attrDisplayPropsID, a.Value)
 		}
 		if !ok {
 			break
@@ -340,3 +352,478 @@ func (d *multiDecoder) Attributes(attrs []xml.Attr) (ok bool) {
 	}
 	return ok
 }
+
+type pbSpecularDisplayDecoder struct {
+	emptyDecoder
+	resource          go3mf.PBSpecularDisplayPropertiesResource
+	pbSpecularDecoder pbSpecularDecoder
+}
+
+func (d *pbSpecularDisplayDecoder) Open() {
+	d.resource.ModelPath = d.file.path
+	d.pbSpecularDecoder.resource = &d.resource
+}
+
+func (d *pbSpecularDisplayDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return d.file.parser.CloseResource()
+}
+
+func (d *pbSpecularDisplayDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsMaterialSpec && name.Local == attrPBSpecular {
+		child = &d.pbSpecularDecoder
+	}
+	return
+}
+
+func (d *pbSpecularDisplayDecoder) Attributes(attrs []xml.Attr) bool {
+	return d.file.parseDisplayID(attrs, &d.resource.ID)
+}
+
+type pbSpecularDecoder struct {
+	emptyDecoder
+	resource *go3mf.PBSpecularDisplayPropertiesResource
+}
+
+func (d *pbSpecularDecoder) Attributes(attrs []xml.Attr) bool {
+	p := go3mf.PBSpecular{SpecularColor: color.RGBA{0x38, 0x38, 0x38, 0xff}}
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrName:
+			p.Name = a.Value
+		case attrSpecularColor:
+			p.SpecularColor = d.file.parseOptionalColor(attrSpecularColor, a.Value, p.SpecularColor)
+		case attrGlossiness:
+			p.Glossiness = d.file.parser.ParseFloat64Optional(attrGlossiness, a.Value)
+		}
+	}
+	if p.Name == "" {
+		return d.file.parser.MissingAttr(attrName)
+	}
+	d.resource.Properties = append(d.resource.Properties, p)
+	return true
+}
+
+type pbMetallicDisplayDecoder struct {
+	emptyDecoder
+	resource          go3mf.PBMetallicDisplayPropertiesResource
+	pbMetallicDecoder pbMetallicDecoder
+}
+
+func (d *pbMetallicDisplayDecoder) Open() {
+	d.resource.ModelPath = d.file.path
+	d.pbMetallicDecoder.resource = &d.resource
+}
+
+func (d *pbMetallicDisplayDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return d.file.parser.CloseResource()
+}
+
+func (d *pbMetallicDisplayDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsMaterialSpec && name.Local == attrPBMetallic {
+		child = &d.pbMetallicDecoder
+	}
+	return
+}
+
+func (d *pbMetallicDisplayDecoder) Attributes(attrs []xml.Attr) bool {
+	return d.file.parseDisplayID(attrs, &d.resource.ID)
+}
+
+type pbMetallicDecoder struct {
+	emptyDecoder
+	resource *go3mf.PBMetallicDisplayPropertiesResource
+}
+
+func (d *pbMetallicDecoder) Attributes(attrs []xml.Attr) bool {
+	p := go3mf.PBMetallic{Roughness: 1}
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrName:
+			p.Name = a.Value
+		case attrMetallicness:
+			p.Metallicness = d.file.parser.ParseFloat64Optional(attrMetallicness, a.Value)
+		case attrRoughness:
+			p.Roughness = d.file.parser.ParseFloat64Optional(attrRoughness, a.Value)
+		}
+	}
+	if p.Name == "" {
+		return d.file.parser.MissingAttr(attrName)
+	}
+	d.resource.Properties = append(d.resource.Properties, p)
+	return true
+}
+
+type pbSpecularTextureDecoder struct {
+	emptyDecoder
+	resource go3mf.PBSpecularTextureDisplayPropertiesResource
+}
+
+func (d *pbSpecularTextureDecoder) Open() {
+	d.resource.ModelPath = d.file.path
+	d.resource.DiffuseFactor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	d.resource.SpecularFactor = color.RGBA{0xff, 0xff, 0xff, 0xff}
+	d.resource.GlossinessFactor = 1
+}
+
+func (d *pbSpecularTextureDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return d.file.parser.CloseResource()
+}
+
+func (d *pbSpecularTextureDecoder) Attributes(attrs []xml.Attr) bool {
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrID:
+			d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
+		case attrName:
+			d.resource.Name = a.Value
+		case attrSpecularTextureID:
+			d.resource.SpecularTextureID, ok = d.file.parser.ParseUint32Required(attrSpecularTextureID, a.Value)
+		case attrGlossTextureID:
+			d.resource.GlossinessTextureID, ok = d.file.parser.ParseUint32Required(attrGlossTextureID, a.Value)
+		case attrDiffuseFactor:
+			d.resource.DiffuseFactor = d.file.parseOptionalColor(attrDiffuseFactor, a.Value, d.resource.DiffuseFactor)
+		case attrSpecularFactor:
+			d.resource.SpecularFactor = d.file.parseOptionalColor(attrSpecularFactor, a.Value, d.resource.SpecularFactor)
+		case attrGlossinessFactor:
+			d.resource.GlossinessFactor = d.file.parser.ParseFloat64Optional(attrGlossinessFactor, a.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if d.resource.Name == "" {
+		ok = d.file.parser.MissingAttr(attrName)
+	}
+	if ok && d.resource.SpecularTextureID == 0 {
+		ok = d.file.parser.MissingAttr(attrSpecularTextureID)
+	}
+	if ok && d.resource.GlossinessTextureID == 0 {
+		ok = d.file.parser.MissingAttr(attrGlossTextureID)
+	}
+	return ok
+}
+
+type pbMetallicTextureDecoder struct {
+	emptyDecoder
+	resource go3mf.PBMetallicTextureDisplayPropertiesResource
+}
+
+func (d *pbMetallicTextureDecoder) Open() {
+	d.resource.ModelPath = d.file.path
+	d.resource.MetallicFactor = 1
+	d.resource.RoughnessFactor = 1
+}
+
+func (d *pbMetallicTextureDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return d.file.parser.CloseResource()
+}
+
+func (d *pbMetallicTextureDecoder) Attributes(attrs []xml.Attr) bool {
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrID:
+			d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
+		case attrName:
+			d.resource.Name = a.Value
+		case attrMetallicTextureID:
+			d.resource.MetallicTextureID, ok = d.file.parser.ParseUint32Required(attrMetallicTextureID, a.Value)
+		case attrRoughnessTextureID:
+			d.resource.RoughnessTextureID, ok = d.file.parser.ParseUint32Required(attrRoughnessTextureID, a.Value)
+		case attrMetallicFactor:
+			d.resource.MetallicFactor = d.file.parser.ParseFloat64Optional(attrMetallicFactor, a.Value)
+		case attrRoughnessFactor:
+			d.resource.RoughnessFactor = d.file.parser.ParseFloat64Optional(attrRoughnessFactor, a.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if d.resource.Name == "" {
+		ok = d.file.parser.MissingAttr(attrName)
+	}
+	if ok && d.resource.MetallicTextureID == 0 {
+		ok = d.file.parser.MissingAttr(attrMetallicTextureID)
+	}
+	if ok && d.resource.RoughnessTextureID == 0 {
+		ok = d.file.parser.MissingAttr(attrRoughnessTextureID)
+	}
+	return ok
+}
+
+type translucentDisplayDecoder struct {
+	emptyDecoder
+	resource           go3mf.TranslucentDisplayPropertiesResource
+	translucentDecoder translucentDecoder
+}
+
+func (d *translucentDisplayDecoder) Open() {
+	d.resource.ModelPath = d.file.path
+	d.translucentDecoder.resource = &d.resource
+}
+
+func (d *translucentDisplayDecoder) Close() bool {
+	d.file.AddResource(&d.resource)
+	return d.file.parser.CloseResource()
+}
+
+func (d *translucentDisplayDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsMaterialSpec && name.Local == attrTranslucent {
+		child = &d.translucentDecoder
+	}
+	return
+}
+
+func (d *translucentDisplayDecoder) Attributes(attrs []xml.Attr) bool {
+	return d.file.parseDisplayID(attrs, &d.resource.ID)
+}
+
+type translucentDecoder struct {
+	emptyDecoder
+	resource *go3mf.TranslucentDisplayPropertiesResource
+}
+
+func (d *translucentDecoder) Attributes(attrs []xml.Attr) bool {
+	t := go3mf.Translucent{RefractiveIndex: [3]float64{1, 1, 1}}
+	var withAttenuation bool
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrName:
+			t.Name = a.Value
+		case attrAttenuation:
+			withAttenuation = true
+			if t.Attenuation, ok = parseRGBValues(a.Value); !ok {
+				ok = d.file.parser.InvalidRequiredAttr(attrAttenuation, a.Value)
+			}
+		case attrRefractiveIndex:
+			if v, valid := parseRGBValues(a.Value); valid {
+				t.RefractiveIndex = v
+			} else {
+				d.file.parser.InvalidOptionalAttr(attrRefractiveIndex, a.Value)
+			}
+		case attrRoughness:
+			t.Roughness = d.file.parser.ParseFloat64Optional(attrRoughness, a.Value)
+		}
+		if !ok {
+			return false
+		}
+	}
+	if t.Name == "" {
+		ok = d.file.parser.MissingAttr(attrName)
+	}
+	if ok && !withAttenuation {
+		ok = d.file.parser.MissingAttr(attrAttenuation)
+	}
+	if ok {
+		d.resource.Properties = append(d.resource.Properties, t)
+	}
+	return ok
+}
+
+// parseDisplayID parses the id of the display properties resources, which is the only attribute they have.
+func (d *modelFile) parseDisplayID(attrs []xml.Attr, id *uint32) bool {
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrID {
+			var ok bool
+			*id, ok = d.parser.ParseResourceID(a.Value)
+			return ok
+		}
+	}
+	return true
+}
+
+func (d *modelFile) parseOptionalColor(attr, s string, defColor color.RGBA) color.RGBA {
+	c, err := strToSRGB(s)
+	if err != nil {
+		d.parser.InvalidOptionalAttr(attr, s)
+		return defColor
+	}
+	return c
+}
+
+// parseRGBValues parses three space-separated numbers, one per color channel.
+func parseRGBValues(s string) (v [3]float64, ok bool) {
+	fields := strings.Fields(s)
+	if len(fields) != 3 {
+		return v, false
+	}
+	for i, f := range fields {
+		var err error
+		if v[i], err = strconv.ParseFloat(f, 64); err != nil {
+			return v, false
+		}
+	}
+	return v, true
+}
+
+func (w *modelWriter) writeColorGroup(r *go3mf.ColorGroupResource) {
+	attrs := []xml.Attr{w.attr(attrID, formatUint32(r.ID))}
+	if r.DisplayPropertiesID != 0 {
+		attrs = append(attrs, w.attr(attrDisplayPropsID, formatUint32(r.DisplayPropertiesID)))
+	}
+	w.startNS(nsMaterialSpec, attrColorGroup, attrs...)
+	for _, c := range r.Colors {
+		w.elementNS(nsMaterialSpec, attrColor, w.attr(attrColor, formatRGBA(c)))
+	}
+	w.endNS(nsMaterialSpec, attrColorGroup)
+}
+
+func (w *modelWriter) writeTexture2DGroup(r *go3mf.Texture2DGroupResource) {
+	attrs := []xml.Attr{w.attr(attrID, formatUint32(r.ID)), w.attr(attrTexID, formatUint32(r.TextureID))}
+	if r.DisplayPropertiesID != 0 {
+		attrs = append(attrs, w.attr(attrDisplayPropsID, formatUint32(r.DisplayPropertiesID)))
+	}
+	w.startNS(nsMaterialSpec, attrTexture2DGroup, attrs...)
+	for _, c := range r.Coords {
+		w.elementNS(nsMaterialSpec, attrTex2DCoord, w.attr(attrU, formatFloat32(c.U())), w.attr(attrV, formatFloat32(c.V())))
+	}
+	w.endNS(nsMaterialSpec, attrTexture2DGroup)
+}
+
+func (w *modelWriter) writeTexture2D(r *go3mf.Texture2DResource) {
+	attrs := []xml.Attr{
+		w.attr(attrID, formatUint32(r.ID)),
+		w.attr(attrPath, r.Path),
+	}
+	if r.ContentType != 0 {
+		attrs = append(attrs, w.attr(attrContentType, r.ContentType.String()))
+	}
+	attrs = append(attrs,
+		w.attr(attrTileStyleU, r.TileStyleU.String()),
+		w.attr(attrTileStyleV, r.TileStyleV.String()),
+		w.attr(attrFilter, r.Filter.String()),
+	)
+	w.elementNS(nsMaterialSpec, attrTexture2D, attrs...)
+}
+
+func (w *modelWriter) writeCompositeMaterials(r *go3mf.CompositeMaterialsResource) {
+	indices := make([]string, len(r.Indices))
+	for i, index := range r.Indices {
+		indices[i] = formatUint32(index)
+	}
+	w.startNS(nsMaterialSpec, attrCompositematerials,
+		w.attr(attrID, formatUint32(r.ID)),
+		w.attr(attrMatID, formatUint32(r.MaterialID)),
+		w.attr(attrMatIndices, strings.Join(indices, " ")),
+	)
+	for _, c := range r.Composites {
+		values := make([]string, len(c.Values))
+		for i, v := range c.Values {
+			values[i] = formatFloat64(v)
+		}
+		w.elementNS(nsMaterialSpec, attrComposite, w.attr(attrValues, strings.Join(values, " ")))
+	}
+	w.endNS(nsMaterialSpec, attrCompositematerials)
+}
+
+func (w *modelWriter) writeMultiProperties(r *go3mf.MultiPropertiesResource) {
+	pids := make([]string, len(r.Resources))
+	for i, pid := range r.Resources {
+		pids[i] = formatUint32(pid)
+	}
+	attrs := []xml.Attr{
+		w.attr(attrID, formatUint32(r.ID)),
+		w.attr(attrPIDs, strings.Join(pids, " ")),
+	}
+	if len(r.BlendMethods) > 0 {
+		methods := make([]string, len(r.BlendMethods))
+		for i, b := range r.BlendMethods {
+			methods[i] = b.String()
+		}
+		attrs = append(attrs, w.attr(attrBlendMethods, strings.Join(methods, " ")))
+	}
+	w.startNS(nsMaterialSpec, attrMultiProps, attrs...)
+	for _, m := range r.Multis {
+		indices := make([]string, len(m.ResourceIndices))
+		for i, index := range m.ResourceIndices {
+			indices[i] = formatUint32(index)
+		}
+		w.elementNS(nsMaterialSpec, attrMulti, w.attr(attrPIndices, strings.Join(indices, " ")))
+	}
+	w.endNS(nsMaterialSpec, attrMultiProps)
+}
+
+func (w *modelWriter) writePBSpecularDisplay(r *go3mf.PBSpecularDisplayPropertiesResource) {
+	w.startNS(nsMaterialSpec, attrPBSpecularDisplay, w.attr(attrID, formatUint32(r.ID)))
+	for _, p := range r.Properties {
+		w.elementNS(nsMaterialSpec, attrPBSpecular,
+			w.attr(attrName, p.Name),
+			w.attr(attrSpecularColor, formatRGBA(p.SpecularColor)),
+			w.attr(attrGlossiness, formatFloat64(p.Glossiness)),
+		)
+	}
+	w.endNS(nsMaterialSpec, attrPBSpecularDisplay)
+}
+
+func (w *modelWriter) writePBMetallicDisplay(r *go3mf.PBMetallicDisplayPropertiesResource) {
+	w.startNS(nsMaterialSpec, attrPBMetallicDisplay, w.attr(attrID, formatUint32(r.ID)))
+	for _, p := range r.Properties {
+		w.elementNS(nsMaterialSpec, attrPBMetallic,
+			w.attr(attrName, p.Name),
+			w.attr(attrMetallicness, formatFloat64(p.Metallicness)),
+			w.attr(attrRoughness, formatFloat64(p.Roughness)),
+		)
+	}
+	w.endNS(nsMaterialSpec, attrPBMetallicDisplay)
+}
+
+func (w *modelWriter) writePBSpecularTexture(r *go3mf.PBSpecularTextureDisplayPropertiesResource) {
+	w.elementNS(nsMaterialSpec, attrPBSpecularTexture,
+		w.attr(attrID, formatUint32(r.ID)),
+		w.attr(attrName, r.Name),
+		w.attr(attrSpecularTextureID, formatUint32(r.SpecularTextureID)),
+		w.attr(attrGlossTextureID, formatUint32(r.GlossinessTextureID)),
+		w.attr(attrDiffuseFactor, formatRGBA(r.DiffuseFactor)),
+		w.attr(attrSpecularFactor, formatRGBA(r.SpecularFactor)),
+		w.attr(attrGlossinessFactor, formatFloat64(r.GlossinessFactor)),
+	)
+}
+
+func (w *modelWriter) writePBMetallicTexture(r *go3mf.PBMetallicTextureDisplayPropertiesResource) {
+	w.elementNS(nsMaterialSpec, attrPBMetallicTexture,
+		w.attr(attrID, formatUint32(r.ID)),
+		w.attr(attrName, r.Name),
+		w.attr(attrMetallicTextureID, formatUint32(r.MetallicTextureID)),
+		w.attr(attrRoughnessTextureID, formatUint32(r.RoughnessTextureID)),
+		w.attr(attrMetallicFactor, formatFloat64(r.MetallicFactor)),
+		w.attr(attrRoughnessFactor, formatFloat64(r.RoughnessFactor)),
+	)
+}
+
+func (w *modelWriter) writeTranslucentDisplay(r *go3mf.TranslucentDisplayPropertiesResource) {
+	w.startNS(nsMaterialSpec, attrTranslucentDisplay, w.attr(attrID, formatUint32(r.ID)))
+	for _, t := range r.Properties {
+		w.elementNS(nsMaterialSpec, attrTranslucent,
+			w.attr(attrName, t.Name),
+			w.attr(attrAttenuation, formatRGBValues(t.Attenuation)),
+			w.attr(attrRefractiveIndex, formatRGBValues(t.RefractiveIndex)),
+			w.attr(attrRoughness, formatFloat64(t.Roughness)),
+		)
+	}
+	w.endNS(nsMaterialSpec, attrTranslucentDisplay)
+}
+
+func formatRGBValues(v [3]float64) string {
+	return formatFloat64(v[0]) + " " + formatFloat64(v[1]) + " " + formatFloat64(v[2])
+}
@@ -9,12 +9,19 @@ import (
 
 type meshDecoder struct {
 	emptyDecoder
-	resource go3mf.MeshResource
+	resource      go3mf.MeshResource
+	nodeCount     uint32
+	triangleCount uint32
+	onVertex      func(*go3mf.MeshResource, geo.Point3D) error
+	onTriangle    func(*go3mf.MeshResource, geo.Face) error
 }
 
 func (d *meshDecoder) Open() {
 	d.resource.Mesh = new(geo.Mesh)
 	d.resource.Mesh.StartCreation(geo.CreationOptions{CalculateConnectivity: false})
+	if d.file.d != nil {
+		d.onVertex, d.onTriangle = d.file.d.OnVertex, d.file.d.OnTriangle
+	}
 }
 
 func (d *meshDecoder) Close() bool {
@@ -26,24 +33,61 @@ func (d *meshDecoder) Close() bool {
 func (d *meshDecoder) Child(name xml.Name) (child nodeDecoder) {
 	if name.Space == nsCoreSpec {
 		if name.Local == attrVertices {
-			child = &verticesDecoder{resource: &d.resource}
+			child = &verticesDecoder{mesh: d}
 		} else if name.Local == attrTriangles {
-			child = &trianglesDecoder{resource: &d.resource}
+			child = &trianglesDecoder{mesh: d}
 		}
 	} else if name.Space == nsBeamLatticeSpec && name.Local == attrBeamLattice {
-		child = &beamLatticeDecoder{resource: &d.resource}
+		child = &beamLatticeDecoder{resource: &d.resource, nodeCount: d.nodeCount}
+	} else if name.Space == nsTriangleSetsSpec && name.Local == attrTriangleSets {
+		child = &triangleSetsDecoder{mesh: d}
+	} else {
+		child = d.file.unknownElement(&d.resource.MeshUnknown, name)
 	}
 	return
 }
 
+func (d *meshDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		d.file.unknownAttr(&d.resource.MeshUnknown, a)
+	}
+	return true
+}
+
+func (d *meshDecoder) addNode(n geo.Point3D) bool {
+	d.nodeCount++
+	if d.onVertex == nil {
+		d.resource.Mesh.AddNode(n)
+		return true
+	}
+	return d.hookResult(d.onVertex(&d.resource, n))
+}
+
+func (d *meshDecoder) addFace(f geo.Face) bool {
+	d.triangleCount++
+	if d.onTriangle == nil {
+		d.resource.Mesh.Faces = append(d.resource.Mesh.Faces, f)
+		return true
+	}
+	return d.hookResult(d.onTriangle(&d.resource, f))
+}
+
+func (d *meshDecoder) hookResult(err error) bool {
+	if err != nil {
+		d.file.parser.Err = err
+		return false
+	}
+	return true
+}
+
 type verticesDecoder struct {
 	emptyDecoder
-	resource      *go3mf.MeshResource
+	mesh          *meshDecoder
 	vertexDecoder vertexDecoder
 }
 
 func (d *verticesDecoder) Open() {
-	d.vertexDecoder.resource = d.resource
+	d.vertexDecoder.mesh = d.mesh
 }
 
 func (d *verticesDecoder) Child(name xml.Name) (child nodeDecoder) {
@@ -55,7 +99,7 @@ func (d *verticesDecoder) Child(name xml.Name) (child nodeDecoder) {
 
 type vertexDecoder struct {
 	emptyDecoder
-	resource *go3mf.MeshResource
+	mesh *meshDecoder
 }
 
 func (d *vertexDecoder) Attributes(attrs []xml.Attr) bool {
@@ -74,21 +118,20 @@ func (d *vertexDecoder) Attributes(attrs []xml.Attr) bool {
 			return false
 		}
 	}
-	d.resource.Mesh.AddNode(geo.Point3D{x, y, z})
-	return true
+	return d.mesh.addNode(geo.Point3D{x, y, z})
 }
 
 type trianglesDecoder struct {
 	emptyDecoder
-	resource        *go3mf.MeshResource
+	mesh            *meshDecoder
 	triangleDecoder triangleDecoder
 }
 
 func (d *trianglesDecoder) Open() {
-	d.triangleDecoder.resource = d.resource
-
-	if len(d.resource.Mesh.Faces) == 0 && len(d.resource.Mesh.Nodes) > 0 {
-		d.resource.Mesh.Faces = make([]geo.Face, 0, len(d.resource.Mesh.Nodes)-1)
+	d.triangleDecoder.mesh = d.mesh
+	mesh := d.mesh.resource.Mesh
+	if d.mesh.onTriangle == nil && len(mesh.Faces) == 0 && len(mesh.Nodes) > 0 {
+		mesh.Faces = make([]geo.Face, 0, len(mesh.Nodes)-1)
 	}
 }
 
@@ -101,7 +144,7 @@ func (d *trianglesDecoder) Child(name xml.Name) (child nodeDecoder) {
 
 type triangleDecoder struct {
 	emptyDecoder
-	resource *go3mf.MeshResource
+	mesh *meshDecoder
 }
 
 func (d *triangleDecoder) Attributes(attrs []xml.Attr) bool {
@@ -134,10 +177,11 @@ func (d *triangleDecoder) Attributes(attrs []xml.Attr) bool {
 		}
 	}
 
-	p1 = applyDefault(p1, d.resource.DefaultPropertyIndex, hasP1)
+	resource := &d.mesh.resource
+	p1 = applyDefault(p1, resource.DefaultPropertyIndex, hasP1)
 	p2 = applyDefault(p2, p1, hasP2)
 	p3 = applyDefault(p3, p1, hasP3)
-	pid = applyDefault(pid, d.resource.DefaultPropertyID, hasPID)
+	pid = applyDefault(pid, resource.DefaultPropertyID, hasPID)
 
 	return d.addTriangle(v1, v2, v3, pid, p1, p2, p3)
 }
@@ -146,16 +190,15 @@ func (d *triangleDecoder) addTriangle(v1, v2, v3, pid, p1, p2, p3 uint32) bool {
 	if v1 == v2 || v1 == v3 || v2 == v3 {
 		return d.file.parser.GenericError(true, "duplicated triangle indices")
 	}
-	nodeCount := uint32(len(d.resource.Mesh.Nodes))
+	nodeCount := d.mesh.nodeCount
 	if v1 >= nodeCount || v2 >= nodeCount || v3 >= nodeCount {
 		return d.file.parser.GenericError(true, "triangle indices are out of range")
 	}
-	d.resource.Mesh.Faces = append(d.resource.Mesh.Faces, geo.Face{
+	return d.mesh.addFace(geo.Face{
 		NodeIndices:     [3]uint32{v1, v2, v3},
 		Resource:        pid,
 		ResourceIndices: [3]uint32{p1, p2, p3},
 	})
-	return true
 }
 
 func applyDefault(val, defVal uint32, noDef bool) uint32 {
@@ -164,3 +207,96 @@ func applyDefault(val, defVal uint32, noDef bool) uint32 {
 	}
 	return defVal
 }
+
+func (w *modelWriter) writeMeshObject(r *go3mf.MeshResource) {
+	w.start(attrObject, w.objectAttrs(&r.ObjectResource)...)
+	w.writeMetadataGroup(r.Metadata)
+	w.start(attrMesh, w.unknownAttrs(r.MeshUnknown.Attr)...)
+	if s, ok := w.streams[r]; ok {
+		w.writeMeshStream(r, s)
+	} else if r.Mesh != nil {
+		w.writeVertices(r.Mesh)
+		// A nil slice means the triangles element was not present, as in some beam lattices.
+		if r.Mesh.Faces != nil {
+			w.writeTriangles(r)
+		}
+	}
+	if r.Mesh != nil && len(r.Mesh.TriangleSets) > 0 {
+		w.writeTriangleSets(r.Mesh.TriangleSets)
+	}
+	if hasBeamLattice(r) {
+		w.writeBeamLattice(r)
+	}
+	w.writeUnknownTokens(r.MeshUnknown)
+	w.end(attrMesh)
+	w.writeAlternatives(r.Alternatives)
+	w.writeUnknownTokens(w.extensions[&r.ObjectResource])
+	w.writeUnknownTokens(r.Unknown)
+	w.end(attrObject)
+}
+
+func (w *modelWriter) writeMeshStream(r *go3mf.MeshResource, s MeshStream) {
+	w.start(attrVertices)
+	if err := s.Vertices(func(n geo.Point3D) error {
+		w.writeVertex(n)
+		return w.err
+	}); w.err == nil {
+		w.err = err
+	}
+	w.end(attrVertices)
+	w.start(attrTriangles)
+	if err := s.Faces(func(f geo.Face) error {
+		w.writeTriangle(r, f)
+		return w.err
+	}); w.err == nil {
+		w.err = err
+	}
+	w.end(attrTriangles)
+}
+
+func (w *modelWriter) writeVertices(m *geo.Mesh) {
+	w.start(attrVertices)
+	for _, n := range m.Nodes {
+		w.writeVertex(n)
+	}
+	w.end(attrVertices)
+}
+
+func (w *modelWriter) writeVertex(n geo.Point3D) {
+	w.element(attrVertex,
+		w.attr(attrX, formatFloat32(n.X())),
+		w.attr(attrY, formatFloat32(n.Y())),
+		w.attr(attrZ, formatFloat32(n.Z())),
+	)
+}
+
+func (w *modelWriter) writeTriangles(r *go3mf.MeshResource) {
+	w.start(attrTriangles)
+	for _, f := range r.Mesh.Faces {
+		w.writeTriangle(r, f)
+	}
+	w.end(attrTriangles)
+}
+
+func (w *modelWriter) writeTriangle(r *go3mf.MeshResource, f geo.Face) {
+	attrs := []xml.Attr{
+		w.attr(attrV1, formatUint32(f.NodeIndices[0])),
+		w.attr(attrV2, formatUint32(f.NodeIndices[1])),
+		w.attr(attrV3, formatUint32(f.NodeIndices[2])),
+	}
+	// Only write the properties that differ from the defaults applied by the decoder.
+	if f.Resource != r.DefaultPropertyID {
+		attrs = append(attrs, w.attr(attrPID, formatUint32(f.Resource)))
+	}
+	p1, p2, p3 := f.ResourceIndices[0], f.ResourceIndices[1], f.ResourceIndices[2]
+	if p1 != r.DefaultPropertyIndex {
+		attrs = append(attrs, w.attr(attrP1, formatUint32(p1)))
+	}
+	if p2 != p1 {
+		attrs = append(attrs, w.attr(attrP2, formatUint32(p2)))
+	}
+	if p3 != p1 {
+		attrs = append(attrs, w.attr(attrP3, formatUint32(p3)))
+	}
+	w.element(attrTriangle, attrs...)
+}
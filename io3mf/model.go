@@ -35,6 +35,8 @@ func (d *modelDecoder) Child(name xml.Name) (child nodeDecoder) {
 				child = &metadataDecoder{metadatas: &d.model.Metadata}
 			}
 		}
+	} else if d.file.isRoot {
+		child = d.file.unknownElement(&d.model.Unknown, name)
 	}
 	return
 }
@@ -65,7 +67,7 @@ func (d *modelDecoder) Attributes(attrs []xml.Attr) bool {
 func (d *modelDecoder) checkRequiredExt(requiredExts string) bool {
 	for _, ext := range strings.Fields(requiredExts) {
 		ext = d.file.namespaces[ext]
-		if ext != nsCoreSpec && ext != nsMaterialSpec && ext != nsProductionSpec && ext != nsBeamLatticeSpec && ext != nsSliceSpec {
+		if !extensionSupported(ext) {
 			if !d.file.parser.GenericError(true, fmt.Sprintf("'%s' extension is not supported", ext)) {
 				return false
 			}
@@ -84,6 +86,10 @@ func (d *modelDecoder) noCoreAttribute(a xml.Attr) {
 		}
 	case attrXmlns:
 		d.file.namespaces[a.Name.Local] = a.Value
+	default:
+		if d.file.isRoot {
+			d.file.unknownAttr(&d.model.Unknown, a)
+		}
 	}
 }
 
@@ -145,3 +151,33 @@ func (d *metadataDecoder) Close() bool {
 	*d.metadatas = append(*d.metadatas, d.metadata)
 	return true
 }
+
+func (w *modelWriter) writeMetadataGroup(metadata []go3mf.Metadata) {
+	if len(metadata) == 0 {
+		return
+	}
+	w.start(attrMetadataGroup)
+	for _, m := range metadata {
+		w.writeMetadata(m)
+	}
+	w.end(attrMetadataGroup)
+}
+
+func (w *modelWriter) writeMetadata(m go3mf.Metadata) {
+	name := m.Name
+	if i := strings.LastIndexByte(name, ':'); i > 0 {
+		if prefix, ok := w.prefixes[name[:i]]; ok {
+			name = prefix + name[i:]
+		}
+	}
+	attrs := []xml.Attr{w.attr(attrName, name)}
+	if m.Type != "" {
+		attrs = append(attrs, w.attr(attrType, m.Type))
+	}
+	if m.Preserve {
+		attrs = append(attrs, w.attr(attrPreserve, "1"))
+	}
+	w.start(attrMetadata, attrs...)
+	w.text(m.Value)
+	w.end(attrMetadata)
+}
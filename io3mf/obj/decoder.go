@@ -0,0 +1,392 @@
+package obj
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+var checkEveryFaces = 1000
+
+const relTypeTexture3D = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dtexture"
+
+// Decoder can decode a Wavefront OBJ file, and the MTL libraries it references, to a model.
+//
+// Each object (o) is decoded as a MeshResource, and polygons are triangulated as a fan.
+// The materials selected with usemtl are added to a BaseMaterialsResource,
+// or to a ColorGroupResource when ColorGroup is true, and assigned to the faces as their property.
+// Faces that have texture coordinates (vt) and whose material has a diffuse map (map_Kd)
+// reference instead a Texture2DGroupResource of that texture.
+type Decoder struct {
+	// Open opens the material libraries and the textures referenced by the file.
+	// When it is nil the mtllib statements are ignored and all the materials are decoded with a default color.
+	Open func(name string) (io.ReadCloser, error)
+	// ColorGroup maps the materials to a ColorGroupResource instead of a BaseMaterialsResource.
+	ColorGroup bool
+	r          io.Reader
+}
+
+// NewDecoder creates a new decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: r,
+	}
+}
+
+// Decode creates the meshes and their materials from a read stream.
+func (d *Decoder) Decode(m *go3mf.Model) error {
+	return d.DecodeContext(context.Background(), m)
+}
+
+// DecodeContext creates the meshes and their materials from a read stream.
+func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
+	dec := objDecoder{
+		Decoder:  d,
+		model:    m,
+		library:  make(map[string]*material),
+		textures: make(map[string]*texture),
+	}
+	return dec.decode(ctx)
+}
+
+// objMesh is an object of the file, the nodes are mapped from the global vertex indices.
+type objMesh struct {
+	resource *go3mf.MeshResource
+	nodes    map[int]uint32
+}
+
+type texture struct {
+	group  *go3mf.Texture2DGroupResource
+	coords map[int]uint32
+}
+
+type objDecoder struct {
+	*Decoder
+	model     *go3mf.Model
+	positions []geo.Point3D
+	uvs       []go3mf.TextureCoord
+	library   map[string]*material
+	textures  map[string]*texture
+	meshes    []*objMesh
+	mesh      *objMesh
+	material  *material
+	materials *go3mf.BaseMaterialsResource
+	colors    *go3mf.ColorGroupResource
+	faces     int
+}
+
+func (d *objDecoder) decode(ctx context.Context) error {
+	scanner := bufio.NewScanner(d.r)
+	nextFaceCheck := checkEveryFaces
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "v":
+			err = d.decodeVertex(fields[1:])
+		case "vt":
+			err = d.decodeTextureCoord(fields[1:])
+		case "f":
+			err = d.decodeFace(fields[1:])
+		case "o":
+			d.mesh = d.newMesh(strings.Join(fields[1:], " "))
+		case "usemtl":
+			d.material = d.findMaterial(strings.Join(fields[1:], " "))
+		case "mtllib":
+			err = d.loadLibraries(fields[1:])
+		}
+		if err != nil {
+			return fmt.Errorf("go3mf: obj line %d: %v", line, err)
+		}
+		if d.faces > nextFaceCheck {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default: // Default is must to avoid blocking
+			}
+			nextFaceCheck += checkEveryFaces
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	for _, mesh := range d.meshes {
+		if len(mesh.resource.Mesh.Faces) > 0 {
+			mesh.resource.ID = d.model.UnusedID()
+			d.model.Resources = append(d.model.Resources, mesh.resource)
+		}
+	}
+	return nil
+}
+
+func (d *objDecoder) newMesh(name string) *objMesh {
+	mesh := &objMesh{
+		resource: &go3mf.MeshResource{
+			ObjectResource: go3mf.ObjectResource{ModelPath: d.model.Path, Name: name},
+			Mesh:           new(geo.Mesh),
+		},
+		nodes: make(map[int]uint32),
+	}
+	d.meshes = append(d.meshes, mesh)
+	return mesh
+}
+
+func (d *objDecoder) decodeVertex(fields []string) error {
+	if len(fields) < 3 {
+		return fmt.Errorf("vertex has %d coordinates", len(fields))
+	}
+	var p geo.Point3D
+	for i := range p {
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return err
+		}
+		p[i] = float32(f)
+	}
+	d.positions = append(d.positions, p)
+	return nil
+}
+
+func (d *objDecoder) decodeTextureCoord(fields []string) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("texture coordinate is empty")
+	}
+	var uv go3mf.TextureCoord
+	for i := 0; i < len(fields) && i < 2; i++ {
+		f, err := strconv.ParseFloat(fields[i], 32)
+		if err != nil {
+			return err
+		}
+		uv[i] = float32(f)
+	}
+	d.uvs = append(d.uvs, uv)
+	return nil
+}
+
+// faceVertex is a vertex of a face, uv is -1 when it has no texture coordinate.
+type faceVertex struct {
+	position, uv int
+}
+
+func (d *objDecoder) decodeFace(fields []string) error {
+	if len(fields) < 3 {
+		return fmt.Errorf("face has %d vertices", len(fields))
+	}
+	vertices := make([]faceVertex, len(fields))
+	for i, field := range fields {
+		refs := strings.Split(field, "/")
+		var err error
+		if vertices[i].position, err = resolveIndex(refs[0], len(d.positions)); err != nil {
+			return err
+		}
+		vertices[i].uv = -1
+		if len(refs) > 1 && refs[1] != "" {
+			if vertices[i].uv, err = resolveIndex(refs[1], len(d.uvs)); err != nil {
+				return err
+			}
+		}
+	}
+	if d.mesh == nil {
+		d.mesh = d.newMesh("")
+	}
+	for i := 1; i < len(vertices)-1; i++ {
+		d.addFace(vertices[0], vertices[i], vertices[i+1])
+	}
+	return nil
+}
+
+// resolveIndex converts a one-based or a negative relative index to a zero-based index.
+func resolveIndex(s string, count int) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, err
+	}
+	if i < 0 {
+		i += count
+	} else {
+		i--
+	}
+	if i < 0 || i >= count {
+		return 0, fmt.Errorf("index %s out of range", s)
+	}
+	return i, nil
+}
+
+func (d *objDecoder) addFace(v1, v2, v3 faceVertex) {
+	n1, n2, n3 := d.node(v1.position), d.node(v2.position), d.node(v3.position)
+	if n1 == n2 || n1 == n3 || n2 == n3 {
+		return
+	}
+	face := d.mesh.resource.Mesh.AddFace(n1, n2, n3)
+	d.faces++
+	if d.material == nil {
+		return
+	}
+	if d.material.texture != "" && v1.uv >= 0 && v2.uv >= 0 && v3.uv >= 0 {
+		tex := d.textures[d.material.texture]
+		face.Resource = tex.group.ID
+		face.ResourceIndices = [3]uint32{d.textureCoord(tex, v1.uv), d.textureCoord(tex, v2.uv), d.textureCoord(tex, v3.uv)}
+		return
+	}
+	id, index := d.materialIndex(d.material)
+	face.Resource = id
+	face.ResourceIndices = [3]uint32{index, index, index}
+}
+
+func (d *objDecoder) node(position int) uint32 {
+	mesh := d.mesh
+	if index, ok := mesh.nodes[position]; ok {
+		return index
+	}
+	index := uint32(len(mesh.resource.Mesh.Nodes))
+	mesh.resource.Mesh.Nodes = append(mesh.resource.Mesh.Nodes, d.positions[position])
+	mesh.nodes[position] = index
+	return index
+}
+
+func (d *objDecoder) textureCoord(tex *texture, uv int) uint32 {
+	if index, ok := tex.coords[uv]; ok {
+		return index
+	}
+	index := uint32(len(tex.group.Coords))
+	tex.group.Coords = append(tex.group.Coords, d.uvs[uv])
+	tex.coords[uv] = index
+	return index
+}
+
+// findMaterial returns the material from the loaded libraries
+// or a new default one if the libraries do not define it.
+func (d *objDecoder) findMaterial(name string) *material {
+	if mat, ok := d.library[name]; ok {
+		return mat
+	}
+	mat := newMaterial(name)
+	d.library[name] = mat
+	return mat
+}
+
+// materialIndex returns the ID of the materials resource and the index of mat in it,
+// the resource and the material are added the first time they are used.
+func (d *objDecoder) materialIndex(mat *material) (uint32, uint32) {
+	if d.ColorGroup {
+		if d.colors == nil {
+			d.colors = &go3mf.ColorGroupResource{ID: d.model.UnusedID(), ModelPath: d.model.Path}
+			d.model.Resources = append(d.model.Resources, d.colors)
+		}
+		if !mat.used {
+			mat.index, mat.used = uint32(len(d.colors.Colors)), true
+			d.colors.Colors = append(d.colors.Colors, mat.color)
+		}
+		return d.colors.ID, mat.index
+	}
+	if d.materials == nil {
+		d.materials = &go3mf.BaseMaterialsResource{ID: d.model.UnusedID(), ModelPath: d.model.Path}
+		d.model.Resources = append(d.model.Resources, d.materials)
+	}
+	if !mat.used {
+		mat.index, mat.used = uint32(len(d.materials.Materials)), true
+		d.materials.Materials = append(d.materials.Materials, go3mf.BaseMaterial{Name: mat.name, Color: mat.color})
+	}
+	return d.materials.ID, mat.index
+}
+
+func (d *objDecoder) loadLibraries(names []string) error {
+	if d.Open == nil {
+		return nil
+	}
+	for _, name := range names {
+		r, err := d.Open(name)
+		if err != nil {
+			return err
+		}
+		mats, err := decodeLibrary(r)
+		r.Close()
+		if err != nil {
+			return fmt.Errorf("%s: %v", name, err)
+		}
+		for _, mat := range mats {
+			if mat.texture != "" {
+				if err = d.loadTexture(mat.texture); err != nil {
+					return err
+				}
+			}
+			d.library[mat.name] = mat
+		}
+	}
+	return nil
+}
+
+// loadTexture adds the texture resources and its image as an attachment.
+func (d *objDecoder) loadTexture(name string) error {
+	if _, ok := d.textures[name]; ok {
+		return nil
+	}
+	r, err := d.Open(name)
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return err
+	}
+	base := path.Base(strings.Replace(name, "\\", "/", -1))
+	res := &go3mf.Texture2DResource{
+		ID:          d.model.UnusedID(),
+		ModelPath:   d.model.Path,
+		Path:        d.texturePath(base),
+		ContentType: go3mf.TextureTypePNG,
+	}
+	if ext := strings.ToLower(path.Ext(base)); ext == ".jpg" || ext == ".jpeg" {
+		res.ContentType = go3mf.TextureTypeJPEG
+	}
+	d.model.Resources = append(d.model.Resources, res)
+	group := &go3mf.Texture2DGroupResource{ID: d.model.UnusedID(), ModelPath: d.model.Path, TextureID: res.ID}
+	d.model.Resources = append(d.model.Resources, group)
+	d.model.Attachments = append(d.model.Attachments, &go3mf.Attachment{
+		Stream:           bytes.NewReader(b),
+		RelationshipType: relTypeTexture3D,
+		Path:             res.Path,
+	})
+	d.textures[name] = &texture{group: group, coords: make(map[int]uint32)}
+	return nil
+}
+
+// texturePath returns the part name of a texture, adding a numeric suffix
+// when another attachment already uses the name, such as textures with the same file name
+// in different directories.
+func (d *objDecoder) texturePath(base string) string {
+	ext := path.Ext(base)
+	name := "/3D/Texture/" + base
+	for i := 1; d.hasAttachment(name); i++ {
+		name = "/3D/Texture/" + strings.TrimSuffix(base, ext) + "_" + strconv.Itoa(i) + ext
+	}
+	return name
+}
+
+// hasAttachment returns true if an attachment has the part name,
+// which are compared case-insensitively as in OPC.
+func (d *objDecoder) hasAttachment(name string) bool {
+	for _, a := range d.model.Attachments {
+		if strings.EqualFold(a.Path, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// defaultColor is the diffuse color of a material without Kd.
+var defaultColor = color.RGBA{R: 204, G: 204, B: 204, A: 255}
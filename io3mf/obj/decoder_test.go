@@ -0,0 +1,187 @@
+package obj
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestNewDecoder(t *testing.T) {
+	tests := []struct {
+		name string
+		r    io.Reader
+		want *Decoder
+	}{
+		{"base", new(bytes.Buffer), &Decoder{r: new(bytes.Buffer)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDecoder(tt.r); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewDecoder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// opener returns a Decoder.Open function that reads the files from a map.
+func opener(files map[string]string) func(string) (io.ReadCloser, error) {
+	return func(name string) (io.ReadCloser, error) {
+		s, ok := files[name]
+		if !ok {
+			return nil, errors.New("file not found")
+		}
+		return ioutil.NopCloser(strings.NewReader(s)), nil
+	}
+}
+
+func newMesh(id uint32, name string, nodes []geo.Point3D, faces ...geo.Face) *go3mf.MeshResource {
+	m := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: id, ModelPath: "/3D/3dmodel.model", Name: name}, Mesh: new(geo.Mesh)}
+	m.Mesh.Nodes = nodes
+	m.Mesh.Faces = faces
+	return m
+}
+
+func face(v1, v2, v3, res, p1, p2, p3 uint32) geo.Face {
+	return geo.Face{NodeIndices: [3]uint32{v1, v2, v3}, Resource: res, ResourceIndices: [3]uint32{p1, p2, p3}}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	const cube = `# two faces of a cube
+mtllib cube.mtl
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+v 0 0 1
+vt 0 0
+vt 1 0
+vt 1 1
+vt 0 1
+o bottom
+usemtl red
+f 1 4 3 2
+o side
+usemtl wood
+f 1/1 2/2 5/3
+usemtl blue
+f -5 -4 -1
+f 1 2 2
+`
+	const library = `newmtl red
+Kd 1 0 0
+newmtl blue
+Kd 0 0 1
+d 0.5
+newmtl wood
+Kd 0.5 0.5 0.5
+map_Kd -s 1 1 1 textures\wood.png
+`
+	files := map[string]string{"cube.mtl": library, `textures\wood.png`: "png"}
+	square := []geo.Point3D{{0, 0, 0}, {0, 1, 0}, {1, 1, 0}, {1, 0, 0}}
+	side := []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 0, 1}}
+	red, blue := color.RGBA{255, 0, 0, 255}, color.RGBA{0, 0, 255, 128}
+	tests := []struct {
+		name       string
+		obj        string
+		open       func(string) (io.ReadCloser, error)
+		colorGroup bool
+		want       *go3mf.Model
+		wantErr    bool
+	}{
+		{"empty", "", nil, false, &go3mf.Model{Path: "/3D/3dmodel.model"}, false},
+		{"noObject", "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\n", nil, false, &go3mf.Model{Path: "/3D/3dmodel.model", Resources: []go3mf.Resource{
+			newMesh(1, "", []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}, face(0, 1, 2, 0, 0, 0, 0)),
+		}}, false},
+		{"noLibrary", cube, nil, false, &go3mf.Model{Path: "/3D/3dmodel.model", Resources: []go3mf.Resource{
+			&go3mf.BaseMaterialsResource{ID: 1, ModelPath: "/3D/3dmodel.model", Materials: []go3mf.BaseMaterial{
+				{Name: "red", Color: defaultColor}, {Name: "wood", Color: defaultColor}, {Name: "blue", Color: defaultColor},
+			}},
+			newMesh(2, "bottom", square, face(0, 1, 2, 1, 0, 0, 0), face(0, 2, 3, 1, 0, 0, 0)),
+			newMesh(3, "side", side, face(0, 1, 2, 1, 1, 1, 1), face(0, 1, 2, 1, 2, 2, 2)),
+		}}, false},
+		{"baseMaterials", cube, opener(files), false, &go3mf.Model{Path: "/3D/3dmodel.model", Resources: []go3mf.Resource{
+			&go3mf.Texture2DResource{ID: 1, ModelPath: "/3D/3dmodel.model", Path: "/3D/Texture/wood.png", ContentType: go3mf.TextureTypePNG},
+			&go3mf.Texture2DGroupResource{ID: 2, ModelPath: "/3D/3dmodel.model", TextureID: 1, Coords: []go3mf.TextureCoord{{0, 0}, {1, 0}, {1, 1}}},
+			&go3mf.BaseMaterialsResource{ID: 3, ModelPath: "/3D/3dmodel.model", Materials: []go3mf.BaseMaterial{
+				{Name: "red", Color: red}, {Name: "blue", Color: blue},
+			}},
+			newMesh(4, "bottom", square, face(0, 1, 2, 3, 0, 0, 0), face(0, 2, 3, 3, 0, 0, 0)),
+			newMesh(5, "side", side, face(0, 1, 2, 2, 0, 1, 2), face(0, 1, 2, 3, 1, 1, 1)),
+		}, Attachments: []*go3mf.Attachment{
+			{Stream: bytes.NewReader([]byte("png")), RelationshipType: relTypeTexture3D, Path: "/3D/Texture/wood.png"},
+		}}, false},
+		{"colorGroup", "mtllib cube.mtl\nv 0 0 0\nv 1 0 0\nv 0 1 0\nusemtl blue\nf 1 2 3\nusemtl red\nf 3 2 1\nusemtl blue\nf 2 3 1\n", opener(files), true,
+			&go3mf.Model{Path: "/3D/3dmodel.model", Resources: []go3mf.Resource{
+				&go3mf.Texture2DResource{ID: 1, ModelPath: "/3D/3dmodel.model", Path: "/3D/Texture/wood.png", ContentType: go3mf.TextureTypePNG},
+				&go3mf.Texture2DGroupResource{ID: 2, ModelPath: "/3D/3dmodel.model", TextureID: 1},
+				&go3mf.ColorGroupResource{ID: 3, ModelPath: "/3D/3dmodel.model", Colors: []color.RGBA{blue, red}},
+				newMesh(4, "", []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}, face(0, 1, 2, 3, 0, 0, 0), face(2, 1, 0, 3, 1, 1, 1), face(1, 2, 0, 3, 0, 0, 0)),
+			}, Attachments: []*go3mf.Attachment{
+				{Stream: bytes.NewReader([]byte("png")), RelationshipType: relTypeTexture3D, Path: "/3D/Texture/wood.png"},
+			}}, false},
+		{"sameTextureName", "mtllib two.mtl\n", opener(map[string]string{
+			"two.mtl": "newmtl oak\nmap_Kd oak/wood.png\nnewmtl pine\nmap_Kd pine/WOOD.png\n", "oak/wood.png": "oak", "pine/WOOD.png": "pine",
+		}), false, &go3mf.Model{Path: "/3D/3dmodel.model", Resources: []go3mf.Resource{
+			&go3mf.Texture2DResource{ID: 1, ModelPath: "/3D/3dmodel.model", Path: "/3D/Texture/wood.png", ContentType: go3mf.TextureTypePNG},
+			&go3mf.Texture2DGroupResource{ID: 2, ModelPath: "/3D/3dmodel.model", TextureID: 1},
+			&go3mf.Texture2DResource{ID: 3, ModelPath: "/3D/3dmodel.model", Path: "/3D/Texture/WOOD_1.png", ContentType: go3mf.TextureTypePNG},
+			&go3mf.Texture2DGroupResource{ID: 4, ModelPath: "/3D/3dmodel.model", TextureID: 3},
+		}, Attachments: []*go3mf.Attachment{
+			{Stream: bytes.NewReader([]byte("oak")), RelationshipType: relTypeTexture3D, Path: "/3D/Texture/wood.png"},
+			{Stream: bytes.NewReader([]byte("pine")), RelationshipType: relTypeTexture3D, Path: "/3D/Texture/WOOD_1.png"},
+		}}, false},
+		{"missingLibrary", "mtllib other.mtl\n", opener(files), false, nil, true},
+		{"missingTexture", "mtllib cube.mtl\n", opener(map[string]string{"cube.mtl": library}), false, nil, true},
+		{"invalidLibrary", "mtllib cube.mtl\n", opener(map[string]string{"cube.mtl": "newmtl red\nKd 1 a 0\n"}), false, nil, true},
+		{"invalidVertex", "v 0 0\n", nil, false, nil, true},
+		{"invalidVertexNumber", "v 0 a 0\n", nil, false, nil, true},
+		{"invalidTextureCoord", "vt a\n", nil, false, nil, true},
+		{"invalidFace", "v 0 0 0\nf 1 1\n", nil, false, nil, true},
+		{"invalidFaceIndex", "v 0 0 0\nf 1 a 1\n", nil, false, nil, true},
+		{"outOfRange", "v 0 0 0\nv 0 0 0\nf 1 2 3\n", nil, false, nil, true},
+		{"outOfRangeCoord", "v 0 0 0\nv 0 0 0\nv 0 0 0\nf 1/1 2/1 3/1\n", nil, false, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(strings.NewReader(tt.obj))
+			d.Open = tt.open
+			d.ColorGroup = tt.colorGroup
+			got := &go3mf.Model{Path: "/3D/3dmodel.model"}
+			err := d.Decode(got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Decoder.Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			deep.CompareUnexportedFields = true
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_DecodeContext_Cancel(t *testing.T) {
+	obj := new(bytes.Buffer)
+	obj.WriteString("v 0 0 0\nv 1 0 0\nv 0 1 0\n")
+	for i := 0; i < checkEveryFaces+2; i++ {
+		obj.WriteString("f 1 2 3\n")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewDecoder(obj).DecodeContext(ctx, new(go3mf.Model)); err != context.Canceled {
+		t.Errorf("Decoder.DecodeContext() error = %v, want %v", err, context.Canceled)
+	}
+}
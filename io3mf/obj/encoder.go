@@ -0,0 +1,50 @@
+package obj
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+
+	"github.com/qmuntal/go3mf/geo"
+)
+
+// Encoder can encode a mesh as a Wavefront OBJ file.
+type Encoder struct {
+	w io.Writer
+}
+
+// NewEncoder creates a new encoder.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: w,
+	}
+}
+
+// Encode encodes the nodes and the faces of a mesh to the writer.
+func (e *Encoder) Encode(m *geo.Mesh) error {
+	w := bufio.NewWriter(e.w)
+	buf := make([]byte, 0, 64)
+	for _, n := range m.Nodes {
+		buf = append(buf[:0], 'v')
+		for _, c := range n {
+			buf = append(buf, ' ')
+			buf = strconv.AppendFloat(buf, float64(c), 'f', -1, 32)
+		}
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	for _, f := range m.Faces {
+		buf = append(buf[:0], 'f')
+		for _, i := range f.NodeIndices {
+			buf = append(buf, ' ')
+			buf = strconv.AppendUint(buf, uint64(i)+1, 10)
+		}
+		buf = append(buf, '\n')
+		if _, err := w.Write(buf); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
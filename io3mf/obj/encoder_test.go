@@ -0,0 +1,73 @@
+package obj
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestNewEncoder(t *testing.T) {
+	w := new(bytes.Buffer)
+	want := &Encoder{w: w}
+	if got := NewEncoder(w); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewEncoder() = %v, want %v", got, want)
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("")
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	triangle := new(geo.Mesh)
+	triangle.Nodes = []geo.Point3D{{0, 0, 0}, {10.5, 0, 0}, {0, -2.25, 1e-3}}
+	triangle.AddFace(0, 1, 2)
+	tests := []struct {
+		name    string
+		m       *geo.Mesh
+		want    string
+		wantErr bool
+	}{
+		{"empty", new(geo.Mesh), "", false},
+		{"triangle", triangle, "v 0 0 0\nv 10.5 0 0\nv 0 -2.25 0.001\nf 1 2 3\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			if err := NewEncoder(w).Encode(tt.m); (err != nil) != tt.wantErr {
+				t.Errorf("Encoder.Encode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("Encoder.Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if err := NewEncoder(errWriter{}).Encode(triangle); err == nil {
+		t.Error("Encoder.Encode() expected error")
+	}
+}
+
+func TestEncoder_roundTrip(t *testing.T) {
+	mesh := new(geo.Mesh)
+	mesh.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0.5}}
+	mesh.AddFace(0, 1, 2)
+	mesh.AddFace(0, 2, 3)
+	buf := new(bytes.Buffer)
+	if err := NewEncoder(buf).Encode(mesh); err != nil {
+		t.Fatalf("Encoder.Encode() error = %v", err)
+	}
+	model := new(go3mf.Model)
+	if err := NewDecoder(buf).Decode(model); err != nil {
+		t.Fatalf("Decoder.Decode() error = %v", err)
+	}
+	if got := model.Resources[0].(*go3mf.MeshResource).Mesh; !reflect.DeepEqual(got, mesh) {
+		t.Errorf("Encoder.Encode() = %v, want %v", got, mesh)
+	}
+}
@@ -0,0 +1,101 @@
+package obj
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// material is a material defined in a MTL library.
+type material struct {
+	name    string
+	color   color.RGBA
+	texture string
+	index   uint32 // index in the materials resource, only valid if used.
+	used    bool
+}
+
+func newMaterial(name string) *material {
+	return &material{name: name, color: defaultColor}
+}
+
+// decodeLibrary decodes the materials of a MTL library.
+// Only the diffuse color (Kd), the opacity (d or Tr) and the diffuse map (map_Kd) are taken into account.
+func decodeLibrary(r io.Reader) ([]*material, error) {
+	var (
+		mats []*material
+		mat  *material
+	)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if fields[0] == "newmtl" {
+			mat = newMaterial(strings.Join(fields[1:], " "))
+			mats = append(mats, mat)
+			continue
+		}
+		if mat == nil {
+			continue
+		}
+		var err error
+		switch fields[0] {
+		case "Kd":
+			err = decodeDiffuse(mat, fields[1:])
+		case "d":
+			mat.color.A, err = decodeUnit(fields[1:], false)
+		case "Tr":
+			mat.color.A, err = decodeUnit(fields[1:], true)
+		case "map_Kd":
+			if len(fields) > 1 {
+				// The file name is the last field, the previous ones are options.
+				mat.texture = fields[len(fields)-1]
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	return mats, scanner.Err()
+}
+
+func decodeDiffuse(mat *material, fields []string) error {
+	if len(fields) < 3 {
+		return fmt.Errorf("diffuse color has %d components", len(fields))
+	}
+	var err error
+	if mat.color.R, err = decodeUnit(fields[0:1], false); err != nil {
+		return err
+	}
+	if mat.color.G, err = decodeUnit(fields[1:2], false); err != nil {
+		return err
+	}
+	mat.color.B, err = decodeUnit(fields[2:3], false)
+	return err
+}
+
+// decodeUnit converts a value in the range [0, 1] to a color component,
+// which is inverted if invert is true.
+func decodeUnit(fields []string, invert bool) (uint8, error) {
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("missing value")
+	}
+	f, err := strconv.ParseFloat(fields[0], 32)
+	if err != nil {
+		return 0, err
+	}
+	if invert {
+		f = 1 - f
+	}
+	if f < 0 {
+		f = 0
+	} else if f > 1 {
+		f = 1
+	}
+	return uint8(f*255 + 0.5), nil
+}
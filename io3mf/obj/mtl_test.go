@@ -0,0 +1,43 @@
+package obj
+
+import (
+	"image/color"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func Test_decodeLibrary(t *testing.T) {
+	tests := []struct {
+		name    string
+		mtl     string
+		want    []*material
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"orphan", "Kd 1 1 1\n", nil, false},
+		{"default", "newmtl a b\n", []*material{{name: "a b", color: defaultColor}}, false},
+		{"full", "# comment\nnewmtl red\nKa 0 0 0\nKd 1 0 0.2\nd 0.2\nnewmtl tex\nTr 0.2\nKd 2 -1 0\nmap_Kd tex.jpg\n", []*material{
+			{name: "red", color: color.RGBA{255, 0, 51, 51}},
+			{name: "tex", color: color.RGBA{255, 0, 0, 204}, texture: "tex.jpg"},
+		}, false},
+		{"shortKd", "newmtl red\nKd 1 0\n", nil, true},
+		{"invalidKd", "newmtl red\nKd 1 0 a\n", nil, true},
+		{"invalidD", "newmtl red\nd a\n", nil, true},
+		{"missingD", "newmtl red\nd\n", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeLibrary(strings.NewReader(tt.mtl))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeLibrary() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			deep.CompareUnexportedFields = true
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("decodeLibrary() = %v", diff)
+			}
+		})
+	}
+}
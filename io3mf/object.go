@@ -10,10 +10,14 @@ type objectDecoder struct {
 	emptyDecoder
 	progressCount int
 	resource      go3mf.ObjectResource
+	// object points to the object resource that is finally added to the model,
+	// which is a copy of resource once the mesh or the components are found.
+	object *go3mf.ObjectResource
 }
 
 func (d *objectDecoder) Open() {
 	d.resource.ModelPath = d.file.path
+	d.object = &d.resource
 }
 
 func (d *objectDecoder) Close() bool {
@@ -36,6 +40,14 @@ func (d *objectDecoder) Attributes(attrs []xml.Attr) bool {
 			ok = d.parseSliceAttr(a)
 		case "":
 			ok = d.parseCoreAttr(a)
+		default:
+			if od, isExt := objectExtension(a.Name.Space); isExt {
+				if err := od.DecodeObjectAttr(&d.resource, a); err != nil {
+					ok = d.file.parser.GenericError(true, err.Error())
+				}
+			} else {
+				d.file.unknownAttr(&d.resource.Unknown, a)
+			}
 		}
 		if !ok {
 			break
@@ -47,15 +59,29 @@ func (d *objectDecoder) Attributes(attrs []xml.Attr) bool {
 func (d *objectDecoder) Child(name xml.Name) (child nodeDecoder) {
 	if name.Space == nsCoreSpec {
 		if name.Local == attrMesh {
-			child = &meshDecoder{resource: go3mf.MeshResource{ObjectResource: d.resource}}
+			mesh := &meshDecoder{resource: go3mf.MeshResource{ObjectResource: d.resource}}
+			d.object, child = &mesh.resource.ObjectResource, mesh
 		} else if name.Local == attrComponents {
 			if d.resource.DefaultPropertyID != 0 {
 				d.file.parser.GenericError(true, "default PID is not supported for component objects")
 			}
-			child = &componentsDecoder{resource: go3mf.ComponentsResource{ObjectResource: d.resource}}
+			components := &componentsDecoder{resource: go3mf.ComponentsResource{ObjectResource: d.resource}}
+			d.object, child = &components.resource.ObjectResource, components
 		} else if name.Local == attrMetadataGroup {
 			child = &metadataGroupDecoder{metadatas: &d.resource.Metadata}
 		}
+	} else if name.Space == nsDisplacementSpec && name.Local == attrDisplacementMesh {
+		mesh := &displacementMeshDecoder{resource: go3mf.DisplacementMeshResource{ObjectResource: d.resource}}
+		d.object, child = &mesh.resource.ObjectResource, mesh
+	} else if name.Space == nsBooleanSpec && name.Local == attrBooleanShape {
+		shape := &booleanShapeDecoder{resource: go3mf.BooleanShapeResource{ObjectResource: d.resource}}
+		d.object, child = &shape.resource.ObjectResource, shape
+	} else if name.Space == nsAlternativesSpec && name.Local == attrAlternatives {
+		child = &alternativesDecoder{object: d.object}
+	} else if od, ok := objectExtension(name.Space); ok {
+		child = d.extensionElement(od, name)
+	} else {
+		child = d.file.unknownElement(&d.object.Unknown, name)
 	}
 	return
 }
@@ -186,3 +212,63 @@ func (d *componentDecoder) addComponent(component *go3mf.Component, path string,
 	}
 	return ok
 }
+
+func (w *modelWriter) objectAttrs(o *go3mf.ObjectResource) []xml.Attr {
+	attrs := []xml.Attr{
+		w.attr(attrID, formatUint32(o.ID)),
+		w.attr(attrType, o.ObjectType.String()),
+	}
+	if o.Name != "" {
+		attrs = append(attrs, w.attr(attrName, o.Name))
+	}
+	if o.PartNumber != "" {
+		attrs = append(attrs, w.attr(attrPartNumber, o.PartNumber))
+	}
+	if o.Thumbnail != "" {
+		attrs = append(attrs, w.attr(attrThumbnail, o.Thumbnail))
+	}
+	if o.DefaultPropertyID != 0 {
+		attrs = append(attrs, w.attr(attrPID, formatUint32(o.DefaultPropertyID)))
+	}
+	if o.DefaultPropertyIndex != 0 {
+		attrs = append(attrs, w.attr(attrPIndex, formatUint32(o.DefaultPropertyIndex)))
+	}
+	if o.UUID != "" {
+		attrs = append(attrs, w.attrNS(nsProductionSpec, attrProdUUID, o.UUID))
+	}
+	if o.SliceStackID != 0 {
+		attrs = append(attrs, w.attrNS(nsSliceSpec, attrSliceRefID, formatUint32(o.SliceStackID)))
+		attrs = append(attrs, w.attrNS(nsSliceSpec, attrMeshRes, o.SliceResoultion.String()))
+	}
+	attrs = append(attrs, w.unknownAttrs(w.extensions[o].Attr)...)
+	return append(attrs, w.unknownAttrs(o.Unknown.Attr)...)
+}
+
+func (w *modelWriter) writeComponentsObject(r *go3mf.ComponentsResource) {
+	w.start(attrObject, w.objectAttrs(&r.ObjectResource)...)
+	w.writeMetadataGroup(r.Metadata)
+	w.start(attrComponents)
+	for _, c := range r.Components {
+		w.writeComponent(c)
+	}
+	w.end(attrComponents)
+	w.writeAlternatives(r.Alternatives)
+	w.writeUnknownTokens(w.extensions[&r.ObjectResource])
+	w.writeUnknownTokens(r.Unknown)
+	w.end(attrObject)
+}
+
+func (w *modelWriter) writeComponent(c *go3mf.Component) {
+	path, id := c.Object.Identify()
+	attrs := []xml.Attr{w.attr(attrObjectID, formatUint32(id))}
+	if c.HasTransform() {
+		attrs = append(attrs, w.attr(attrTransform, formatMatrix(c.Transform)))
+	}
+	if c.UUID != "" {
+		attrs = append(attrs, w.attrNS(nsProductionSpec, attrProdUUID, c.UUID))
+	}
+	if w.isExternal(c.Object) {
+		attrs = append(attrs, w.attrNS(nsProductionSpec, attrPath, path))
+	}
+	w.element(attrComponent, attrs...)
+}
@@ -1,6 +1,7 @@
 package io3mf
 
 import (
+	"fmt"
 	"io"
 
 	"github.com/qmuntal/opc"
@@ -89,3 +90,43 @@ func findOPCFileURIFromRel(relType string, rels []*opc.Relationship) string {
 	}
 	return ""
 }
+
+func resolveRelationship(source, target string) string {
+	return opc.ResolveRelationship(source, target)
+}
+
+type opcWriter struct {
+	w *opc.Writer
+}
+
+func newOpcWriter(w io.Writer) *opcWriter {
+	return &opcWriter{opc.NewWriter(w)}
+}
+
+func (o *opcWriter) Create(name, contentType string, rels []relationship) (io.Writer, error) {
+	part := &opc.Part{Name: opc.NormalizePartName(name), ContentType: contentType, Relationships: newOPCRelationships(rels)}
+	return o.w.CreatePart(part, opc.CompressionNormal)
+}
+
+func (o *opcWriter) AddRelationship(r relationship) {
+	o.w.Relationships = append(o.w.Relationships, newOPCRelationship(r, len(o.w.Relationships)))
+}
+
+func (o *opcWriter) Close() error {
+	return o.w.Close()
+}
+
+func newOPCRelationships(rels []relationship) []*opc.Relationship {
+	if len(rels) == 0 {
+		return nil
+	}
+	or := make([]*opc.Relationship, len(rels))
+	for i, r := range rels {
+		or[i] = newOPCRelationship(r, i)
+	}
+	return or
+}
+
+func newOPCRelationship(r relationship, index int) *opc.Relationship {
+	return &opc.Relationship{ID: fmt.Sprintf("rel%d", index), Type: r.Type(), TargetURI: r.TargetURI()}
+}
@@ -0,0 +1,337 @@
+package ply
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+var checkEveryFaces = 1000
+
+// Decoder can decode a PLY to a mesh.
+// It supports the ascii, binary little-endian and binary big-endian formats.
+//
+// The vertex and face elements are decoded as a MeshResource, polygons are triangulated as a fan
+// and any other element is skipped.
+// When the vertices have red, green and blue properties, and optionally alpha,
+// their colors are added to a ColorGroupResource referenced by the faces.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder creates a new decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: r,
+	}
+}
+
+// Decode creates a mesh from a read stream.
+func (d *Decoder) Decode(m *go3mf.Model) error {
+	return d.DecodeContext(context.Background(), m)
+}
+
+// DecodeContext creates a mesh from a read stream.
+func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
+	b := bufio.NewReader(d.r)
+	h, err := decodeHeader(b)
+	if err != nil {
+		return err
+	}
+	dec := plyDecoder{model: m, mesh: new(geo.Mesh)}
+	switch h.encoding {
+	case ASCII:
+		s := bufio.NewScanner(b)
+		s.Split(bufio.ScanWords)
+		dec.r = &asciiReader{s: s}
+	case BinaryBigEndian:
+		dec.r = &binaryReader{r: b, order: binary.BigEndian}
+	default:
+		dec.r = &binaryReader{r: b, order: binary.LittleEndian}
+	}
+	for _, e := range h.elements {
+		switch e.name {
+		case "vertex":
+			err = dec.decodeVertices(e)
+		case "face":
+			err = dec.decodeFaces(ctx, e)
+		default:
+			err = dec.skip(e)
+		}
+		if err != nil {
+			if err == ctx.Err() {
+				return err
+			}
+			return fmt.Errorf("go3mf: ply %s element: %v", e.name, err)
+		}
+	}
+	m.Resources = append(m.Resources, &go3mf.MeshResource{
+		ObjectResource: go3mf.ObjectResource{
+			ModelPath: m.Path,
+			ID:        m.UnusedID(),
+		},
+		Mesh: dec.mesh,
+	})
+	return nil
+}
+
+type plyDecoder struct {
+	r      valueReader
+	model  *go3mf.Model
+	mesh   *geo.Mesh
+	colors *go3mf.ColorGroupResource
+	// vertexColors is the index in colors of the color of each vertex.
+	vertexColors []uint32
+}
+
+// colorIndex returns the color component of a property name, or -1 if it is not a color.
+func colorIndex(name string) int {
+	switch name {
+	case "red", "r", "diffuse_red":
+		return 0
+	case "green", "g", "diffuse_green":
+		return 1
+	case "blue", "b", "diffuse_blue":
+		return 2
+	case "alpha", "a", "diffuse_alpha":
+		return 3
+	}
+	return -1
+}
+
+func (d *plyDecoder) decodeVertices(e element) error {
+	coords, components := [3]int{-1, -1, -1}, [4]int{-1, -1, -1, -1}
+	for i, p := range e.properties {
+		if p.countType != 0 {
+			continue
+		}
+		switch p.name {
+		case "x":
+			coords[0] = i
+		case "y":
+			coords[1] = i
+		case "z":
+			coords[2] = i
+		default:
+			if c := colorIndex(p.name); c >= 0 {
+				components[c] = i
+			}
+		}
+	}
+	if coords[0] < 0 || coords[1] < 0 || coords[2] < 0 {
+		return errors.New("missing coordinate properties")
+	}
+	hasColor := components[0] >= 0 && components[1] >= 0 && components[2] >= 0
+	var colors map[color.RGBA]uint32
+	if hasColor {
+		d.colors = &go3mf.ColorGroupResource{ID: d.model.UnusedID(), ModelPath: d.model.Path}
+		d.model.Resources = append(d.model.Resources, d.colors)
+		colors = make(map[color.RGBA]uint32)
+	}
+	values := make([]float64, len(e.properties))
+	for i := 0; i < e.count; i++ {
+		if err := d.readValues(e, values); err != nil {
+			return err
+		}
+		d.mesh.Nodes = append(d.mesh.Nodes, geo.Point3D{float32(values[coords[0]]), float32(values[coords[1]]), float32(values[coords[2]])})
+		if !hasColor {
+			continue
+		}
+		c := color.RGBA{A: 255}
+		for j, ptr := range [4]*uint8{&c.R, &c.G, &c.B, &c.A} {
+			if components[j] >= 0 {
+				*ptr = colorComponent(e.properties[components[j]].dataType, values[components[j]])
+			}
+		}
+		index, ok := colors[c]
+		if !ok {
+			index = uint32(len(d.colors.Colors))
+			d.colors.Colors = append(d.colors.Colors, c)
+			colors[c] = index
+		}
+		d.vertexColors = append(d.vertexColors, index)
+	}
+	return nil
+}
+
+// colorComponent converts a color property to a component,
+// floating point values are in the range [0, 1] and integer values in the range [0, 255].
+func colorComponent(t dataType, v float64) uint8 {
+	if t == typeFloat32 || t == typeFloat64 {
+		v = v*255 + 0.5
+	}
+	return uint8(math.Max(0, math.Min(255, v)))
+}
+
+func (d *plyDecoder) decodeFaces(ctx context.Context, e element) error {
+	indices := -1
+	for i, p := range e.properties {
+		if p.countType != 0 && (p.name == "vertex_indices" || p.name == "vertex_index") {
+			indices = i
+		}
+	}
+	if indices < 0 {
+		return errors.New("missing vertex_indices property")
+	}
+	nextFaceCheck := checkEveryFaces
+	nodeCount := uint32(len(d.mesh.Nodes))
+	var polygon []uint32
+	for i := 0; i < e.count; i++ {
+		for j, p := range e.properties {
+			if j != indices {
+				if err := d.skipProperty(p); err != nil {
+					return err
+				}
+				continue
+			}
+			var err error
+			if polygon, err = d.readIndices(p, polygon[:0], nodeCount); err != nil {
+				return err
+			}
+		}
+		for j := 1; j < len(polygon)-1; j++ {
+			d.addFace(polygon[0], polygon[j], polygon[j+1])
+		}
+		if len(d.mesh.Faces) > nextFaceCheck {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default: // Default is must to avoid blocking
+			}
+			nextFaceCheck += checkEveryFaces
+		}
+	}
+	return nil
+}
+
+func (d *plyDecoder) readIndices(p property, polygon []uint32, nodeCount uint32) ([]uint32, error) {
+	count, err := d.r.read(p.countType)
+	if err != nil {
+		return nil, err
+	}
+	for k := 0; k < int(count); k++ {
+		v, err := d.r.read(p.dataType)
+		if err != nil {
+			return nil, err
+		}
+		if v < 0 || v >= float64(nodeCount) {
+			return nil, fmt.Errorf("vertex index %v out of range", v)
+		}
+		polygon = append(polygon, uint32(v))
+	}
+	return polygon, nil
+}
+
+func (d *plyDecoder) addFace(n1, n2, n3 uint32) {
+	if n1 == n2 || n1 == n3 || n2 == n3 {
+		return
+	}
+	face := d.mesh.AddFace(n1, n2, n3)
+	if d.colors != nil {
+		face.Resource = d.colors.ID
+		face.ResourceIndices = [3]uint32{d.vertexColors[n1], d.vertexColors[n2], d.vertexColors[n3]}
+	}
+}
+
+// readValues reads the scalar properties of an element into values, list properties are skipped.
+func (d *plyDecoder) readValues(e element, values []float64) error {
+	for i, p := range e.properties {
+		if p.countType != 0 {
+			if err := d.skipProperty(p); err != nil {
+				return err
+			}
+			continue
+		}
+		v, err := d.r.read(p.dataType)
+		if err != nil {
+			return err
+		}
+		values[i] = v
+	}
+	return nil
+}
+
+func (d *plyDecoder) skip(e element) error {
+	for i := 0; i < e.count; i++ {
+		for _, p := range e.properties {
+			if err := d.skipProperty(p); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (d *plyDecoder) skipProperty(p property) error {
+	count := 1.0
+	if p.countType != 0 {
+		var err error
+		if count, err = d.r.read(p.countType); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < int(count); i++ {
+		if _, err := d.r.read(p.dataType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type valueReader interface {
+	read(t dataType) (float64, error)
+}
+
+type asciiReader struct {
+	s *bufio.Scanner
+}
+
+func (r *asciiReader) read(t dataType) (float64, error) {
+	if !r.s.Scan() {
+		if err := r.s.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.ErrUnexpectedEOF
+	}
+	return strconv.ParseFloat(r.s.Text(), 64)
+}
+
+type binaryReader struct {
+	r     io.Reader
+	order binary.ByteOrder
+	buf   [8]byte
+}
+
+func (r *binaryReader) read(t dataType) (float64, error) {
+	b := r.buf[:t.size()]
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		return 0, err
+	}
+	switch t {
+	case typeInt8:
+		return float64(int8(b[0])), nil
+	case typeUint8:
+		return float64(b[0]), nil
+	case typeInt16:
+		return float64(int16(r.order.Uint16(b))), nil
+	case typeUint16:
+		return float64(r.order.Uint16(b)), nil
+	case typeInt32:
+		return float64(int32(r.order.Uint32(b))), nil
+	case typeUint32:
+		return float64(r.order.Uint32(b)), nil
+	case typeFloat32:
+		return float64(math.Float32frombits(r.order.Uint32(b))), nil
+	default:
+		return math.Float64frombits(r.order.Uint64(b)), nil
+	}
+}
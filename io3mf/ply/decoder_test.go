@@ -0,0 +1,148 @@
+package ply
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image/color"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestNewDecoder(t *testing.T) {
+	tests := []struct {
+		name string
+		r    io.Reader
+		want *Decoder
+	}{
+		{"base", new(bytes.Buffer), &Decoder{r: new(bytes.Buffer)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewDecoder(tt.r); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewDecoder() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// binaryPLY returns a binary square with a red and a blue vertex.
+func binaryPLY(order binary.ByteOrder, format string) []byte {
+	buf := bytes.NewBufferString("ply\nformat " + format + " 1.0\nelement vertex 4\nproperty float x\nproperty float y\nproperty float z\n" +
+		"property uchar red\nproperty uchar green\nproperty uchar blue\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n")
+	for i, v := range [][3]float32{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}} {
+		binary.Write(buf, order, v)
+		if i%2 == 0 {
+			buf.Write([]byte{255, 0, 0})
+		} else {
+			buf.Write([]byte{0, 0, 255})
+		}
+	}
+	buf.WriteByte(4)
+	binary.Write(buf, order, [4]int32{0, 1, 2, 3})
+	return buf.Bytes()
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	const asciiPLY = `ply
+format ascii 1.0
+comment a triangle and a degenerate quad
+element vertex 4
+property float x
+property float y
+property float z
+property list uchar int other
+property float red
+property float green
+property float blue
+property float alpha
+element edge 1
+property int vertex1
+property list uchar int vertex2
+element face 2
+property uchar flags
+property list uchar uint vertex_index
+end_header
+0 0 0 0 1 0 0 1
+1 0 0 2 1 1 1 0 0 1
+0 1 0 0 1 0 0 1
+0 0 1 0 0 0 1 0.5
+0 2 1 3
+1 3 0 1 2
+2 4 0 0 1 1
+`
+	square := new(geo.Mesh)
+	square.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {1, 1, 0}, {0, 1, 0}}
+	square.Faces = []geo.Face{
+		{NodeIndices: [3]uint32{0, 1, 2}, Resource: 1, ResourceIndices: [3]uint32{0, 1, 0}},
+		{NodeIndices: [3]uint32{0, 2, 3}, Resource: 1, ResourceIndices: [3]uint32{0, 0, 1}},
+	}
+	squareColors := &go3mf.ColorGroupResource{ID: 1, ModelPath: "/3D/3dmodel.model", Colors: []color.RGBA{{255, 0, 0, 255}, {0, 0, 255, 255}}}
+	triangle := new(geo.Mesh)
+	triangle.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	triangle.Faces = []geo.Face{{NodeIndices: [3]uint32{0, 1, 2}, Resource: 1, ResourceIndices: [3]uint32{0, 0, 0}}}
+	noColors := new(geo.Mesh)
+	noColors.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}}
+	tests := []struct {
+		name    string
+		r       io.Reader
+		want    []go3mf.Resource
+		wantErr bool
+	}{
+		{"ascii", strings.NewReader(asciiPLY), []go3mf.Resource{
+			&go3mf.ColorGroupResource{ID: 1, ModelPath: "/3D/3dmodel.model", Colors: []color.RGBA{{255, 0, 0, 255}, {0, 0, 255, 128}}},
+			&go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 2, ModelPath: "/3D/3dmodel.model"}, Mesh: triangle},
+		}, false},
+		{"littleEndian", bytes.NewReader(binaryPLY(binary.LittleEndian, "binary_little_endian")), []go3mf.Resource{
+			squareColors, &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 2, ModelPath: "/3D/3dmodel.model"}, Mesh: square},
+		}, false},
+		{"bigEndian", bytes.NewReader(binaryPLY(binary.BigEndian, "binary_big_endian")), []go3mf.Resource{
+			squareColors, &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 2, ModelPath: "/3D/3dmodel.model"}, Mesh: square},
+		}, false},
+		{"noColors", strings.NewReader("ply\nformat ascii 1.0\nelement vertex 3\nproperty double x\nproperty double y\nproperty double z\nend_header\n0 0 0\n1 0 0\n0 1 0\n"), []go3mf.Resource{
+			&go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 1, ModelPath: "/3D/3dmodel.model"}, Mesh: noColors},
+		}, false},
+		{"header", strings.NewReader("ply\n"), nil, true},
+		{"noCoordinates", strings.NewReader("ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nend_header\n0\n"), nil, true},
+		{"noIndices", strings.NewReader("ply\nformat ascii 1.0\nelement face 1\nproperty int vertex_indices\nend_header\n0\n"), nil, true},
+		{"outOfRange", strings.NewReader("ply\nformat ascii 1.0\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n3 0 1 2\n"), nil, true},
+		{"invalidNumber", strings.NewReader("ply\nformat ascii 1.0\nelement vertex 1\nproperty float x\nproperty float y\nproperty float z\nend_header\n0 a 0\n"), nil, true},
+		{"truncatedASCII", strings.NewReader("ply\nformat ascii 1.0\nelement other 1\nproperty list uchar int a\nend_header\n3 0 1"), nil, true},
+		{"truncatedBinary", bytes.NewReader(binaryPLY(binary.LittleEndian, "binary_little_endian")[:200]), nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &go3mf.Model{Path: "/3D/3dmodel.model"}
+			if err := NewDecoder(tt.r).Decode(got); (err != nil) != tt.wantErr {
+				t.Errorf("Decoder.Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			deep.CompareUnexportedFields = true
+			if diff := deep.Equal(got.Resources, tt.want); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestDecoder_DecodeContext_Cancel(t *testing.T) {
+	ply := bytes.NewBufferString("ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n")
+	ply.WriteString("element face 1002\nproperty list uchar int vertex_indices\nend_header\n0 0 0\n1 0 0\n0 1 0\n")
+	for i := 0; i < checkEveryFaces+2; i++ {
+		ply.WriteString("3 0 1 2\n")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := NewDecoder(ply).DecodeContext(ctx, new(go3mf.Model)); err != context.Canceled {
+		t.Errorf("Decoder.DecodeContext() error = %v, want %v", err, context.Canceled)
+	}
+}
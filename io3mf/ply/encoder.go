@@ -0,0 +1,165 @@
+package ply
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"image/color"
+	"io"
+	"math"
+	"strconv"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+// Encoder can encode a mesh as an ascii or a binary PLY file.
+type Encoder struct {
+	// Colors, if not nil, is the color group written as RGBA vertex colors.
+	// Each vertex takes the color that the first face referencing the group assigns to it,
+	// and the vertices without any color are written opaque white.
+	Colors       *go3mf.ColorGroupResource
+	w            io.Writer
+	encodingType EncodingType
+}
+
+// NewEncoder creates a new binary little-endian encoder.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w:            w,
+		encodingType: BinaryLittleEndian,
+	}
+}
+
+// NewEncoderType creates a new encoder of the desired type.
+func NewEncoderType(w io.Writer, encodingType EncodingType) *Encoder {
+	return &Encoder{
+		w:            w,
+		encodingType: encodingType,
+	}
+}
+
+// Encode encodes a mesh to the writer.
+func (e *Encoder) Encode(m *geo.Mesh) error {
+	w := bufio.NewWriter(e.w)
+	colors := e.vertexColors(m)
+	fmt.Fprintf(w, "ply\nformat %s 1.0\ncomment generated by go3mf\nelement vertex %d\n", e.encodingType, len(m.Nodes))
+	w.WriteString("property float x\nproperty float y\nproperty float z\n")
+	if colors != nil {
+		w.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\nproperty uchar alpha\n")
+	}
+	fmt.Fprintf(w, "element face %d\nproperty list uchar uint vertex_indices\nend_header\n", len(m.Faces))
+	var vw valueWriter
+	switch e.encodingType {
+	case ASCII:
+		vw = &asciiWriter{w: w}
+	case BinaryBigEndian:
+		vw = &binaryWriter{w: w, order: binary.BigEndian}
+	default:
+		vw = &binaryWriter{w: w, order: binary.LittleEndian}
+	}
+	for i, n := range m.Nodes {
+		vw.writeFloat32(n[0])
+		vw.writeFloat32(n[1])
+		vw.writeFloat32(n[2])
+		if colors != nil {
+			c := colors[i]
+			vw.writeUint8(c.R)
+			vw.writeUint8(c.G)
+			vw.writeUint8(c.B)
+			vw.writeUint8(c.A)
+		}
+		vw.endLine()
+	}
+	for _, f := range m.Faces {
+		vw.writeUint8(3)
+		vw.writeUint32(f.NodeIndices[0])
+		vw.writeUint32(f.NodeIndices[1])
+		vw.writeUint32(f.NodeIndices[2])
+		vw.endLine()
+	}
+	return w.Flush()
+}
+
+// vertexColors returns the color of each vertex, or nil if there is no color group.
+func (e *Encoder) vertexColors(m *geo.Mesh) []color.RGBA {
+	if e.Colors == nil {
+		return nil
+	}
+	colors := make([]color.RGBA, len(m.Nodes))
+	assigned := make([]bool, len(m.Nodes))
+	for i := range colors {
+		colors[i] = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+	}
+	for _, f := range m.Faces {
+		if f.Resource != e.Colors.ID {
+			continue
+		}
+		for j, n := range f.NodeIndices {
+			if index := f.ResourceIndices[j]; !assigned[n] && int(index) < len(e.Colors.Colors) {
+				colors[n], assigned[n] = e.Colors.Colors[index], true
+			}
+		}
+	}
+	return colors
+}
+
+// valueWriter writes the values of an element, the errors are reported when the writer is flushed.
+type valueWriter interface {
+	writeFloat32(v float32)
+	writeUint8(v uint8)
+	writeUint32(v uint32)
+	endLine()
+}
+
+type asciiWriter struct {
+	w   *bufio.Writer
+	buf []byte
+}
+
+func (w *asciiWriter) sep() {
+	if len(w.buf) > 0 {
+		w.buf = append(w.buf, ' ')
+	}
+}
+
+func (w *asciiWriter) writeFloat32(v float32) {
+	w.sep()
+	w.buf = strconv.AppendFloat(w.buf, float64(v), 'g', -1, 32)
+}
+
+func (w *asciiWriter) writeUint8(v uint8) {
+	w.writeUint32(uint32(v))
+}
+
+func (w *asciiWriter) writeUint32(v uint32) {
+	w.sep()
+	w.buf = strconv.AppendUint(w.buf, uint64(v), 10)
+}
+
+func (w *asciiWriter) endLine() {
+	w.buf = append(w.buf, '\n')
+	w.w.Write(w.buf)
+	w.buf = w.buf[:0]
+}
+
+type binaryWriter struct {
+	w     *bufio.Writer
+	order binary.ByteOrder
+	buf   [4]byte
+}
+
+func (w *binaryWriter) writeFloat32(v float32) {
+	w.writeUint32(math.Float32bits(v))
+}
+
+func (w *binaryWriter) writeUint8(v uint8) {
+	w.w.WriteByte(v)
+}
+
+func (w *binaryWriter) writeUint32(v uint32) {
+	w.order.PutUint32(w.buf[:], v)
+	w.w.Write(w.buf[:])
+}
+
+func (w *binaryWriter) endLine() {}
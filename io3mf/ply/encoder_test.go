@@ -0,0 +1,96 @@
+package ply
+
+import (
+	"bytes"
+	"errors"
+	"image/color"
+	"reflect"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestNewEncoder(t *testing.T) {
+	w := new(bytes.Buffer)
+	if got, want := NewEncoder(w), (&Encoder{w: w, encodingType: BinaryLittleEndian}); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewEncoder() = %v, want %v", got, want)
+	}
+	if got, want := NewEncoderType(w, ASCII), (&Encoder{w: w, encodingType: ASCII}); !reflect.DeepEqual(got, want) {
+		t.Errorf("NewEncoderType() = %v, want %v", got, want)
+	}
+}
+
+type errWriter struct{}
+
+func (errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("")
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	triangle := new(geo.Mesh)
+	triangle.Nodes = []geo.Point3D{{0, 0, 0}, {10.5, 0, 0}, {0, -2, 0}}
+	triangle.AddFace(0, 1, 2)
+	colors := &go3mf.ColorGroupResource{ID: 3, Colors: []color.RGBA{{255, 0, 0, 255}}}
+	tests := []struct {
+		name   string
+		colors *go3mf.ColorGroupResource
+		want   string
+	}{
+		{"noColors", nil, "ply\nformat ascii 1.0\ncomment generated by go3mf\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n" +
+			"element face 1\nproperty list uchar uint vertex_indices\nend_header\n0 0 0\n10.5 0 0\n0 -2 0\n3 0 1 2\n"},
+		{"colors", colors, "ply\nformat ascii 1.0\ncomment generated by go3mf\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\n" +
+			"property uchar red\nproperty uchar green\nproperty uchar blue\nproperty uchar alpha\n" +
+			"element face 1\nproperty list uchar uint vertex_indices\nend_header\n0 0 0 255 255 255 255\n10.5 0 0 255 255 255 255\n0 -2 0 255 255 255 255\n3 0 1 2\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			e := NewEncoderType(w, ASCII)
+			e.Colors = tt.colors
+			if err := e.Encode(triangle); err != nil {
+				t.Errorf("Encoder.Encode() error = %v", err)
+				return
+			}
+			if got := w.String(); got != tt.want {
+				t.Errorf("Encoder.Encode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if err := NewEncoder(errWriter{}).Encode(triangle); err == nil {
+		t.Error("Encoder.Encode() expected error")
+	}
+}
+
+func TestEncoder_roundTrip(t *testing.T) {
+	mesh := new(geo.Mesh)
+	mesh.Nodes = []geo.Point3D{{0, 0, 0}, {1.5, 0, 0}, {1, 1, 0}, {0, 1, 0.25}}
+	mesh.Faces = []geo.Face{
+		{NodeIndices: [3]uint32{0, 1, 2}, Resource: 1, ResourceIndices: [3]uint32{0, 1, 0}},
+		{NodeIndices: [3]uint32{0, 2, 3}, Resource: 1, ResourceIndices: [3]uint32{0, 0, 2}},
+	}
+	colors := &go3mf.ColorGroupResource{ID: 1, Colors: []color.RGBA{{255, 0, 0, 255}, {0, 255, 0, 128}, {0, 0, 255, 0}}}
+	for _, encodingType := range []EncodingType{BinaryLittleEndian, BinaryBigEndian, ASCII} {
+		t.Run(encodingType.String(), func(t *testing.T) {
+			buf := new(bytes.Buffer)
+			e := NewEncoderType(buf, encodingType)
+			e.Colors = colors
+			if err := e.Encode(mesh); err != nil {
+				t.Fatalf("Encoder.Encode() error = %v", err)
+			}
+			got := new(go3mf.Model)
+			if err := NewDecoder(buf).Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() error = %v", err)
+			}
+			want := []go3mf.Resource{
+				&go3mf.ColorGroupResource{ID: 1, Colors: colors.Colors},
+				&go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 2}, Mesh: mesh},
+			}
+			deep.CompareUnexportedFields = true
+			if diff := deep.Equal(got.Resources, want); diff != nil {
+				t.Errorf("Encoder.Encode() = %v", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package ply
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// EncodingType is the type of encoding used in the file.
+type EncodingType int
+
+const (
+	// BinaryLittleEndian when the PLY is encoded as a little-endian binary file.
+	BinaryLittleEndian EncodingType = iota
+	// BinaryBigEndian when the PLY is encoded as a big-endian binary file.
+	BinaryBigEndian
+	// ASCII when the PLY is encoded as an ASCII file.
+	ASCII
+)
+
+func (e EncodingType) String() string {
+	return map[EncodingType]string{
+		BinaryLittleEndian: "binary_little_endian",
+		BinaryBigEndian:    "binary_big_endian",
+		ASCII:              "ascii",
+	}[e]
+}
+
+func newEncodingType(s string) (e EncodingType, ok bool) {
+	e, ok = map[string]EncodingType{
+		"binary_little_endian": BinaryLittleEndian,
+		"binary_big_endian":    BinaryBigEndian,
+		"ascii":                ASCII,
+	}[s]
+	return
+}
+
+// dataType is the type of a property value.
+type dataType uint8
+
+const (
+	typeInt8 dataType = iota + 1
+	typeUint8
+	typeInt16
+	typeUint16
+	typeInt32
+	typeUint32
+	typeFloat32
+	typeFloat64
+)
+
+func newDataType(s string) (t dataType, ok bool) {
+	t, ok = map[string]dataType{
+		"char":    typeInt8,
+		"int8":    typeInt8,
+		"uchar":   typeUint8,
+		"uint8":   typeUint8,
+		"short":   typeInt16,
+		"int16":   typeInt16,
+		"ushort":  typeUint16,
+		"uint16":  typeUint16,
+		"int":     typeInt32,
+		"int32":   typeInt32,
+		"uint":    typeUint32,
+		"uint32":  typeUint32,
+		"float":   typeFloat32,
+		"float32": typeFloat32,
+		"double":  typeFloat64,
+		"float64": typeFloat64,
+	}[s]
+	return
+}
+
+// size returns the number of bytes of a binary value.
+func (t dataType) size() int {
+	return map[dataType]int{
+		typeInt8:    1,
+		typeUint8:   1,
+		typeInt16:   2,
+		typeUint16:  2,
+		typeInt32:   4,
+		typeUint32:  4,
+		typeFloat32: 4,
+		typeFloat64: 8,
+	}[t]
+}
+
+// property is a scalar property or, if countType is not zero, a list property.
+type property struct {
+	name      string
+	dataType  dataType
+	countType dataType
+}
+
+type element struct {
+	name       string
+	count      int
+	properties []property
+}
+
+type header struct {
+	encoding EncodingType
+	elements []element
+}
+
+// decodeHeader reads the header up to and including the end_header line.
+func decodeHeader(r *bufio.Reader) (*header, error) {
+	line, err := r.ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "ply" {
+		return nil, errors.New("go3mf: ply magic number not found")
+	}
+	h := new(header)
+	var hasFormat bool
+	for {
+		line, err = r.ReadString('\n')
+		if err != nil {
+			return nil, fmt.Errorf("go3mf: ply header: %v", err)
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "end_header":
+			if !hasFormat {
+				return nil, errors.New("go3mf: ply header: missing format")
+			}
+			return h, nil
+		case "format":
+			var ok bool
+			if len(fields) < 2 {
+				return nil, errors.New("go3mf: ply header: missing format type")
+			}
+			if h.encoding, ok = newEncodingType(fields[1]); !ok {
+				return nil, fmt.Errorf("go3mf: ply header: unsupported format %s", fields[1])
+			}
+			hasFormat = true
+		case "element":
+			if len(fields) != 3 {
+				return nil, fmt.Errorf("go3mf: ply header: invalid element '%s'", strings.TrimSpace(line))
+			}
+			count, err := strconv.Atoi(fields[2])
+			if err != nil || count < 0 {
+				return nil, fmt.Errorf("go3mf: ply header: invalid element count '%s'", fields[2])
+			}
+			h.elements = append(h.elements, element{name: fields[1], count: count})
+		case "property":
+			if len(h.elements) == 0 {
+				return nil, errors.New("go3mf: ply header: property without element")
+			}
+			p, err := decodeProperty(fields[1:])
+			if err != nil {
+				return nil, err
+			}
+			e := &h.elements[len(h.elements)-1]
+			e.properties = append(e.properties, p)
+		}
+	}
+}
+
+func decodeProperty(fields []string) (property, error) {
+	var (
+		p  property
+		ok bool
+	)
+	if len(fields) == 4 && fields[0] == "list" {
+		if p.countType, ok = newDataType(fields[1]); !ok {
+			return p, fmt.Errorf("go3mf: ply header: unsupported type %s", fields[1])
+		}
+		fields = fields[2:]
+	}
+	if len(fields) != 2 {
+		return p, errors.New("go3mf: ply header: invalid property")
+	}
+	if p.dataType, ok = newDataType(fields[0]); !ok {
+		return p, fmt.Errorf("go3mf: ply header: unsupported type %s", fields[0])
+	}
+	p.name = fields[1]
+	return p, nil
+}
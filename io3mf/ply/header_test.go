@@ -0,0 +1,60 @@
+package ply
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func Test_decodeHeader(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		want    *header
+		wantErr bool
+	}{
+		{"empty", "", nil, true},
+		{"noMagic", "format ascii 1.0\nend_header\n", nil, true},
+		{"noFormat", "ply\nend_header\n", nil, true},
+		{"noEnd", "ply\nformat ascii 1.0\n", nil, true},
+		{"emptyFormat", "ply\nformat\nend_header\n", nil, true},
+		{"unsupportedFormat", "ply\nformat binary 1.0\nend_header\n", nil, true},
+		{"invalidElement", "ply\nformat ascii 1.0\nelement vertex\nend_header\n", nil, true},
+		{"invalidCount", "ply\nformat ascii 1.0\nelement vertex -1\nend_header\n", nil, true},
+		{"orphanProperty", "ply\nformat ascii 1.0\nproperty float x\nend_header\n", nil, true},
+		{"invalidProperty", "ply\nformat ascii 1.0\nelement vertex 1\nproperty float\nend_header\n", nil, true},
+		{"invalidType", "ply\nformat ascii 1.0\nelement vertex 1\nproperty half x\nend_header\n", nil, true},
+		{"invalidCountType", "ply\nformat ascii 1.0\nelement face 1\nproperty list byte int vertex_indices\nend_header\n", nil, true},
+		{"ascii", "ply\nformat ascii 1.0\ncomment test\n\nelement vertex 8\nproperty float32 x\nproperty uchar red\nelement face 6\nproperty list uchar int vertex_indices\nend_header\n", &header{
+			encoding: ASCII,
+			elements: []element{
+				{name: "vertex", count: 8, properties: []property{{name: "x", dataType: typeFloat32}, {name: "red", dataType: typeUint8}}},
+				{name: "face", count: 6, properties: []property{{name: "vertex_indices", dataType: typeInt32, countType: typeUint8}}},
+			},
+		}, false},
+		{"binary", "ply\r\nformat binary_big_endian 1.0\r\nend_header\r\n", &header{encoding: BinaryBigEndian}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decodeHeader(bufio.NewReader(strings.NewReader(tt.header)))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decodeHeader() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			deep.CompareUnexportedFields = true
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("decodeHeader() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestEncodingType_String(t *testing.T) {
+	for _, e := range []EncodingType{BinaryLittleEndian, BinaryBigEndian, ASCII} {
+		if got, ok := newEncodingType(e.String()); !ok || got != e {
+			t.Errorf("newEncodingType(%s) = %v, want %v", e, got, e)
+		}
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"context"
 	"encoding/xml"
 	"errors"
+	"fmt"
 	"image/color"
 	"io"
+	"io/ioutil"
 	"os"
 	"sort"
 	"strconv"
@@ -143,7 +145,13 @@ func (d *modelFile) NamespaceRegistered(ns string) bool {
 	return false
 }
 
-func (d *modelFile) Decode(ctx context.Context, x XMLDecoder) (err error) {
+func (d *modelFile) Decode(ctx context.Context, x XMLDecoder) error {
+	return d.decode(ctx, x, &topLevelDecoder{isRoot: d.isRoot, model: d.model})
+}
+
+// decode walks the XML tokens starting from the top level decoder,
+// which is what allows other package XML parts to reuse the model decoders machinery.
+func (d *modelFile) decode(ctx context.Context, x XMLDecoder, top nodeDecoder) (err error) {
 	d.parser = parser{Strict: d.strict, ModelPath: d.path}
 	d.namespaces = make(map[string]string)
 	d.resourcesMap = make(map[uint32]go3mf.Resource)
@@ -157,7 +165,8 @@ func (d *modelFile) Decode(ctx context.Context, x XMLDecoder) (err error) {
 		t              xml.Token
 	)
 	nextBytesCheck := checkEveryBytes
-	currentDecoder = &topLevelDecoder{isRoot: d.isRoot, model: d.model}
+	currentDecoder = top
+	currentDecoder.SetModelFile(d)
 
 	for {
 		t, err = x.Token()
@@ -219,11 +228,33 @@ type Decoder struct {
 	Strict              bool
 	Warnings            []error
 	AttachmentRelations []string
-	p                   packageReader
-	x                   func(r io.Reader) XMLDecoder
-	flate               func(r io.Reader) io.ReadCloser
-	productionModels    map[string]packageFile
-	ctx                 context.Context
+	// OnVertex, if not nil, is called for every vertex of a mesh object
+	// instead of storing it in the object Mesh, so huge meshes can be inspected in constant memory.
+	// The object attributes are already decoded when it is called.
+	// Returning an error stops the decoding.
+	// It can be called concurrently when the model has more than one production model part.
+	OnVertex func(r *go3mf.MeshResource, v geo.Point3D) error
+	// OnTriangle is the OnVertex counterpart for triangles,
+	// which are received with the object default properties already applied.
+	OnTriangle func(r *go3mf.MeshResource, f geo.Face) error
+	// KeyProvider unwraps the keys of the parts encrypted using the secure content extension.
+	// It is only required when the package has encrypted parts.
+	KeyProvider KeyProvider
+	// PreserveUnknown captures the attributes and elements of unsupported namespaces
+	// found in the model, the objects, the meshes and the build items into their Unknown fields,
+	// so the Encoder writes them back unchanged. Otherwise they are skipped.
+	PreserveUnknown bool
+
+	p                packageReader
+	x                func(r io.Reader) XMLDecoder
+	flate            func(r io.Reader) io.ReadCloser
+	productionModels map[string]packageFile
+	ctx              context.Context
+	keyStore         *go3mf.KeyStore
+	keysMu           sync.Mutex
+	keys             map[*go3mf.ResourceDataGroup][]byte
+	resolution       go3mf.SliceResolution
+	hasResolution    bool
 }
 
 // NewDecoder returns a new Decoder reading a 3mf file from r.
@@ -249,13 +280,27 @@ func (d *Decoder) SetDecompressor(dcomp func(r io.Reader) io.ReadCloser) {
 	d.flate = dcomp
 }
 
+// SetModelResolution selects the model resolution of the object alternatives to decode.
+// The production model parts only referenced by alternatives with other resolutions are not decoded,
+// so FindAlternative does not find their objects and the model should not be encoded back.
+func (d *Decoder) SetModelResolution(r go3mf.SliceResolution) {
+	d.resolution = r
+	d.hasResolution = true
+}
+
 // DecodeContext reads the 3mf file and unmarshall its content into the model.
 func (d *Decoder) DecodeContext(ctx context.Context, model *go3mf.Model) error {
 	rootFile, err := d.processOPC(model)
 	if err != nil {
 		return err
 	}
-	if err := d.processNonRootModels(ctx, model); err != nil {
+	var skip map[string]bool
+	if d.hasResolution {
+		if skip, err = d.unneededModels(rootFile); err != nil {
+			return err
+		}
+	}
+	if err := d.processNonRootModels(ctx, model, skip); err != nil {
 		return err
 	}
 	return d.processRootModel(ctx, rootFile, model)
@@ -269,7 +314,7 @@ func (d *Decoder) tokenReader(r io.Reader) XMLDecoder {
 }
 
 func (d *Decoder) processRootModel(ctx context.Context, rootFile packageFile, model *go3mf.Model) error {
-	f, err := rootFile.Open()
+	f, err := d.openFile(rootFile)
 	if err != nil {
 		return err
 	}
@@ -294,7 +339,69 @@ func (d *Decoder) addModelFile(f *modelFile, model *go3mf.Model) {
 	}
 }
 
-func (d *Decoder) processNonRootModels(ctx context.Context, model *go3mf.Model) (err error) {
+// unneededModels scans the root model for the production model parts
+// which are only referenced by alternatives with a resolution other than the selected one.
+func (d *Decoder) unneededModels(rootFile packageFile) (map[string]bool, error) {
+	f, err := d.openFile(rootFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	x := d.tokenReader(f)
+	needed, alternatives := make(map[string]bool), make(map[string]bool)
+	for {
+		t, err := x.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		se, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if se.Name.Space == nsAlternativesSpec && se.Name.Local == attrAlternative {
+			var (
+				path       string
+				resolution go3mf.SliceResolution
+			)
+			for _, a := range se.Attr {
+				if a.Name.Space != "" {
+					continue
+				}
+				switch a.Name.Local {
+				case attrPath:
+					path = a.Value
+				case attrModelResolution:
+					resolution, _ = newSliceResolution(a.Value)
+				}
+			}
+			if path != "" {
+				if resolution == d.resolution {
+					needed[path] = true
+				} else {
+					alternatives[path] = true
+				}
+			}
+			continue
+		}
+		for _, a := range se.Attr {
+			if a.Name.Space == nsProductionSpec && a.Name.Local == attrPath {
+				needed[a.Value] = true
+			}
+		}
+	}
+	skip := make(map[string]bool)
+	for path := range alternatives {
+		if !needed[path] {
+			skip[path] = true
+		}
+	}
+	return skip, nil
+}
+
+func (d *Decoder) processNonRootModels(ctx context.Context, model *go3mf.Model, skip map[string]bool) (err error) {
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -304,6 +411,9 @@ func (d *Decoder) processNonRootModels(ctx context.Context, model *go3mf.Model)
 	for i := 0; i < prodAttCount; i++ {
 		go func(i int) {
 			defer wg.Done()
+			if skip[model.ProductionAttachments[i].Path] {
+				return
+			}
 			f, err1 := d.readProductionAttachmentModel(ctx, i, model)
 			select {
 			case <-ctx.Done():
@@ -345,18 +455,22 @@ func (d *Decoder) processOPC(model *go3mf.Model) (packageFile, error) {
 	}
 
 	model.Path = rootFile.Name()
+	if err := d.processKeyStore(model); err != nil {
+		return nil, err
+	}
 	d.extractTexturesAttachments(rootFile, model)
 	d.extractCustomAttachments(rootFile, model)
 	d.extractModelAttachments(rootFile, model)
 	for _, a := range model.ProductionAttachments {
 		file, _ := d.p.FindFileFromName(a.Path)
-		d.extractCustomAttachments(file, model)
 		d.extractTexturesAttachments(file, model)
+		d.extractCustomAttachments(file, model)
 	}
 	thumbFile, ok := rootFile.FindFileFromRel(relTypeThumbnail)
 	if ok {
-		if buff, err := copyFile(thumbFile); err == nil {
+		if buff, err := d.copyFile(thumbFile); err == nil {
 			model.SetThumbnail(buff)
+			model.Thumbnail.Path = thumbFile.Name()
 		}
 	}
 
@@ -369,20 +483,39 @@ func (d *Decoder) extractTexturesAttachments(rootFile packageFile, model *go3mf.
 			continue
 		}
 
-		if file, ok := rootFile.FindFileFromRel(rel.Type()); ok {
+		if file, ok := d.findFileFromTarget(rootFile, rel); ok {
 			model.Attachments = d.addAttachment(model.Attachments, file, rel.Type())
 		}
 	}
 }
 
 func (d *Decoder) extractCustomAttachments(rootFile packageFile, model *go3mf.Model) {
-	for _, rel := range d.AttachmentRelations {
-		if file, ok := rootFile.FindFileFromRel(rel); ok {
-			model.Attachments = d.addAttachment(model.Attachments, file, rel)
+	for _, rel := range rootFile.Relationships() {
+		if !d.isAttachmentRelation(rel.Type()) {
+			continue
+		}
+
+		if file, ok := d.findFileFromTarget(rootFile, rel); ok {
+			model.Attachments = d.addAttachment(model.Attachments, file, rel.Type())
 		}
 	}
 }
 
+func (d *Decoder) isAttachmentRelation(relType string) bool {
+	for _, r := range d.AttachmentRelations {
+		if r == relType {
+			return true
+		}
+	}
+	return false
+}
+
+// findFileFromTarget returns the file pointed by the relationship target,
+// as there may be more than one relationship of the same type.
+func (d *Decoder) findFileFromTarget(source packageFile, rel relationship) (packageFile, bool) {
+	return d.p.FindFileFromName(resolveRelationship(source.Name(), rel.TargetURI()))
+}
+
 func (d *Decoder) extractModelAttachments(rootFile packageFile, model *go3mf.Model) {
 	d.productionModels = make(map[string]packageFile)
 	for _, rel := range rootFile.Relationships() {
@@ -390,7 +523,7 @@ func (d *Decoder) extractModelAttachments(rootFile packageFile, model *go3mf.Mod
 			continue
 		}
 
-		if file, ok := rootFile.FindFileFromRel(rel.TargetURI()); ok {
+		if file, ok := d.findFileFromTarget(rootFile, rel); ok {
 			model.ProductionAttachments = append(model.ProductionAttachments, &go3mf.ProductionAttachment{
 				RelationshipType: rel.Type(),
 				Path:             file.Name(),
@@ -401,7 +534,7 @@ func (d *Decoder) extractModelAttachments(rootFile packageFile, model *go3mf.Mod
 }
 
 func (d *Decoder) addAttachment(attachments []*go3mf.Attachment, file packageFile, relType string) []*go3mf.Attachment {
-	buff, err := copyFile(file)
+	buff, err := d.copyFile(file)
 	if err == nil {
 		return append(attachments, &go3mf.Attachment{
 			RelationshipType: relType,
@@ -414,7 +547,7 @@ func (d *Decoder) addAttachment(attachments []*go3mf.Attachment, file packageFil
 
 func (d *Decoder) readProductionAttachmentModel(ctx context.Context, i int, model *go3mf.Model) (*modelFile, error) {
 	attachment := model.ProductionAttachments[i]
-	file, err := d.productionModels[attachment.Path].Open()
+	file, err := d.openFile(d.productionModels[attachment.Path])
 	if err != nil {
 		return nil, err
 	}
@@ -424,15 +557,100 @@ func (d *Decoder) readProductionAttachmentModel(ctx context.Context, i int, mode
 	return &mf, err
 }
 
-func copyFile(file packageFile) (io.Reader, error) {
-	stream, err := file.Open()
+// processKeyStore reads the keystore of the secure content extension, if any.
+func (d *Decoder) processKeyStore(model *go3mf.Model) error {
+	file, ok := d.p.FindFileFromRel(relTypeKeyStore)
+	if !ok {
+		return nil
+	}
+	f, err := file.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	ks := new(go3mf.KeyStore)
+	mf := modelFile{d: d, path: file.Name(), model: model, strict: d.Strict}
+	err = mf.decode(context.Background(), d.tokenReader(f), &keyStoreFileDecoder{ks: ks})
+	for _, res := range mf.parser.Warnings {
+		d.Warnings = append(d.Warnings, res)
+	}
+	if err != nil {
+		return err
+	}
+	model.KeyStore = ks
+	d.keyStore = ks
+	return nil
+}
+
+// openFile opens a package file, decrypting it when it is protected by the keystore.
+func (d *Decoder) openFile(file packageFile) (io.ReadCloser, error) {
+	if d.keyStore == nil {
+		return file.Open()
+	}
+	group, data, ok := d.keyStore.FindResourceData(file.Name())
+	if !ok {
+		return file.Open()
+	}
+	key, err := d.contentKey(group)
+	if err != nil {
+		return nil, err
+	}
+	f, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	ciphered, err := ioutil.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := decryptContent(key, data.CEKParams, ciphered)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.NopCloser(bytes.NewReader(plain)), nil
+}
+
+// contentKey returns the content encryption key of the group,
+// unwrapping it from the first access right the key provider can open.
+func (d *Decoder) contentKey(group *go3mf.ResourceDataGroup) ([]byte, error) {
+	d.keysMu.Lock()
+	defer d.keysMu.Unlock()
+	if key, ok := d.keys[group]; ok {
+		return key, nil
+	}
+	if d.KeyProvider == nil {
+		return nil, errors.New("go3mf: decrypting parts requires a key provider")
+	}
+	err := fmt.Errorf("go3mf: resource data group '%s' does not have any access right", group.KeyUUID)
+	for _, ar := range group.AccessRights {
+		if int(ar.ConsumerIndex) >= len(d.keyStore.Consumers) {
+			continue
+		}
+		var key []byte
+		key, err = d.KeyProvider.UnwrapKey(d.keyStore.Consumers[ar.ConsumerIndex], ar)
+		if err == nil {
+			if d.keys == nil {
+				d.keys = make(map[*go3mf.ResourceDataGroup][]byte)
+			}
+			d.keys[group] = key
+			return key, nil
+		}
+	}
+	return nil, err
+}
+
+// copyFile returns a seekable copy of the file content
+// so attachments can be read more than once.
+func (d *Decoder) copyFile(file packageFile) (io.Reader, error) {
+	stream, err := d.openFile(file)
 	if err != nil {
 		return nil, err
 	}
 	buff := new(bytes.Buffer)
 	_, err = io.Copy(buff, stream)
 	stream.Close()
-	return buff, err
+	return bytes.NewReader(buff.Bytes()), err
 }
 
 func strToSRGB(s string) (c color.RGBA, err error) {
@@ -71,7 +71,9 @@ func (m *modelBuilder) withModel(unit string, lang string) *modelBuilder {
 	m.str.WriteString(`<model `)
 	m.addAttr("", "unit", unit).addAttr("xml", "lang", lang)
 	m.addAttr("", "xmlns", nsCoreSpec).addAttr("xmlns", "m", nsMaterialSpec).addAttr("xmlns", "p", nsProductionSpec)
-	m.addAttr("xmlns", "b", nsBeamLatticeSpec).addAttr("xmlns", "s", nsSliceSpec).addAttr("", "requiredextensions", "m p b s")
+	m.addAttr("xmlns", "b", nsBeamLatticeSpec).addAttr("xmlns", "s", nsSliceSpec).addAttr("xmlns", "d", nsDisplacementSpec).addAttr("xmlns", "t", nsTriangleSetsSpec)
+	m.addAttr("xmlns", "bo", nsBooleanSpec).addAttr("xmlns", "b2", nsBallsSpec).addAttr("xmlns", "pa", nsAlternativesSpec)
+	m.addAttr("", "requiredextensions", "m p b s d")
 	m.str.WriteString(">\n")
 	m.hasModel = true
 	return m
@@ -135,10 +137,14 @@ type mockPackage struct {
 	mock.Mock
 }
 
-func newMockPackage(other *mockFile) *mockPackage {
+func newMockPackage(other *mockFile, files ...*mockFile) *mockPackage {
 	m := new(mockPackage)
 	m.On("Open", mock.Anything).Return(nil).Maybe()
+	m.On("FindFileFromRel", relTypeKeyStore).Return((*mockFile)(nil), false).Maybe()
 	m.On("FindFileFromRel", mock.Anything).Return(other, other != nil).Maybe()
+	for _, f := range files {
+		m.On("FindFileFromName", f.Name()).Return(f, true).Maybe()
+	}
 	m.On("FindFileFromName", mock.Anything).Return(other, other != nil).Maybe()
 	return m
 }
@@ -188,14 +194,14 @@ func TestDecoder_processOPC(t *testing.T) {
 		{"noRoot", &Decoder{p: newMockPackage(nil)}, &go3mf.Model{}, true},
 		{"noRels", &Decoder{p: newMockPackage(newMockFile("/a.model", nil, nil, nil, false))}, &go3mf.Model{Path: "/a.model"}, false},
 		{"withThumb", &Decoder{
-			p: newMockPackage(newMockFile("/a.model", []relationship{newMockRelationship(relTypeThumbnail, "/a.png")}, thumbFile, thumbFile, false)),
+			p: newMockPackage(newMockFile("/a.model", []relationship{newMockRelationship(relTypeThumbnail, "/a.png")}, thumbFile, thumbFile, false), thumbFile),
 		}, &go3mf.Model{
 			Path:        "/a.model",
-			Thumbnail:   &go3mf.Attachment{RelationshipType: relTypeThumbnail, Path: "/Metadata/thumbnail.png", Stream: new(bytes.Buffer)},
-			Attachments: []*go3mf.Attachment{{RelationshipType: relTypeThumbnail, Path: "/a.png", Stream: new(bytes.Buffer)}},
+			Thumbnail:   &go3mf.Attachment{RelationshipType: relTypeThumbnail, Path: "/a.png", Stream: bytes.NewReader(nil)},
+			Attachments: []*go3mf.Attachment{{RelationshipType: relTypeThumbnail, Path: "/a.png", Stream: bytes.NewReader(nil)}},
 		}, false},
 		{"withThumbErr", &Decoder{
-			p: newMockPackage(newMockFile("/a.model", []relationship{newMockRelationship(relTypeThumbnail, "/a.png")}, thumbErr, thumbErr, false)),
+			p: newMockPackage(newMockFile("/a.model", []relationship{newMockRelationship(relTypeThumbnail, "/a.png")}, thumbErr, thumbErr, false), thumbErr),
 		}, &go3mf.Model{Path: "/a.model"}, false},
 		{"withOtherRel", &Decoder{
 			p: newMockPackage(newMockFile("/a.model", []relationship{newMockRelationship("other", "/a.png")}, nil, nil, false)),
@@ -207,10 +213,22 @@ func TestDecoder_processOPC(t *testing.T) {
 			ProductionAttachments: []*go3mf.ProductionAttachment{{RelationshipType: relTypeModel3D, Path: "/a.model"}},
 		}, false},
 		{"withAttRel", &Decoder{AttachmentRelations: []string{"b"},
-			p: newMockPackage(newMockFile("/a.model", []relationship{newMockRelationship("b", "/a.xml")}, nil, newMockFile("/a.xml", nil, nil, nil, false), false)),
+			p: newMockPackage(newMockFile("/a.model", []relationship{newMockRelationship("b", "/a.xml")}, nil, nil, false), newMockFile("/a.xml", nil, nil, nil, false)),
 		}, &go3mf.Model{
 			Path:        "/a.model",
-			Attachments: []*go3mf.Attachment{{RelationshipType: "b", Path: "/a.xml", Stream: new(bytes.Buffer)}},
+			Attachments: []*go3mf.Attachment{{RelationshipType: "b", Path: "/a.xml", Stream: bytes.NewReader(nil)}},
+		}, false},
+		{"withMultipleAttRel", &Decoder{AttachmentRelations: []string{"b"},
+			p: newMockPackage(newMockFile("/a.model", []relationship{
+				newMockRelationship("b", "/a.xml"), newMockRelationship(relTypeTexture3D, "/a.png"), newMockRelationship("b", "/b.xml"),
+			}, nil, nil, false), newMockFile("/a.xml", nil, nil, nil, false), newMockFile("/b.xml", nil, nil, nil, false), thumbFile),
+		}, &go3mf.Model{
+			Path: "/a.model",
+			Attachments: []*go3mf.Attachment{
+				{RelationshipType: relTypeTexture3D, Path: "/a.png", Stream: bytes.NewReader(nil)},
+				{RelationshipType: "b", Path: "/a.xml", Stream: bytes.NewReader(nil)},
+				{RelationshipType: "b", Path: "/b.xml", Stream: bytes.NewReader(nil)},
+			},
 		}, false},
 	}
 	for _, tt := range tests {
@@ -249,140 +267,34 @@ func TestDecoder_processRootModel_Fail(t *testing.T) {
 	}
 }
 
-func TestDecoder_processRootModel(t *testing.T) {
-	baseMaterials := &go3mf.BaseMaterialsResource{ID: 5, ModelPath: "/3d/3dmodel.model", Materials: []go3mf.BaseMaterial{
-		{Name: "Blue PLA", Color: color.RGBA{0, 0, 255, 255}},
-		{Name: "Red ABS", Color: color.RGBA{255, 0, 0, 255}},
-	}}
-	baseTexture := &go3mf.Texture2DResource{ID: 6, ModelPath: "/3d/3dmodel.model", Path: "/3D/Texture/msLogo.png", ContentType: go3mf.TextureTypePNG, TileStyleU: go3mf.TileWrap, TileStyleV: go3mf.TileMirror, Filter: go3mf.TextureFilterAuto}
-	otherSlices := go3mf.SliceStack{
-		BottomZ: 2,
-		Slices: []*geo.Slice{
-			{
-				TopZ:     1.2,
-				Vertices: []geo.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}, {1.07, 9.08}},
-				Polygons: [][]int{{0, 1, 2, 3, 0}},
-			},
-		},
-	}
-	sliceStack := &go3mf.SliceStackResource{ID: 3, ModelPath: "/3d/3dmodel.model", Stack: go3mf.SliceStack{
-		BottomZ: 1,
-		Slices: []*geo.Slice{
-			{
-				TopZ:     0,
-				Vertices: []geo.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}, {1.07, 9.08}},
-				Polygons: [][]int{{0, 1, 2, 3, 0}},
-			},
-			{
-				TopZ:     0.1,
-				Vertices: []geo.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}, {1.07, 9.08}},
-				Polygons: [][]int{{0, 2, 1, 3, 0}},
-			},
-		},
-	}}
-	sliceStackRef := &go3mf.SliceStackResource{ID: 7, ModelPath: "/3d/3dmodel.model", Stack: go3mf.SliceStack{BottomZ: 1.1, Refs: []go3mf.SliceRef{{SliceStackID: 10, Path: "/2D/2Dmodel.model"}}}}
-	meshRes := &go3mf.MeshResource{
-		ObjectResource: go3mf.ObjectResource{ID: 8, Name: "Box 1", ModelPath: "/3d/3dmodel.model", SliceStackID: 3, Thumbnail: "/a.png", DefaultPropertyID: 5, SliceResoultion: go3mf.ResolutionLow, PartNumber: "11111111-1111-1111-1111-111111111111"},
-		Mesh:           new(geo.Mesh),
-	}
-	meshRes.Mesh.Nodes = append(meshRes.Mesh.Nodes, []geo.Point3D{
-		{0, 0, 0},
-		{100, 0, 0},
-		{100, 100, 0},
-		{0, 100, 0},
-		{0, 0, 100},
-		{100, 0, 100},
-		{100, 100, 100},
-		{0, 100, 100},
-	}...)
-	meshRes.Mesh.Faces = append(meshRes.Mesh.Faces, []geo.Face{
-		{NodeIndices: [3]uint32{3, 2, 1}, Resource: 5},
-		{NodeIndices: [3]uint32{1, 0, 3}, Resource: 5},
-		{NodeIndices: [3]uint32{4, 5, 6}, Resource: 5, ResourceIndices: [3]uint32{1, 1, 1}},
-		{NodeIndices: [3]uint32{6, 7, 4}, Resource: 5, ResourceIndices: [3]uint32{1, 1, 1}},
-		{NodeIndices: [3]uint32{0, 1, 5}, Resource: 2, ResourceIndices: [3]uint32{0, 1, 2}},
-		{NodeIndices: [3]uint32{5, 4, 0}, Resource: 2, ResourceIndices: [3]uint32{3, 0, 2}},
-		{NodeIndices: [3]uint32{1, 2, 6}, Resource: 1, ResourceIndices: [3]uint32{0, 1, 2}},
-		{NodeIndices: [3]uint32{6, 5, 1}, Resource: 1, ResourceIndices: [3]uint32{2, 1, 3}},
-		{NodeIndices: [3]uint32{2, 3, 7}, Resource: 5},
-		{NodeIndices: [3]uint32{7, 6, 2}, Resource: 5},
-		{NodeIndices: [3]uint32{3, 0, 4}, Resource: 5},
-		{NodeIndices: [3]uint32{4, 7, 3}, Resource: 5},
-	}...)
-
-	meshLattice := &go3mf.MeshResource{
-		ObjectResource:        go3mf.ObjectResource{ID: 15, Name: "Box", ModelPath: "/3d/3dmodel.model", PartNumber: "e1ef01d4-cbd4-4a62-86b6-9634e2ca198b"},
-		BeamLatticeAttributes: go3mf.BeamLatticeAttributes{ClipMode: go3mf.ClipInside, ClippingMeshID: 8, RepresentationMeshID: 8},
-		Mesh:                  new(geo.Mesh),
-	}
-	meshLattice.Mesh.MinLength = 0.0001
-	meshLattice.Mesh.CapMode = geo.CapModeHemisphere
-	meshLattice.Mesh.DefaultRadius = 1
-	meshLattice.Mesh.Nodes = append(meshLattice.Mesh.Nodes, []geo.Point3D{
-		{45, 55, 55},
-		{45, 45, 55},
-		{45, 55, 45},
-		{45, 45, 45},
-		{55, 55, 45},
-		{55, 55, 55},
-		{55, 45, 55},
-		{55, 45, 45},
-	}...)
-	meshLattice.Mesh.BeamSets = append(meshLattice.Mesh.BeamSets, geo.BeamSet{Name: "test", Identifier: "set_id", Refs: []uint32{1}})
-	meshLattice.Mesh.Beams = append(meshLattice.Mesh.Beams, []geo.Beam{
-		{NodeIndices: [2]uint32{0, 1}, Radius: [2]float64{1.5, 1.6}, CapMode: [2]geo.CapMode{geo.CapModeSphere, geo.CapModeButt}},
-		{NodeIndices: [2]uint32{2, 0}, Radius: [2]float64{3, 1.5}, CapMode: [2]geo.CapMode{geo.CapModeSphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{1, 3}, Radius: [2]float64{1.6, 3}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{3, 2}, Radius: [2]float64{1, 1}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{2, 4}, Radius: [2]float64{3, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{4, 5}, Radius: [2]float64{2, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{5, 6}, Radius: [2]float64{2, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{7, 6}, Radius: [2]float64{2, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{1, 6}, Radius: [2]float64{1.6, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{7, 4}, Radius: [2]float64{2, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{7, 3}, Radius: [2]float64{2, 3}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
-		{NodeIndices: [2]uint32{0, 5}, Radius: [2]float64{1.5, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeButt}},
-	}...)
-
-	components := &go3mf.ComponentsResource{
-		ObjectResource: go3mf.ObjectResource{
-			ID: 20, UUID: "cb828680-8895-4e08-a1fc-be63e033df15", ModelPath: "/3d/3dmodel.model",
-			Metadata: []go3mf.Metadata{{Name: nsProductionSpec + ":CustomMetadata3", Type: "xs:boolean", Value: "1"}, {Name: nsProductionSpec + ":CustomMetadata4", Type: "xs:boolean", Value: "2"}},
-		},
-		Components: []*go3mf.Component{{UUID: "cb828680-8895-4e08-a1fc-be63e033df16", Object: meshRes,
-			Transform: geo.Matrix{3, 0, 0, 0, 0, 1, 0, 0, 0, 0, 2, 0, -66.4, -87.1, 8.8, 1}}},
-	}
-
-	want := &go3mf.Model{Units: go3mf.UnitMillimeter, Language: "en-US", Path: "/3d/3dmodel.model", UUID: "e9e25302-6428-402e-8633-cc95528d0ed3"}
-	otherMesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 8, ModelPath: "/3d/other.model"}, Mesh: new(geo.Mesh)}
-	colorGroup := &go3mf.ColorGroupResource{ID: 1, ModelPath: "/3d/3dmodel.model", Colors: []color.RGBA{{R: 255, G: 255, B: 255, A: 255}, {R: 0, G: 0, B: 0, A: 255}, {R: 26, G: 181, B: 103, A: 255}, {R: 223, G: 4, B: 90, A: 255}}}
-	texGroup := &go3mf.Texture2DGroupResource{ID: 2, ModelPath: "/3d/3dmodel.model", TextureID: 6, Coords: []go3mf.TextureCoord{{0.3, 0.5}, {0.3, 0.8}, {0.5, 0.8}, {0.5, 0.5}}}
-	compositeGroup := &go3mf.CompositeMaterialsResource{ID: 4, ModelPath: "/3d/3dmodel.model", MaterialID: 5, Indices: []uint32{1, 2}, Composites: []go3mf.Composite{{Values: []float64{0.5, 0.5}}, {Values: []float64{0.2, 0.8}}}}
-	multiGroup := &go3mf.MultiPropertiesResource{ID: 9, ModelPath: "/3d/3dmodel.model", BlendMethods: []go3mf.BlendMethod{go3mf.BlendMultiply}, Resources: []uint32{5, 2}, Multis: []go3mf.Multi{{ResourceIndices: []uint32{0, 0}}, {ResourceIndices: []uint32{1, 0}}, {ResourceIndices: []uint32{2, 3}}}}
-	want.Resources = append(want.Resources, &go3mf.SliceStackResource{ID: 10, ModelPath: "/2D/2Dmodel.model", Stack: otherSlices})
-	want.Resources = append(want.Resources, []go3mf.Resource{otherMesh, baseMaterials, baseTexture, colorGroup, texGroup, compositeGroup, sliceStack, sliceStackRef, multiGroup, meshRes, meshLattice, components}...)
-	want.BuildItems = append(want.BuildItems, &go3mf.BuildItem{Object: components, PartNumber: "bob", UUID: "e9e25302-6428-402e-8633-cc95528d0ed2",
-		Transform: geo.Matrix{1, 0, 0, 0, 0, 2, 0, 0, 0, 0, 3, 0, -66.4, -87.1, 8.8, 1},
-	})
-	want.BuildItems = append(want.BuildItems, &go3mf.BuildItem{Object: otherMesh, UUID: "e9e25302-6428-402e-8633-cc95528d0ed3", Metadata: []go3mf.Metadata{{Name: nsProductionSpec + ":CustomMetadata3", Type: "xs:boolean", Value: "1"}}})
-	want.Metadata = append(want.Metadata, []go3mf.Metadata{
-		{Name: "Application", Value: "go3mf app"},
-		{Name: nsProductionSpec + ":CustomMetadata1", Preserve: true, Type: "xs:string", Value: "CE8A91FB-C44E-4F00-B634-BAA411465F6A"},
-	}...)
-	got := new(go3mf.Model)
-	got.Path = "/3d/3dmodel.model"
-	got.Resources = append(got.Resources, &go3mf.SliceStackResource{ID: 10, ModelPath: "/2D/2Dmodel.model", Stack: otherSlices}, otherMesh)
-	rootFile := new(modelBuilder).withDefaultModel().withElement(`
+// rootModelFixture returns a root model that uses all the supported extensions.
+// It references the slice stack 10 from "/2D/2Dmodel.model" and the object 8 from "/3d/other.model".
+func rootModelFixture() *modelBuilder {
+	return new(modelBuilder).withDefaultModel().withElement(`
 		<resources>
-			<basematerials id="5">
+			<m:pbspeculardisplayproperties id="11">
+				<m:pbspecular name="Specular" specularcolor="#FF000080" glossiness="0.5" />
+				<m:pbspecular name="Default" />
+			</m:pbspeculardisplayproperties>
+			<m:pbmetallicdisplayproperties id="12">
+				<m:pbmetallic name="Metal" metallicness="1" roughness="0.2" />
+				<m:pbmetallic name="Default" />
+			</m:pbmetallicdisplayproperties>
+			<m:pbspeculartexturedisplayproperties id="13" name="SpecTex" speculartextureid="6" glossinesstextureid="6" diffusefactor="#00FF00" />
+			<m:pbmetallictexturedisplayproperties id="14" name="MetalTex" metallictextureid="6" roughnesstextureid="6" metallicfactor="0.5" />
+			<m:translucentdisplayproperties id="16">
+				<m:translucent name="Glass" attenuation="0.1 0.2 0.3" refractiveindex="1.5 1.5 1.5" roughness="0.1" />
+				<m:translucent name="Default" attenuation="0 0 0" />
+			</m:translucentdisplayproperties>
+			<basematerials id="5" displaypropertiesid="12">
 				<base name="Blue PLA" displaycolor="#0000FF" />
 				<base name="Red ABS" displaycolor="#FF0000" />
 			</basematerials>
 			<m:texture2d id="6" path="/3D/Texture/msLogo.png" contenttype="image/png" tilestyleu="wrap" tilestylev="mirror" filter="auto" />
-			<m:colorgroup id="1">
+			<m:colorgroup id="1" displaypropertiesid="11">
 				<m:color color="#FFFFFF" /> <m:color color="#000000" /> <m:color color="#1AB567" /> <m:color color="#DF045A" />
 			</m:colorgroup>
-			<m:texture2dgroup id="2" texid="6">
+			<m:texture2dgroup id="2" texid="6" displaypropertiesid="14">
 				<m:tex2coord u="0.3" v="0.5" /> <m:tex2coord u="0.3" v="0.8" />	<m:tex2coord u="0.5" v="0.8" />	<m:tex2coord u="0.5" v="0.5" />
 			</m:texture2dgroup>
 			<m:compositematerials id="4" matid="5" matindices="1 2">
@@ -441,7 +353,20 @@ func TestDecoder_processRootModel(t *testing.T) {
 						<triangle v1="3" v2="0" v3="4" />
 						<triangle v1="4" v2="7" v3="3" />
 					</triangles>
+					<t:trianglesets>
+						<t:triangleset name="Top" identifier="set_top">
+							<t:ref index="2"/>
+							<t:refrange startindex="4" endindex="6"/>
+							<t:refrange startindex="5" endindex="6"/>
+							<t:ref index="5"/>
+							<t:ref index="0"/>
+						</t:triangleset>
+					</t:trianglesets>
 				</mesh>
+				<pa:alternatives>
+					<pa:alternative objectid="8" UUID="cb828680-8895-4e08-a1fc-be63e033df20" path="/3d/other.model" modelresolution="lowres" />
+					<pa:alternative objectid="15" UUID="cb828680-8895-4e08-a1fc-be63e033df21" />
+				</pa:alternatives>
 			</object>
 			<object id="15" name="Box" partnumber="e1ef01d4-cbd4-4a62-86b6-9634e2ca198b" type="model">
 				<mesh>
@@ -455,7 +380,7 @@ func TestDecoder_processRootModel(t *testing.T) {
 						<vertex x="55.00000" y="45.00000" z="55.00000"/>
 						<vertex x="55.00000" y="45.00000" z="45.00000"/>
 					</vertices>
-					<b:beamlattice radius="1" minlength="0.0001" cap="hemisphere" clippingmode="inside" clippingmesh="8" representationmesh="8">
+					<b:beamlattice radius="1" minlength="0.0001" cap="hemisphere" clippingmode="inside" clippingmesh="8" representationmesh="8" b2:ballmode="mixed">
 						<b:beams>
 							<b:beam v1="0" v2="1" r1="1.50000" r2="1.60000" cap1="sphere" cap2="butt"/>
 							<b:beam v1="2" v2="0" r1="3.00000" r2="1.50000" cap1="sphere"/>
@@ -470,6 +395,10 @@ func TestDecoder_processRootModel(t *testing.T) {
 							<b:beam v1="7" v2="3" r1="2.00000" r2="3.00000"/>
 							<b:beam v1="0" v2="5" r1="1.50000" r2="2.00000" cap2="butt"/>
 						</b:beams>
+						<b2:balls>
+							<b2:ball vindex="0" r="1.5"/>
+							<b2:ball vindex="2"/>
+						</b2:balls>
 						<b:beamsets>
 							<b:beamset name="test" identifier="set_id">
 								<b:ref index="1"/>
@@ -487,6 +416,35 @@ func TestDecoder_processRootModel(t *testing.T) {
 					<component objectid="8" p:UUID="cb828680-8895-4e08-a1fc-be63e033df16" transform="3 0 0 0 1 0 0 0 2 -66.4 -87.1 8.8"/>
 				</components>
 			</object>
+			<d:displacement2d id="17" path="/3D/Texture/disp.png" channel="R" tilestyleu="clamp" tilestylev="mirror" filter="linear" />
+			<d:normvectorgroup id="18">
+				<d:normvector x="0" y="0" z="1" /> <d:normvector x="0.5" y="0.5" z="0.7071" />
+			</d:normvectorgroup>
+			<d:disp2dgroup id="19" dispid="17" nid="18" height="2.5" offset="-0.5">
+				<d:disp2dcoord u="0" v="0" n="0" /> <d:disp2dcoord u="1" v="0.5" n="1" f="0.5" />
+			</d:disp2dgroup>
+			<object id="21" name="Displaced" pid="5" type="model">
+				<d:displacementmesh>
+					<d:vertices>
+						<d:vertex x="0" y="0" z="0" />
+						<d:vertex x="10" y="0" z="0" />
+						<d:vertex x="0" y="10" z="0" />
+						<d:vertex x="0" y="0" z="10" />
+					</d:vertices>
+					<d:triangles did="19">
+						<d:triangle v1="0" v2="2" v3="1" />
+						<d:triangle v1="0" v2="1" v3="3" d1="1" />
+						<d:triangle v1="1" v2="2" v3="3" p1="1" d1="1" d2="0" />
+						<d:triangle v1="2" v2="0" v3="3" pid="1" p1="2" did="0" />
+					</d:triangles>
+				</d:displacementmesh>
+			</object>
+			<object id="22" name="Cut" type="model">
+				<bo:booleanshape objectid="8" operation="difference" transform="1 0 0 0 1 0 0 0 1 0 0 5">
+					<bo:boolean objectid="15" transform="2 0 0 0 2 0 0 0 2 1 1 1" />
+					<bo:boolean objectid="8" p:path="/3d/other.model" />
+				</bo:booleanshape>
+			</object>
 		</resources>
 		<build p:UUID="e9e25302-6428-402e-8633-cc95528d0ed3">
 			<item partnumber="bob" objectid="20" p:UUID="e9e25302-6428-402e-8633-cc95528d0ed2" transform="1 0 0 0 2 0 0 0 3 -66.4 -87.1 8.8" />
@@ -499,7 +457,199 @@ func TestDecoder_processRootModel(t *testing.T) {
 		<metadata name="Application">go3mf app</metadata>
 		<metadata name="p:CustomMetadata1" type="xs:string" preserve="1">CE8A91FB-C44E-4F00-B634-BAA411465F6A</metadata>
 		<other />
-		`).build()
+		`)
+}
+
+// newFixtureModel returns a model with the non-root resources referenced by rootModelFixture.
+func newFixtureModel() *go3mf.Model {
+	otherSlices := go3mf.SliceStack{BottomZ: 2, Slices: []*geo.Slice{{TopZ: 1.2, Vertices: []geo.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}}, Polygons: [][]int{{0, 1, 2, 0}}}}}
+	model := &go3mf.Model{Path: "/3d/3dmodel.model"}
+	model.Resources = append(model.Resources, &go3mf.SliceStackResource{ID: 10, ModelPath: "/2D/2Dmodel.model", Stack: otherSlices},
+		&go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 8, ModelPath: "/3d/other.model"}, Mesh: new(geo.Mesh)})
+	return model
+}
+
+func TestDecoder_processRootModel(t *testing.T) {
+	specular := &go3mf.PBSpecularDisplayPropertiesResource{ID: 11, ModelPath: "/3d/3dmodel.model", Properties: []go3mf.PBSpecular{
+		{Name: "Specular", SpecularColor: color.RGBA{255, 0, 0, 128}, Glossiness: 0.5},
+		{Name: "Default", SpecularColor: color.RGBA{0x38, 0x38, 0x38, 255}},
+	}}
+	metallic := &go3mf.PBMetallicDisplayPropertiesResource{ID: 12, ModelPath: "/3d/3dmodel.model", Properties: []go3mf.PBMetallic{
+		{Name: "Metal", Metallicness: 1, Roughness: 0.2},
+		{Name: "Default", Roughness: 1},
+	}}
+	specularTex := &go3mf.PBSpecularTextureDisplayPropertiesResource{ID: 13, ModelPath: "/3d/3dmodel.model", Name: "SpecTex", SpecularTextureID: 6, GlossinessTextureID: 6,
+		DiffuseFactor: color.RGBA{0, 255, 0, 255}, SpecularFactor: color.RGBA{255, 255, 255, 255}, GlossinessFactor: 1}
+	metallicTex := &go3mf.PBMetallicTextureDisplayPropertiesResource{ID: 14, ModelPath: "/3d/3dmodel.model", Name: "MetalTex", MetallicTextureID: 6, RoughnessTextureID: 6, MetallicFactor: 0.5, RoughnessFactor: 1}
+	translucent := &go3mf.TranslucentDisplayPropertiesResource{ID: 16, ModelPath: "/3d/3dmodel.model", Properties: []go3mf.Translucent{
+		{Name: "Glass", Attenuation: [3]float64{0.1, 0.2, 0.3}, RefractiveIndex: [3]float64{1.5, 1.5, 1.5}, Roughness: 0.1},
+		{Name: "Default", RefractiveIndex: [3]float64{1, 1, 1}},
+	}}
+	baseMaterials := &go3mf.BaseMaterialsResource{ID: 5, ModelPath: "/3d/3dmodel.model", DisplayPropertiesID: 12, Materials: []go3mf.BaseMaterial{
+		{Name: "Blue PLA", Color: color.RGBA{0, 0, 255, 255}},
+		{Name: "Red ABS", Color: color.RGBA{255, 0, 0, 255}},
+	}}
+	baseTexture := &go3mf.Texture2DResource{ID: 6, ModelPath: "/3d/3dmodel.model", Path: "/3D/Texture/msLogo.png", ContentType: go3mf.TextureTypePNG, TileStyleU: go3mf.TileWrap, TileStyleV: go3mf.TileMirror, Filter: go3mf.TextureFilterAuto}
+	otherSlices := go3mf.SliceStack{
+		BottomZ: 2,
+		Slices: []*geo.Slice{
+			{
+				TopZ:     1.2,
+				Vertices: []geo.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}, {1.07, 9.08}},
+				Polygons: [][]int{{0, 1, 2, 3, 0}},
+			},
+		},
+	}
+	sliceStack := &go3mf.SliceStackResource{ID: 3, ModelPath: "/3d/3dmodel.model", Stack: go3mf.SliceStack{
+		BottomZ: 1,
+		Slices: []*geo.Slice{
+			{
+				TopZ:     0,
+				Vertices: []geo.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}, {1.07, 9.08}},
+				Polygons: [][]int{{0, 1, 2, 3, 0}},
+			},
+			{
+				TopZ:     0.1,
+				Vertices: []geo.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}, {1.07, 9.08}},
+				Polygons: [][]int{{0, 2, 1, 3, 0}},
+			},
+		},
+	}}
+	sliceStackRef := &go3mf.SliceStackResource{ID: 7, ModelPath: "/3d/3dmodel.model", Stack: go3mf.SliceStack{BottomZ: 1.1, Refs: []go3mf.SliceRef{{SliceStackID: 10, Path: "/2D/2Dmodel.model"}}}}
+	meshRes := &go3mf.MeshResource{
+		ObjectResource: go3mf.ObjectResource{ID: 8, Name: "Box 1", ModelPath: "/3d/3dmodel.model", SliceStackID: 3, Thumbnail: "/a.png", DefaultPropertyID: 5, SliceResoultion: go3mf.ResolutionLow, PartNumber: "11111111-1111-1111-1111-111111111111"},
+		Mesh:           new(geo.Mesh),
+	}
+	meshRes.Mesh.Nodes = append(meshRes.Mesh.Nodes, []geo.Point3D{
+		{0, 0, 0},
+		{100, 0, 0},
+		{100, 100, 0},
+		{0, 100, 0},
+		{0, 0, 100},
+		{100, 0, 100},
+		{100, 100, 100},
+		{0, 100, 100},
+	}...)
+	meshRes.Mesh.Faces = append(meshRes.Mesh.Faces, []geo.Face{
+		{NodeIndices: [3]uint32{3, 2, 1}, Resource: 5},
+		{NodeIndices: [3]uint32{1, 0, 3}, Resource: 5},
+		{NodeIndices: [3]uint32{4, 5, 6}, Resource: 5, ResourceIndices: [3]uint32{1, 1, 1}},
+		{NodeIndices: [3]uint32{6, 7, 4}, Resource: 5, ResourceIndices: [3]uint32{1, 1, 1}},
+		{NodeIndices: [3]uint32{0, 1, 5}, Resource: 2, ResourceIndices: [3]uint32{0, 1, 2}},
+		{NodeIndices: [3]uint32{5, 4, 0}, Resource: 2, ResourceIndices: [3]uint32{3, 0, 2}},
+		{NodeIndices: [3]uint32{1, 2, 6}, Resource: 1, ResourceIndices: [3]uint32{0, 1, 2}},
+		{NodeIndices: [3]uint32{6, 5, 1}, Resource: 1, ResourceIndices: [3]uint32{2, 1, 3}},
+		{NodeIndices: [3]uint32{2, 3, 7}, Resource: 5},
+		{NodeIndices: [3]uint32{7, 6, 2}, Resource: 5},
+		{NodeIndices: [3]uint32{3, 0, 4}, Resource: 5},
+		{NodeIndices: [3]uint32{4, 7, 3}, Resource: 5},
+	}...)
+	meshRes.Mesh.TriangleSets = []geo.TriangleSet{{Name: "Top", Identifier: "set_top", Refs: []uint32{0, 2, 4, 5, 6}}}
+	meshRes.Alternatives = []go3mf.Alternative{
+		{ObjectID: 8, UUID: "cb828680-8895-4e08-a1fc-be63e033df20", Path: "/3d/other.model", ModelResolution: go3mf.ResolutionLow},
+		{ObjectID: 15, UUID: "cb828680-8895-4e08-a1fc-be63e033df21"},
+	}
+
+	meshLattice := &go3mf.MeshResource{
+		ObjectResource:        go3mf.ObjectResource{ID: 15, Name: "Box", ModelPath: "/3d/3dmodel.model", PartNumber: "e1ef01d4-cbd4-4a62-86b6-9634e2ca198b"},
+		BeamLatticeAttributes: go3mf.BeamLatticeAttributes{ClipMode: go3mf.ClipInside, ClippingMeshID: 8, RepresentationMeshID: 8},
+		Mesh:                  new(geo.Mesh),
+	}
+	meshLattice.Mesh.MinLength = 0.0001
+	meshLattice.Mesh.CapMode = geo.CapModeHemisphere
+	meshLattice.Mesh.DefaultRadius = 1
+	meshLattice.Mesh.Nodes = append(meshLattice.Mesh.Nodes, []geo.Point3D{
+		{45, 55, 55},
+		{45, 45, 55},
+		{45, 55, 45},
+		{45, 45, 45},
+		{55, 55, 45},
+		{55, 55, 55},
+		{55, 45, 55},
+		{55, 45, 45},
+	}...)
+	meshLattice.Mesh.BeamSets = append(meshLattice.Mesh.BeamSets, geo.BeamSet{Name: "test", Identifier: "set_id", Refs: []uint32{1}})
+	meshLattice.Mesh.BallMode = geo.BallModeMixed
+	meshLattice.Mesh.DefaultBallRadius = 1
+	meshLattice.Mesh.Balls = []geo.Ball{{NodeIndex: 0, Radius: 1.5}, {NodeIndex: 2, Radius: 1}}
+	meshLattice.Mesh.Beams = append(meshLattice.Mesh.Beams, []geo.Beam{
+		{NodeIndices: [2]uint32{0, 1}, Radius: [2]float64{1.5, 1.6}, CapMode: [2]geo.CapMode{geo.CapModeSphere, geo.CapModeButt}},
+		{NodeIndices: [2]uint32{2, 0}, Radius: [2]float64{3, 1.5}, CapMode: [2]geo.CapMode{geo.CapModeSphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{1, 3}, Radius: [2]float64{1.6, 3}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{3, 2}, Radius: [2]float64{1, 1}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{2, 4}, Radius: [2]float64{3, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{4, 5}, Radius: [2]float64{2, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{5, 6}, Radius: [2]float64{2, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{7, 6}, Radius: [2]float64{2, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{1, 6}, Radius: [2]float64{1.6, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{7, 4}, Radius: [2]float64{2, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{7, 3}, Radius: [2]float64{2, 3}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+		{NodeIndices: [2]uint32{0, 5}, Radius: [2]float64{1.5, 2}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeButt}},
+	}...)
+
+	components := &go3mf.ComponentsResource{
+		ObjectResource: go3mf.ObjectResource{
+			ID: 20, UUID: "cb828680-8895-4e08-a1fc-be63e033df15", ModelPath: "/3d/3dmodel.model",
+			Metadata: []go3mf.Metadata{{Name: nsProductionSpec + ":CustomMetadata3", Type: "xs:boolean", Value: "1"}, {Name: nsProductionSpec + ":CustomMetadata4", Type: "xs:boolean", Value: "2"}},
+		},
+		Components: []*go3mf.Component{{UUID: "cb828680-8895-4e08-a1fc-be63e033df16", Object: meshRes,
+			Transform: geo.Matrix{3, 0, 0, 0, 0, 1, 0, 0, 0, 0, 2, 0, -66.4, -87.1, 8.8, 1}}},
+	}
+
+	displacement := &go3mf.Displacement2DResource{ID: 17, ModelPath: "/3d/3dmodel.model", Path: "/3D/Texture/disp.png", Channel: go3mf.ChannelR, TileStyleU: go3mf.TileClamp, TileStyleV: go3mf.TileMirror, Filter: go3mf.TextureFilterLinear}
+	normVectors := &go3mf.NormVectorGroupResource{ID: 18, ModelPath: "/3d/3dmodel.model", Vectors: []geo.Point3D{{0, 0, 1}, {0.5, 0.5, 0.7071}}}
+	dispGroup := &go3mf.Disp2DGroupResource{ID: 19, ModelPath: "/3d/3dmodel.model", DisplacementID: 17, NormVectorGroupID: 18, Height: 2.5, Offset: -0.5,
+		Coords: []go3mf.Disp2DCoord{{U: 0, V: 0, N: 0, F: 1}, {U: 1, V: 0.5, N: 1, F: 0.5}}}
+	dispMesh := &go3mf.DisplacementMeshResource{
+		ObjectResource:        go3mf.ObjectResource{ID: 21, Name: "Displaced", ModelPath: "/3d/3dmodel.model", DefaultPropertyID: 5},
+		Mesh:                  new(geo.Mesh),
+		DefaultDisplacementID: 19,
+		Displacements: []go3mf.DisplacementTriangle{
+			{DisplacementID: 19},
+			{DisplacementID: 19, Indices: [3]uint32{1, 1, 1}},
+			{DisplacementID: 19, Indices: [3]uint32{1, 0, 1}},
+			{DisplacementID: 0},
+		},
+	}
+	dispMesh.Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {10, 0, 0}, {0, 10, 0}, {0, 0, 10}}
+	dispMesh.Mesh.Faces = []geo.Face{
+		{NodeIndices: [3]uint32{0, 2, 1}, Resource: 5},
+		{NodeIndices: [3]uint32{0, 1, 3}, Resource: 5},
+		{NodeIndices: [3]uint32{1, 2, 3}, Resource: 5, ResourceIndices: [3]uint32{1, 1, 1}},
+		{NodeIndices: [3]uint32{2, 0, 3}, Resource: 1, ResourceIndices: [3]uint32{2, 2, 2}},
+	}
+
+	otherMesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 8, ModelPath: "/3d/other.model"}, Mesh: new(geo.Mesh)}
+	booleanShape := &go3mf.BooleanShapeResource{
+		ObjectResource: go3mf.ObjectResource{ID: 22, Name: "Cut", ModelPath: "/3d/3dmodel.model"},
+		Object:         meshRes,
+		Transform:      geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 5, 1},
+		Operation:      go3mf.BooleanDifference,
+		Operands: []*go3mf.Boolean{
+			{Object: meshLattice, Transform: geo.Matrix{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 1, 1, 1, 1}},
+			{Object: otherMesh},
+		},
+	}
+
+	want := &go3mf.Model{Units: go3mf.UnitMillimeter, Language: "en-US", Path: "/3d/3dmodel.model", UUID: "e9e25302-6428-402e-8633-cc95528d0ed3"}
+	colorGroup := &go3mf.ColorGroupResource{ID: 1, ModelPath: "/3d/3dmodel.model", DisplayPropertiesID: 11, Colors: []color.RGBA{{R: 255, G: 255, B: 255, A: 255}, {R: 0, G: 0, B: 0, A: 255}, {R: 26, G: 181, B: 103, A: 255}, {R: 223, G: 4, B: 90, A: 255}}}
+	texGroup := &go3mf.Texture2DGroupResource{ID: 2, ModelPath: "/3d/3dmodel.model", TextureID: 6, DisplayPropertiesID: 14, Coords: []go3mf.TextureCoord{{0.3, 0.5}, {0.3, 0.8}, {0.5, 0.8}, {0.5, 0.5}}}
+	compositeGroup := &go3mf.CompositeMaterialsResource{ID: 4, ModelPath: "/3d/3dmodel.model", MaterialID: 5, Indices: []uint32{1, 2}, Composites: []go3mf.Composite{{Values: []float64{0.5, 0.5}}, {Values: []float64{0.2, 0.8}}}}
+	multiGroup := &go3mf.MultiPropertiesResource{ID: 9, ModelPath: "/3d/3dmodel.model", BlendMethods: []go3mf.BlendMethod{go3mf.BlendMultiply}, Resources: []uint32{5, 2}, Multis: []go3mf.Multi{{ResourceIndices: []uint32{0, 0}}, {ResourceIndices: []uint32{1, 0}}, {ResourceIndices: []uint32{2, 3}}}}
+	want.Resources = append(want.Resources, &go3mf.SliceStackResource{ID: 10, ModelPath: "/2D/2Dmodel.model", Stack: otherSlices})
+	want.Resources = append(want.Resources, []go3mf.Resource{otherMesh, specular, metallic, specularTex, metallicTex, translucent, baseMaterials, baseTexture, colorGroup, texGroup, compositeGroup, sliceStack, sliceStackRef, multiGroup, meshRes, meshLattice, components, displacement, normVectors, dispGroup, dispMesh, booleanShape}...)
+	want.BuildItems = append(want.BuildItems, &go3mf.BuildItem{Object: components, PartNumber: "bob", UUID: "e9e25302-6428-402e-8633-cc95528d0ed2",
+		Transform: geo.Matrix{1, 0, 0, 0, 0, 2, 0, 0, 0, 0, 3, 0, -66.4, -87.1, 8.8, 1},
+	})
+	want.BuildItems = append(want.BuildItems, &go3mf.BuildItem{Object: otherMesh, UUID: "e9e25302-6428-402e-8633-cc95528d0ed3", Metadata: []go3mf.Metadata{{Name: nsProductionSpec + ":CustomMetadata3", Type: "xs:boolean", Value: "1"}}})
+	want.Metadata = append(want.Metadata, []go3mf.Metadata{
+		{Name: "Application", Value: "go3mf app"},
+		{Name: nsProductionSpec + ":CustomMetadata1", Preserve: true, Type: "xs:string", Value: "CE8A91FB-C44E-4F00-B634-BAA411465F6A"},
+	}...)
+	got := new(go3mf.Model)
+	got.Path = "/3d/3dmodel.model"
+	got.Resources = append(got.Resources, &go3mf.SliceStackResource{ID: 10, ModelPath: "/2D/2Dmodel.model", Stack: otherSlices}, otherMesh)
+	rootFile := rootModelFixture().build()
 
 	t.Run("base", func(t *testing.T) {
 		d := new(Decoder)
@@ -612,6 +762,7 @@ func TestDecoder_processNonRootModels(t *testing.T) {
 		name    string
 		model   *go3mf.Model
 		d       *Decoder
+		skip    map[string]bool
 		wantErr bool
 		want    *go3mf.Model
 	}{
@@ -632,7 +783,7 @@ func TestDecoder_processNonRootModels(t *testing.T) {
 					<m:texture2d id="6" path="/3D/Texture/msLogo.png" contenttype="image/png" tilestyleu="wrap" tilestylev="mirror" filter="auto" />
 				</resources>
 			`).build(),
-		}}, false, &go3mf.Model{
+		}}, nil, false, &go3mf.Model{
 			ProductionAttachments: []*go3mf.ProductionAttachment{
 				{Path: "3d/new.model"},
 				{Path: "3d/other.model"},
@@ -644,11 +795,29 @@ func TestDecoder_processNonRootModels(t *testing.T) {
 				&go3mf.Texture2DResource{ID: 6, ModelPath: "3d/other.model", Path: "/3D/Texture/msLogo.png", ContentType: go3mf.TextureTypePNG, TileStyleU: go3mf.TileWrap, TileStyleV: go3mf.TileMirror, Filter: go3mf.TextureFilterAuto},
 			},
 		}},
-		{"noAtt", new(go3mf.Model), new(Decoder), false, new(go3mf.Model)},
+		{"skip", &go3mf.Model{ProductionAttachments: []*go3mf.ProductionAttachment{
+			{Path: "3d/new.model"},
+			{Path: "3d/other.model"},
+		}}, &Decoder{productionModels: map[string]packageFile{
+			"3d/new.model": new(modelBuilder).withDefaultModel().withElement(`
+				<resources><basematerials id="5"><base name="Blue PLA" displaycolor="#0000FF" /></basematerials></resources>
+			`).build(),
+			"3d/other.model": newMockFile("3d/other.model", nil, nil, nil, true),
+		}}, map[string]bool{"3d/other.model": true}, false, &go3mf.Model{
+			ProductionAttachments: []*go3mf.ProductionAttachment{
+				{Path: "3d/new.model"},
+				{Path: "3d/other.model"},
+			}, Resources: []go3mf.Resource{
+				&go3mf.BaseMaterialsResource{ID: 5, ModelPath: "3d/new.model", Materials: []go3mf.BaseMaterial{
+					{Name: "Blue PLA", Color: color.RGBA{0, 0, 255, 255}},
+				}},
+			},
+		}},
+		{"noAtt", new(go3mf.Model), new(Decoder), nil, false, new(go3mf.Model)},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.d.processNonRootModels(context.Background(), tt.model); (err != nil) != tt.wantErr {
+			if err := tt.d.processNonRootModels(context.Background(), tt.model, tt.skip); (err != nil) != tt.wantErr {
 				t.Errorf("Decoder.processNonRootModels() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
@@ -662,6 +831,40 @@ func TestDecoder_processNonRootModels(t *testing.T) {
 	}
 }
 
+func TestDecoder_unneededModels(t *testing.T) {
+	const root = `
+		<resources>
+			<object id="1"><pa:alternatives>
+				<pa:alternative objectid="1" UUID="e9e25302-6428-402e-8633-cc95528d0ed3" path="/3D/low.model" modelresolution="lowres" />
+				<pa:alternative objectid="2" UUID="e9e25302-6428-402e-8633-cc95528d0ed4" path="/3D/full.model" />
+				<pa:alternative objectid="3" UUID="e9e25302-6428-402e-8633-cc95528d0ed5" path="/3D/shared.model" modelresolution="lowres" />
+			</pa:alternatives><components><component objectid="4" p:path="/3D/shared.model" /></components></object>
+		</resources>
+	`
+	tests := []struct {
+		name       string
+		resolution go3mf.SliceResolution
+		want       map[string]bool
+	}{
+		{"full", go3mf.ResolutionFull, map[string]bool{"/3D/low.model": true}},
+		{"low", go3mf.ResolutionLow, map[string]bool{"/3D/full.model": true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := new(Decoder)
+			d.SetModelResolution(tt.resolution)
+			got, err := d.unneededModels(new(modelBuilder).withDefaultModel().withElement(root).build())
+			if err != nil {
+				t.Errorf("Decoder.unneededModels() unexpected error = %v", err)
+				return
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Decoder.unneededModels() = %v", diff)
+			}
+		})
+	}
+}
+
 func TestDecoder_Decode(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -739,6 +942,75 @@ func TestNewDecoder(t *testing.T) {
 	}
 }
 
+func TestDecoder_processRootModel_hooks(t *testing.T) {
+	hookErr := errors.New("hook error")
+	type counts struct{ vertices, triangles, p1 int }
+	tests := []struct {
+		name       string
+		onVertex   bool
+		onTriangle bool
+		failAt     int
+		want       map[uint32]counts
+		wantErr    error
+	}{
+		{"vertices", true, false, -1, map[uint32]counts{8: {8, 0, 0}, 15: {8, 0, 0}}, nil},
+		{"triangles", false, true, -1, map[uint32]counts{8: {0, 12, 2}}, nil},
+		{"both", true, true, -1, map[uint32]counts{8: {8, 12, 2}, 15: {8, 0, 0}}, nil},
+		{"error", true, true, 3, nil, hookErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[uint32]counts)
+			calls := 0
+			count := func(r *go3mf.MeshResource, f func(*counts)) error {
+				if calls++; calls == tt.failAt {
+					return hookErr
+				}
+				c := got[r.ID]
+				f(&c)
+				got[r.ID] = c
+				return nil
+			}
+			d := new(Decoder)
+			if tt.onVertex {
+				d.OnVertex = func(r *go3mf.MeshResource, v geo.Point3D) error {
+					return count(r, func(c *counts) { c.vertices++ })
+				}
+			}
+			if tt.onTriangle {
+				d.OnTriangle = func(r *go3mf.MeshResource, f geo.Face) error {
+					return count(r, func(c *counts) {
+						c.triangles++
+						if f.ResourceIndices == [3]uint32{1, 1, 1} {
+							c.p1++
+						}
+					})
+				}
+			}
+			model := newFixtureModel()
+			err := d.processRootModel(context.Background(), rootModelFixture().build(), model)
+			if err != tt.wantErr {
+				t.Errorf("Decoder.processRootModel() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if diff := deep.Equal(got, tt.want); diff != nil {
+				t.Errorf("Decoder.processRootModel() = %v", diff)
+			}
+			r, _ := model.FindResource(model.Path, 8)
+			mesh := r.(*go3mf.MeshResource).Mesh
+			if tt.onVertex && len(mesh.Nodes) != 0 {
+				t.Errorf("Decoder.processRootModel() vertices stored with OnVertex = %d", len(mesh.Nodes))
+			}
+			if tt.onTriangle && len(mesh.Faces) != 0 {
+				t.Errorf("Decoder.processRootModel() triangles stored with OnTriangle = %d", len(mesh.Faces))
+			}
+		})
+	}
+}
+
 func TestDecoder_processRootModel_warns(t *testing.T) {
 	want := []error{
 		ParsePropertyError{ResourceID: 0, Element: "base", Name: "displaycolor", Value: "0000FF", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
@@ -751,6 +1023,13 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 		MissingPropertyError{ResourceID: 0, Element: "texture2d", ModelPath: "/3d/3dmodel.model", Name: "path"},
 		MissingPropertyError{ResourceID: 0, Element: "texture2d", ModelPath: "/3d/3dmodel.model", Name: "id"},
 		ParsePropertyError{ResourceID: 1, Element: "color", Name: "color", Value: "#FFFFF", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
+		ParsePropertyError{ResourceID: 11, Element: "pbspecular", Name: "specularcolor", Value: "#FF", ModelPath: "/3d/3dmodel.model", Type: PropertyOptional},
+		ParsePropertyError{ResourceID: 11, Element: "pbspecular", Name: "glossiness", Value: "a", ModelPath: "/3d/3dmodel.model", Type: PropertyOptional},
+		MissingPropertyError{ResourceID: 11, Element: "pbspecular", ModelPath: "/3d/3dmodel.model", Name: "name"},
+		MissingPropertyError{ResourceID: 12, Element: "pbmetallic", ModelPath: "/3d/3dmodel.model", Name: "name"},
+		MissingPropertyError{ResourceID: 14, Element: "pbmetallictexturedisplayproperties", ModelPath: "/3d/3dmodel.model", Name: "roughnesstextureid"},
+		ParsePropertyError{ResourceID: 16, Element: "translucent", Name: "attenuation", Value: "0.1 0.2", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
+		ParsePropertyError{ResourceID: 16, Element: "translucent", Name: "refractiveindex", Value: "1.5", ModelPath: "/3d/3dmodel.model", Type: PropertyOptional},
 		ParsePropertyError{ResourceID: 2, Element: "tex2coord", Name: "u", Value: "b", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
 		ParsePropertyError{ResourceID: 2, Element: "tex2coord", Name: "v", Value: "c", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
 		MissingPropertyError{ResourceID: 4, Element: "compositematerials", ModelPath: "/3d/3dmodel.model", Name: "matid"},
@@ -774,10 +1053,22 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 		ParsePropertyError{ResourceID: 8, Element: "object", ModelPath: "/3d/3dmodel.model", Name: "meshresolution", Value: "invalid", Type: PropertyOptional},
 		GenericError{ResourceID: 8, Element: "triangle", ModelPath: "/3d/3dmodel.model", Message: "duplicated triangle indices"},
 		GenericError{ResourceID: 8, Element: "triangle", ModelPath: "/3d/3dmodel.model", Message: "triangle indices are out of range"},
+		MissingPropertyError{ResourceID: 8, Element: "ref", ModelPath: "/3d/3dmodel.model", Name: "index"},
+		GenericError{ResourceID: 8, Element: "ref", ModelPath: "/3d/3dmodel.model", Message: "triangle set indices are out of range"},
+		MissingPropertyError{ResourceID: 8, Element: "refrange", ModelPath: "/3d/3dmodel.model", Name: "endindex"},
+		GenericError{ResourceID: 8, Element: "refrange", ModelPath: "/3d/3dmodel.model", Message: "triangle set range start is greater than its end"},
+		MissingPropertyError{ResourceID: 8, Element: "alternative", ModelPath: "/3d/3dmodel.model", Name: "objectid"},
+		MissingPropertyError{ResourceID: 8, Element: "alternative", ModelPath: "/3d/3dmodel.model", Name: "UUID"},
+		ParsePropertyError{ResourceID: 8, Element: "alternative", Name: "UUID", Value: "cb828680", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
+		ParsePropertyError{ResourceID: 8, Element: "alternative", Name: "modelresolution", Value: "invalid", ModelPath: "/3d/3dmodel.model", Type: PropertyOptional},
 		MissingPropertyError{ResourceID: 15, Element: "beamlattice", ModelPath: "/3d/3dmodel.model", Name: "radius"},
 		MissingPropertyError{ResourceID: 15, Element: "beamlattice", ModelPath: "/3d/3dmodel.model", Name: "minlength"},
+		ParsePropertyError{ResourceID: 15, Element: "beamlattice", Name: "ballmode", Value: "invalid", ModelPath: "/3d/3dmodel.model", Type: PropertyOptional},
 		MissingPropertyError{ResourceID: 15, Element: "beam", ModelPath: "/3d/3dmodel.model", Name: "v1"},
 		MissingPropertyError{ResourceID: 15, Element: "beam", ModelPath: "/3d/3dmodel.model", Name: "v2"},
+		MissingPropertyError{ResourceID: 15, Element: "ball", ModelPath: "/3d/3dmodel.model", Name: "vindex"},
+		GenericError{ResourceID: 15, Element: "ball", ModelPath: "/3d/3dmodel.model", Message: "ball vertex is not the end of any beam"},
+		GenericError{ResourceID: 15, Element: "ball", ModelPath: "/3d/3dmodel.model", Message: "ball vertex index is out of range"},
 		MissingPropertyError{ResourceID: 15, Element: "ref", ModelPath: "/3d/3dmodel.model", Name: "index"},
 		ParsePropertyError{ResourceID: 15, Element: "ref", Name: "index", Value: "a", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
 		ParsePropertyError{ResourceID: 22, Element: "object", ModelPath: "/3d/3dmodel.model", Name: "type", Value: "invalid", Type: PropertyOptional},
@@ -788,6 +1079,19 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 		MissingPropertyError{ResourceID: 20, Element: "component", ModelPath: "/3d/3dmodel.model", Name: "UUID"},
 		GenericError{ResourceID: 20, Element: "component", ModelPath: "/3d/3dmodel.model", Message: "non-existent referenced object"},
 		GenericError{ResourceID: 20, Element: "component", ModelPath: "/3d/3dmodel.model", Message: "non-object referenced resource"},
+		MissingPropertyError{ResourceID: 23, Element: "displacement2d", ModelPath: "/3d/3dmodel.model", Name: "path"},
+		ParsePropertyError{ResourceID: 24, Element: "disp2dgroup", Name: "nid", Value: "a", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
+		MissingPropertyError{ResourceID: 24, Element: "disp2dgroup", ModelPath: "/3d/3dmodel.model", Name: "height"},
+		ParsePropertyError{ResourceID: 24, Element: "disp2dcoord", Name: "u", Value: "a", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
+		ParsePropertyError{ResourceID: 25, Element: "vertex", Name: "y", Value: "b", ModelPath: "/3d/3dmodel.model", Type: PropertyRequired},
+		GenericError{ResourceID: 25, Element: "triangle", ModelPath: "/3d/3dmodel.model", Message: "duplicated triangle indices"},
+		GenericError{ResourceID: 25, Element: "triangle", ModelPath: "/3d/3dmodel.model", Message: "triangle indices are out of range"},
+		ParsePropertyError{ResourceID: 26, Element: "booleanshape", Name: "operation", Value: "invalid", ModelPath: "/3d/3dmodel.model", Type: PropertyOptional},
+		GenericError{ResourceID: 26, Element: "booleanshape", ModelPath: "/3d/3dmodel.model", Message: "boolean base object is not a mesh or a boolean shape"},
+		MissingPropertyError{ResourceID: 26, Element: "boolean", ModelPath: "/3d/3dmodel.model", Name: "objectid"},
+		GenericError{ResourceID: 26, Element: "boolean", ModelPath: "/3d/3dmodel.model", Message: "boolean operand is not a mesh object"},
+		GenericError{ResourceID: 26, Element: "boolean", ModelPath: "/3d/3dmodel.model", Message: "non-existent referenced object"},
+		GenericError{ResourceID: 27, Element: "booleanshape", ModelPath: "/3d/3dmodel.model", Message: "non-existent referenced object"},
 		MissingPropertyError{ResourceID: 0, Element: "build", ModelPath: "/3d/3dmodel.model", Name: "UUID"},
 		ParsePropertyError{ResourceID: 20, Element: "item", Name: "transform", Value: "1 0 0 0 2 0 0 0 3 -66.4 -87.1", ModelPath: "/3d/3dmodel.model", Type: PropertyOptional},
 		GenericError{ResourceID: 20, Element: "item", ModelPath: "/3d/3dmodel.model", Message: "referenced object cannot be have OTHER type"},
@@ -815,6 +1119,16 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 			<m:colorgroup id="1">
 				<m:color color="#FFFFF" /> <m:color color="#000000" /> <m:color color="#1AB567" /> <m:color color="#DF045A" />
 			</m:colorgroup>
+			<m:pbspeculardisplayproperties id="11">
+				<m:pbspecular specularcolor="#FF" glossiness="a" />
+			</m:pbspeculardisplayproperties>
+			<m:pbmetallicdisplayproperties id="12">
+				<m:pbmetallic metallicness="1" />
+			</m:pbmetallicdisplayproperties>
+			<m:pbmetallictexturedisplayproperties id="14" name="MetalTex" metallictextureid="6" />
+			<m:translucentdisplayproperties id="16">
+				<m:translucent name="Glass" attenuation="0.1 0.2" refractiveindex="1.5" />
+			</m:translucentdisplayproperties>
 			<m:texture2dgroup qm:mq="other" id="2" texid="6">
 				<m:tex2coord qm:mq="other" u="b" v="0.5" /> <m:tex2coord u="0.3" v="c" />	<m:tex2coord u="0.5" v="0.8" />	<m:tex2coord u="0.5" v="0.5" />
 			</m:texture2dgroup>
@@ -877,7 +1191,20 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 						<triangle v1="3" v2="0" v3="4" />
 						<triangle v1="4" v2="7" v3="3" />
 					</triangles>
+					<t:trianglesets>
+						<t:triangleset name="Top" identifier="set_top">
+							<t:ref />
+							<t:ref index="100"/>
+							<t:refrange startindex="4" />
+							<t:refrange startindex="6" endindex="4"/>
+						</t:triangleset>
+					</t:trianglesets>
 				</mesh>
+				<pa:alternatives>
+					<pa:alternative UUID="cb828680-8895-4e08-a1fc-be63e033df20" />
+					<pa:alternative objectid="15" />
+					<pa:alternative objectid="15" UUID="cb828680" modelresolution="invalid" />
+				</pa:alternatives>
 			</object>
 			<object id="15" name="Box" partnumber="e1ef01d4-cbd4-4a62-86b6-9634e2ca198b" type="model">
 				<mesh>
@@ -890,9 +1217,10 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 						<vertex x="55.00000" y="55.00000" z="55.00000"/>
 						<vertex x="55.00000" y="45.00000" z="55.00000"/>
 						<vertex x="55.00000" y="45.00000" z="45.00000"/>
+						<vertex x="50.00000" y="50.00000" z="50.00000"/>
 					</vertices>
 					<b:beamlattice />
-					<b:beamlattice qm:mq="other" radius="1" minlength="0.0001" cap="hemisphere" clippingmode="inside" clippingmesh="8" representationmesh="8">
+					<b:beamlattice qm:mq="other" radius="1" minlength="0.0001" cap="hemisphere" clippingmode="inside" clippingmesh="8" representationmesh="8" b2:ballmode="invalid">
 						<b:beams>
 							<b:beam qm:mq="other" v1="0" v2="1" r1="1.50000" r2="1.60000" cap1="sphere" cap2="butt"/>
 							<b:beam v1="2" v2="0" r1="3.00000" r2="1.50000" cap1="sphere"/>
@@ -908,6 +1236,11 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 							<b:beam v1="7" v2="3" r1="2.00000" r2="3.00000"/>
 							<b:beam v1="0" v2="5" r1="1.50000" r2="2.00000" cap2="butt"/>
 						</b:beams>
+						<b2:balls>
+							<b2:ball r="1.5"/>
+							<b2:ball vindex="8"/>
+							<b2:ball vindex="9"/>
+						</b2:balls>
 						<b:beamsets>
 							<b:beamset qm:mq="other" name="test" identifier="set_id">
 								<b:ref index="1"/>
@@ -925,6 +1258,31 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 					<component objectid="3" p:UUID="cb828680-8895-4e08-a1fc-be63e033df16"/>
 				</components>
 			</object>
+			<d:displacement2d id="23" channel="X" />
+			<d:disp2dgroup id="24" dispid="23" nid="a">
+				<d:disp2dcoord u="a" v="0" n="0" />
+			</d:disp2dgroup>
+			<object id="25">
+				<d:displacementmesh>
+					<d:vertices>
+						<d:vertex x="0" y="0" z="0" /> <d:vertex x="1" y="0" z="0" /> <d:vertex x="0" y="b" z="0" />
+					</d:vertices>
+					<d:triangles>
+						<d:triangle v1="0" v2="0" v3="1" />
+						<d:triangle v1="0" v2="1" v3="3" />
+					</d:triangles>
+				</d:displacementmesh>
+			</object>
+			<object id="26">
+				<bo:booleanshape objectid="20" operation="invalid">
+					<bo:boolean />
+					<bo:boolean objectid="20" />
+					<bo:boolean objectid="100" />
+				</bo:booleanshape>
+			</object>
+			<object id="27">
+				<bo:booleanshape objectid="27" />
+			</object>
 		</resources>
 		<build>
 			<item partnumber="bob" objectid="20" p:UUID="e9e25302-6428-402e-8633-cc95528d0ed2" transform="1 0 0 0 2 0 0 0 3 -66.4 -87.1" />
@@ -952,5 +1310,15 @@ func TestDecoder_processRootModel_warns(t *testing.T) {
 			t.Errorf("Decoder.processRootModel() = %v", diff)
 			return
 		}
+		for _, id := range []uint32{26, 27} {
+			if _, ok := got.FindResource(got.Path, id); ok {
+				t.Errorf("Decoder.processRootModel() added the boolean shape %d without base object", id)
+			}
+		}
+		specular, _ := got.FindResource(got.Path, 11)
+		metallic, _ := got.FindResource(got.Path, 12)
+		if len(specular.(*go3mf.PBSpecularDisplayPropertiesResource).Properties) != 0 || len(metallic.(*go3mf.PBMetallicDisplayPropertiesResource).Properties) != 0 {
+			t.Error("Decoder.processRootModel() added the display properties without name")
+		}
 	})
 }
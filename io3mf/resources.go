@@ -33,10 +33,31 @@ func (d *resourceDecoder) Child(name xml.Name) (child nodeDecoder) {
 			child = new(compositeMaterialsDecoder)
 		case attrMultiProps:
 			child = new(multiPropertiesDecoder)
+		case attrPBSpecularDisplay:
+			child = new(pbSpecularDisplayDecoder)
+		case attrPBMetallicDisplay:
+			child = new(pbMetallicDisplayDecoder)
+		case attrPBSpecularTexture:
+			child = new(pbSpecularTextureDecoder)
+		case attrPBMetallicTexture:
+			child = new(pbMetallicTextureDecoder)
+		case attrTranslucentDisplay:
+			child = new(translucentDisplayDecoder)
 		}
 	} else if name.Space == nsSliceSpec && name.Local == attrSliceStack {
 		d.progressCount++
 		child = &sliceStackDecoder{progressCount: d.progressCount}
+	} else if name.Space == nsDisplacementSpec {
+		switch name.Local {
+		case attrDisplacement2D:
+			child = new(displacement2DDecoder)
+		case attrNormVectorGroup:
+			child = new(normVectorGroupDecoder)
+		case attrDisp2DGroup:
+			child = new(disp2DGroupDecoder)
+		}
+	} else if rd, ok := resourceExtension(name.Space); ok {
+		child = d.extensionResource(rd, name)
 	}
 	return
 }
@@ -70,8 +91,18 @@ func (d *baseMaterialsDecoder) Child(name xml.Name) (child nodeDecoder) {
 func (d *baseMaterialsDecoder) Attributes(attrs []xml.Attr) bool {
 	ok := true
 	for _, a := range attrs {
-		if a.Name.Space == "" && a.Name.Local == attrID {
-			d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
+		if a.Name.Space != "" && a.Name.Space != nsMaterialSpec {
+			continue
+		}
+		switch a.Name.Local {
+		case attrID:
+			if a.Name.Space == "" {
+				d.resource.ID, ok = d.file.parser.ParseResourceID(a.Value)
+			}
+		case attrDisplayPropsID:
+			d.resource.DisplayPropertiesID, ok = d.file.parser.ParseUint32Required(attrDisplayPropsID, a.Value)
+		}
+		if !ok {
 			break
 		}
 	}
@@ -112,3 +143,15 @@ func (d *baseMaterialDecoder) Attributes(attrs []xml.Attr) bool {
 	}
 	return ok
 }
+
+func (w *modelWriter) writeBaseMaterials(r *go3mf.BaseMaterialsResource) {
+	attrs := []xml.Attr{w.attr(attrID, formatUint32(r.ID))}
+	if r.DisplayPropertiesID != 0 {
+		attrs = append(attrs, w.attr(attrDisplayPropsID, formatUint32(r.DisplayPropertiesID)))
+	}
+	w.start(attrBaseMaterials, attrs...)
+	for _, m := range r.Materials {
+		w.element(attrBase, w.attr(attrName, m.Name), w.attr(attrBaseMaterialColor, m.ColorString()))
+	}
+	w.end(attrBaseMaterials)
+}
@@ -0,0 +1,637 @@
+package io3mf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	go3mf "github.com/qmuntal/go3mf"
+)
+
+const (
+	uriKeyStore         = "/Secure/keystore.xml"
+	contentTypeKeyStore = "application/vnd.ms-package.3dmanufacturing-keystore+xml"
+)
+
+const (
+	algAES256GCM  = "http://www.w3.org/2009/xmlenc11#aes256-gcm"
+	algRSAOAEP    = "http://www.w3.org/2009/xmlenc11#rsa-oaep"
+	algRSAOAEPMGF = "http://www.w3.org/2001/04/xmlenc#rsa-oaep-mgf1p"
+	cekSize       = 32
+	ivSize        = 12
+)
+
+// A KeyProvider gives access to the content encryption keys of the secure content extension,
+// which are stored in the keystore wrapped with the key of each consumer.
+type KeyProvider interface {
+	// UnwrapKey returns the content encryption key wrapped in the access right of the consumer.
+	UnwrapKey(c go3mf.Consumer, ar go3mf.AccessRight) ([]byte, error)
+	// WrapKey wraps the content encryption key for the consumer.
+	WrapKey(c go3mf.Consumer, params go3mf.KEKParams, key []byte) ([]byte, error)
+}
+
+// RSAKeyProvider is a KeyProvider that wraps the content encryption keys with RSA-OAEP.
+type RSAKeyProvider struct {
+	// PrivateKeys maps consumer IDs to the keys used to unwrap their content encryption keys.
+	PrivateKeys map[string]*rsa.PrivateKey
+	// PublicKeys maps consumer IDs to the keys used to wrap their content encryption keys.
+	// When a consumer is not in the map its key is parsed from the consumer KeyValue, in PEM format,
+	// or taken from its private key.
+	PublicKeys map[string]*rsa.PublicKey
+}
+
+// UnwrapKey decrypts the cipher value of the access right with the consumer private key.
+func (p *RSAKeyProvider) UnwrapKey(c go3mf.Consumer, ar go3mf.AccessRight) ([]byte, error) {
+	priv, ok := p.PrivateKeys[c.ConsumerID]
+	if !ok {
+		return nil, fmt.Errorf("go3mf: missing private key of consumer '%s'", c.ConsumerID)
+	}
+	h, err := oaepHash(ar.KEKParams)
+	if err != nil {
+		return nil, err
+	}
+	return rsa.DecryptOAEP(h, nil, priv, ar.CipherValue, nil)
+}
+
+// WrapKey encrypts the key with the consumer public key.
+func (p *RSAKeyProvider) WrapKey(c go3mf.Consumer, params go3mf.KEKParams, key []byte) ([]byte, error) {
+	pub, err := p.publicKey(c)
+	if err != nil {
+		return nil, err
+	}
+	h, err := oaepHash(params)
+	if err != nil {
+		return nil, err
+	}
+	return rsa.EncryptOAEP(h, rand.Reader, pub, key, nil)
+}
+
+func (p *RSAKeyProvider) publicKey(c go3mf.Consumer) (*rsa.PublicKey, error) {
+	if pub, ok := p.PublicKeys[c.ConsumerID]; ok {
+		return pub, nil
+	}
+	if c.KeyValue != "" {
+		block, _ := pem.Decode([]byte(c.KeyValue))
+		if block == nil {
+			return nil, fmt.Errorf("go3mf: invalid key value of consumer '%s'", c.ConsumerID)
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if pub, ok := key.(*rsa.PublicKey); ok {
+			return pub, nil
+		}
+		return nil, fmt.Errorf("go3mf: key value of consumer '%s' is not a RSA key", c.ConsumerID)
+	}
+	if priv, ok := p.PrivateKeys[c.ConsumerID]; ok {
+		return &priv.PublicKey, nil
+	}
+	return nil, fmt.Errorf("go3mf: missing public key of consumer '%s'", c.ConsumerID)
+}
+
+// oaepHash returns the hash defined by the key wrapping parameters.
+// The digest method and the mask generation function must use the same hash.
+func oaepHash(params go3mf.KEKParams) (hash.Hash, error) {
+	if params.WrappingAlgorithm != "" && params.WrappingAlgorithm != algRSAOAEP && params.WrappingAlgorithm != algRSAOAEPMGF {
+		return nil, fmt.Errorf("go3mf: unsupported wrapping algorithm '%s'", params.WrappingAlgorithm)
+	}
+	digestMethod, mgfAlgorithm := params.DigestMethod, params.MgfAlgorithm
+	if digestMethod == "" {
+		digestMethod = "http://www.w3.org/2000/09/xmldsig#sha1"
+	}
+	if mgfAlgorithm == "" {
+		mgfAlgorithm = "http://www.w3.org/2009/xmlenc11#mgf1sha1"
+	}
+	digest, ok := newDigestMethod(digestMethod)
+	if !ok {
+		return nil, fmt.Errorf("go3mf: unsupported digest method '%s'", params.DigestMethod)
+	}
+	mgf, ok := map[string]crypto.Hash{
+		"http://www.w3.org/2009/xmlenc11#mgf1sha1":   crypto.SHA1,
+		"http://www.w3.org/2009/xmlenc11#mgf1sha256": crypto.SHA256,
+		"http://www.w3.org/2009/xmlenc11#mgf1sha384": crypto.SHA384,
+		"http://www.w3.org/2009/xmlenc11#mgf1sha512": crypto.SHA512,
+	}[mgfAlgorithm]
+	if !ok || mgf != digest {
+		return nil, fmt.Errorf("go3mf: unsupported mask generation function '%s'", params.MgfAlgorithm)
+	}
+	return digest.New(), nil
+}
+
+func newGCM(key []byte, iv []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCMWithNonceSize(block, len(iv))
+}
+
+// decryptContent returns the plain content of an encrypted part.
+func decryptContent(key []byte, params go3mf.CEKParams, data []byte) ([]byte, error) {
+	if params.EncryptionAlgorithm != algAES256GCM {
+		return nil, fmt.Errorf("go3mf: unsupported encryption algorithm '%s'", params.EncryptionAlgorithm)
+	}
+	if len(params.IV) == 0 {
+		return nil, errors.New("go3mf: missing encryption IV")
+	}
+	gcm, err := newGCM(key, params.IV)
+	if err != nil {
+		return nil, err
+	}
+	data = append(data, params.Tag...)
+	plain, err := gcm.Open(data[:0], params.IV, data, params.AAD)
+	if err != nil || params.Compression != go3mf.CompressionDeflate {
+		return plain, err
+	}
+	r, err := zlib.NewReader(bytes.NewReader(plain))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// encryptContent encrypts the content of a part with a new IV,
+// which is stored in the params together with the resulting tag.
+func encryptContent(key []byte, params *go3mf.CEKParams, plain []byte) ([]byte, error) {
+	if params.Compression == go3mf.CompressionDeflate {
+		var buff bytes.Buffer
+		w := zlib.NewWriter(&buff)
+		if _, err := w.Write(plain); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		plain = buff.Bytes()
+	}
+	params.IV = make([]byte, ivSize)
+	if _, err := io.ReadFull(rand.Reader, params.IV); err != nil {
+		return nil, err
+	}
+	gcm, err := newGCM(key, params.IV)
+	if err != nil {
+		return nil, err
+	}
+	data := gcm.Seal(nil, params.IV, plain, params.AAD)
+	tagStart := len(data) - gcm.Overhead()
+	params.Tag = append([]byte(nil), data[tagStart:]...)
+	return data[:tagStart], nil
+}
+
+// contentEncrypter encrypts the parts protected by a keystore.
+// It works on a copy of the keystore, as every encoding uses new keys and IVs.
+type contentEncrypter struct {
+	ks   go3mf.KeyStore
+	keys [][]byte
+}
+
+func newContentEncrypter(ks *go3mf.KeyStore, p KeyProvider) (*contentEncrypter, error) {
+	if ks == nil {
+		return nil, nil
+	}
+	if p == nil {
+		return nil, errors.New("go3mf: encrypting parts requires a key provider")
+	}
+	e := &contentEncrypter{ks: *ks, keys: make([][]byte, len(ks.ResourceDataGroups))}
+	e.ks.ResourceDataGroups = make([]go3mf.ResourceDataGroup, len(ks.ResourceDataGroups))
+	for i, g := range ks.ResourceDataGroups {
+		e.keys[i] = make([]byte, cekSize)
+		if _, err := io.ReadFull(rand.Reader, e.keys[i]); err != nil {
+			return nil, err
+		}
+		g.AccessRights = append([]go3mf.AccessRight(nil), g.AccessRights...)
+		g.ResourceDatas = append([]go3mf.ResourceData(nil), g.ResourceDatas...)
+		for j := range g.AccessRights {
+			ar := &g.AccessRights[j]
+			if int(ar.ConsumerIndex) >= len(ks.Consumers) {
+				return nil, fmt.Errorf("go3mf: access right of resource data group '%s' has an invalid consumer index", g.KeyUUID)
+			}
+			if ar.KEKParams.WrappingAlgorithm == "" {
+				ar.KEKParams.WrappingAlgorithm = algRSAOAEP
+			}
+			cipherValue, err := p.WrapKey(ks.Consumers[ar.ConsumerIndex], ar.KEKParams, e.keys[i])
+			if err != nil {
+				return nil, err
+			}
+			ar.CipherValue = cipherValue
+		}
+		for j := range g.ResourceDatas {
+			if g.ResourceDatas[j].CEKParams.EncryptionAlgorithm == "" {
+				g.ResourceDatas[j].CEKParams.EncryptionAlgorithm = algAES256GCM
+			}
+		}
+		e.ks.ResourceDataGroups[i] = g
+	}
+	return e, nil
+}
+
+// encrypt returns the encrypted content if the part is protected, else the same content.
+func (e *contentEncrypter) encrypt(name string, data []byte) ([]byte, error) {
+	for i := range e.ks.ResourceDataGroups {
+		g := &e.ks.ResourceDataGroups[i]
+		for j := range g.ResourceDatas {
+			if g.ResourceDatas[j].Path == name {
+				return encryptContent(e.keys[i], &g.ResourceDatas[j].CEKParams, data)
+			}
+		}
+	}
+	return data, nil
+}
+
+// protects returns true if the part is encrypted.
+func (e *contentEncrypter) protects(name string) bool {
+	if e == nil {
+		return false
+	}
+	_, _, ok := e.ks.FindResourceData(name)
+	return ok
+}
+
+// relationships returns the relationships from the keystore to the encrypted parts.
+func (e *contentEncrypter) relationships() []relationship {
+	var rels []relationship
+	for _, g := range e.ks.ResourceDataGroups {
+		for _, r := range g.ResourceDatas {
+			rels = append(rels, &packageRelationship{relType: relTypeEncrypted, targetURI: r.Path})
+		}
+	}
+	return rels
+}
+
+func writeKeyStore(iw io.Writer, ks *go3mf.KeyStore) error {
+	w := modelWriter{x: xml.NewEncoder(iw), prefixes: map[string]string{nsSecureContent: "", nsXMLEnc: "xenc"}}
+	w.writeProcInst()
+	w.startNS(nsSecureContent, attrKeyStore, w.attr(attrXmlns, nsSecureContent), w.attr(attrXmlns+":xenc", nsXMLEnc), w.attr(attrProdUUID, ks.UUID))
+	for _, c := range ks.Consumers {
+		attrs := []xml.Attr{w.attr(attrConsumerID, c.ConsumerID)}
+		if c.KeyID != "" {
+			attrs = append(attrs, w.attr(attrKeyID, c.KeyID))
+		}
+		w.startNS(nsSecureContent, attrConsumer, attrs...)
+		if c.KeyValue != "" {
+			w.startNS(nsSecureContent, attrKeyValue)
+			w.text(c.KeyValue)
+			w.endNS(nsSecureContent, attrKeyValue)
+		}
+		w.endNS(nsSecureContent, attrConsumer)
+	}
+	for _, g := range ks.ResourceDataGroups {
+		w.writeResourceDataGroup(g)
+	}
+	w.endNS(nsSecureContent, attrKeyStore)
+	if w.err == nil {
+		w.err = w.x.Flush()
+	}
+	return w.err
+}
+
+func (w *modelWriter) writeResourceDataGroup(g go3mf.ResourceDataGroup) {
+	w.startNS(nsSecureContent, attrResourceDataGroup, w.attr(attrKeyUUID, g.KeyUUID))
+	for _, ar := range g.AccessRights {
+		w.startNS(nsSecureContent, attrAccessRight, w.attr(attrConsumerIndex, formatUint32(ar.ConsumerIndex)))
+		attrs := []xml.Attr{w.attr(attrWrappingAlgorithm, ar.KEKParams.WrappingAlgorithm)}
+		if ar.KEKParams.MgfAlgorithm != "" {
+			attrs = append(attrs, w.attr(attrMgfAlgorithm, ar.KEKParams.MgfAlgorithm))
+		}
+		if ar.KEKParams.DigestMethod != "" {
+			attrs = append(attrs, w.attr(attrDigestMethod, ar.KEKParams.DigestMethod))
+		}
+		w.elementNS(nsSecureContent, attrKEKParams, attrs...)
+		w.startNS(nsSecureContent, attrCipherData)
+		w.writeBase64(nsXMLEnc, attrCipherValue, ar.CipherValue)
+		w.endNS(nsSecureContent, attrCipherData)
+		w.endNS(nsSecureContent, attrAccessRight)
+	}
+	for _, r := range g.ResourceDatas {
+		w.startNS(nsSecureContent, attrResourceData, w.attr(attrPath, r.Path))
+		attrs := []xml.Attr{w.attr(attrEncryptionAlgo, r.CEKParams.EncryptionAlgorithm)}
+		if r.CEKParams.Compression != go3mf.CompressionNone {
+			attrs = append(attrs, w.attr(attrCompression, r.CEKParams.Compression.String()))
+		}
+		w.startNS(nsSecureContent, attrCEKParams, attrs...)
+		w.writeBase64(nsSecureContent, attrIV, r.CEKParams.IV)
+		w.writeBase64(nsSecureContent, attrTag, r.CEKParams.Tag)
+		if len(r.CEKParams.AAD) > 0 {
+			w.writeBase64(nsSecureContent, attrAAD, r.CEKParams.AAD)
+		}
+		w.endNS(nsSecureContent, attrCEKParams)
+		w.endNS(nsSecureContent, attrResourceData)
+	}
+	w.endNS(nsSecureContent, attrResourceDataGroup)
+}
+
+func (w *modelWriter) writeBase64(ns, local string, b []byte) {
+	w.startNS(ns, local)
+	w.text(base64.StdEncoding.EncodeToString(b))
+	w.endNS(ns, local)
+}
+
+type keyStoreFileDecoder struct {
+	emptyDecoder
+	ks *go3mf.KeyStore
+}
+
+func (d *keyStoreFileDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsSecureContent && name.Local == attrKeyStore {
+		child = &keyStoreDecoder{ks: d.ks}
+	}
+	return
+}
+
+type keyStoreDecoder struct {
+	emptyDecoder
+	ks *go3mf.KeyStore
+}
+
+func (d *keyStoreDecoder) Attributes(attrs []xml.Attr) bool {
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrProdUUID {
+			if err := validateUUID(a.Value); err != nil {
+				ok = d.file.parser.InvalidRequiredAttr(attrProdUUID, a.Value)
+			}
+			d.ks.UUID = a.Value
+		}
+	}
+	if ok && d.ks.UUID == "" {
+		ok = d.file.parser.MissingAttr(attrProdUUID)
+	}
+	return ok
+}
+
+func (d *keyStoreDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsSecureContent {
+		if name.Local == attrConsumer {
+			child = &consumerDecoder{ks: d.ks}
+		} else if name.Local == attrResourceDataGroup {
+			child = &resourceDataGroupDecoder{ks: d.ks}
+		}
+	}
+	return
+}
+
+type consumerDecoder struct {
+	emptyDecoder
+	ks       *go3mf.KeyStore
+	consumer go3mf.Consumer
+}
+
+func (d *consumerDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrConsumerID:
+			d.consumer.ConsumerID = a.Value
+		case attrKeyID:
+			d.consumer.KeyID = a.Value
+		}
+	}
+	if d.consumer.ConsumerID == "" {
+		return d.file.parser.MissingAttr(attrConsumerID)
+	}
+	return true
+}
+
+func (d *consumerDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsSecureContent && name.Local == attrKeyValue {
+		child = &keyValueDecoder{consumer: &d.consumer}
+	}
+	return
+}
+
+func (d *consumerDecoder) Close() bool {
+	d.ks.Consumers = append(d.ks.Consumers, d.consumer)
+	return true
+}
+
+type keyValueDecoder struct {
+	emptyDecoder
+	consumer *go3mf.Consumer
+}
+
+func (d *keyValueDecoder) Text(txt []byte) bool {
+	d.consumer.KeyValue += strings.TrimSpace(string(txt))
+	return true
+}
+
+type resourceDataGroupDecoder struct {
+	emptyDecoder
+	ks    *go3mf.KeyStore
+	group go3mf.ResourceDataGroup
+}
+
+func (d *resourceDataGroupDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrKeyUUID {
+			if err := validateUUID(a.Value); err != nil {
+				return d.file.parser.InvalidRequiredAttr(attrKeyUUID, a.Value)
+			}
+			d.group.KeyUUID = a.Value
+		}
+	}
+	if d.group.KeyUUID == "" {
+		return d.file.parser.MissingAttr(attrKeyUUID)
+	}
+	return true
+}
+
+func (d *resourceDataGroupDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsSecureContent {
+		if name.Local == attrAccessRight {
+			child = &accessRightDecoder{group: &d.group}
+		} else if name.Local == attrResourceData {
+			child = &resourceDataDecoder{group: &d.group}
+		}
+	}
+	return
+}
+
+func (d *resourceDataGroupDecoder) Close() bool {
+	d.ks.ResourceDataGroups = append(d.ks.ResourceDataGroups, d.group)
+	return true
+}
+
+type accessRightDecoder struct {
+	emptyDecoder
+	group       *go3mf.ResourceDataGroup
+	accessRight go3mf.AccessRight
+}
+
+func (d *accessRightDecoder) Attributes(attrs []xml.Attr) bool {
+	var hasIndex bool
+	ok := true
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrConsumerIndex {
+			hasIndex = true
+			d.accessRight.ConsumerIndex, ok = d.file.parser.ParseUint32Required(attrConsumerIndex, a.Value)
+		}
+	}
+	if ok && !hasIndex {
+		ok = d.file.parser.MissingAttr(attrConsumerIndex)
+	}
+	return ok
+}
+
+func (d *accessRightDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsSecureContent {
+		if name.Local == attrKEKParams {
+			child = &kekParamsDecoder{params: &d.accessRight.KEKParams}
+		} else if name.Local == attrCipherData {
+			child = &cipherDataDecoder{accessRight: &d.accessRight}
+		}
+	}
+	return
+}
+
+func (d *accessRightDecoder) Close() bool {
+	d.group.AccessRights = append(d.group.AccessRights, d.accessRight)
+	return true
+}
+
+type kekParamsDecoder struct {
+	emptyDecoder
+	params *go3mf.KEKParams
+}
+
+func (d *kekParamsDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrWrappingAlgorithm:
+			d.params.WrappingAlgorithm = a.Value
+		case attrMgfAlgorithm:
+			d.params.MgfAlgorithm = a.Value
+		case attrDigestMethod:
+			d.params.DigestMethod = a.Value
+		}
+	}
+	if d.params.WrappingAlgorithm == "" {
+		return d.file.parser.MissingAttr(attrWrappingAlgorithm)
+	}
+	return true
+}
+
+type cipherDataDecoder struct {
+	emptyDecoder
+	accessRight *go3mf.AccessRight
+}
+
+func (d *cipherDataDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsXMLEnc && name.Local == attrCipherValue {
+		child = &base64Decoder{name: attrCipherValue, value: &d.accessRight.CipherValue}
+	}
+	return
+}
+
+type resourceDataDecoder struct {
+	emptyDecoder
+	group        *go3mf.ResourceDataGroup
+	resourceData go3mf.ResourceData
+}
+
+func (d *resourceDataDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrPath {
+			d.resourceData.Path = a.Value
+		}
+	}
+	if d.resourceData.Path == "" {
+		return d.file.parser.MissingAttr(attrPath)
+	}
+	return true
+}
+
+func (d *resourceDataDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsSecureContent && name.Local == attrCEKParams {
+		child = &cekParamsDecoder{params: &d.resourceData.CEKParams}
+	}
+	return
+}
+
+func (d *resourceDataDecoder) Close() bool {
+	d.group.ResourceDatas = append(d.group.ResourceDatas, d.resourceData)
+	return true
+}
+
+type cekParamsDecoder struct {
+	emptyDecoder
+	params *go3mf.CEKParams
+}
+
+func (d *cekParamsDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrEncryptionAlgo:
+			d.params.EncryptionAlgorithm = a.Value
+		case attrCompression:
+			var ok bool
+			if d.params.Compression, ok = newCompression(a.Value); !ok {
+				d.file.parser.InvalidOptionalAttr(attrCompression, a.Value)
+			}
+		}
+	}
+	if d.params.EncryptionAlgorithm == "" {
+		return d.file.parser.MissingAttr(attrEncryptionAlgo)
+	}
+	return true
+}
+
+func (d *cekParamsDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsSecureContent {
+		switch name.Local {
+		case attrIV:
+			child = &base64Decoder{name: attrIV, value: &d.params.IV}
+		case attrTag:
+			child = &base64Decoder{name: attrTag, value: &d.params.Tag}
+		case attrAAD:
+			child = &base64Decoder{name: attrAAD, value: &d.params.AAD}
+		}
+	}
+	return
+}
+
+// base64Decoder decodes the base64 text of an element.
+type base64Decoder struct {
+	emptyDecoder
+	name  string
+	value *[]byte
+	text  []byte
+}
+
+func (d *base64Decoder) Text(txt []byte) bool {
+	d.text = append(d.text, txt...)
+	return true
+}
+
+func (d *base64Decoder) Close() bool {
+	s := strings.Join(strings.Fields(string(d.text)), "")
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return d.file.parser.InvalidRequiredAttr(d.name, s)
+	}
+	*d.value = b
+	return true
+}
@@ -0,0 +1,388 @@
+package io3mf
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"encoding/xml"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	go3mf "github.com/qmuntal/go3mf"
+)
+
+func newTestKeyStore() *go3mf.KeyStore {
+	return &go3mf.KeyStore{
+		UUID:      "ee2a5b3b-a5f2-4f3f-a4c9-0b2df9e1f1e3",
+		Consumers: []go3mf.Consumer{{ConsumerID: "consumer1", KeyID: "key1"}, {ConsumerID: "consumer2"}},
+		ResourceDataGroups: []go3mf.ResourceDataGroup{
+			{
+				KeyUUID:      "f4b8f9b5-8d2e-4a56-9f3d-9c3e0e5fa0f0",
+				AccessRights: []go3mf.AccessRight{{ConsumerIndex: 0}, {ConsumerIndex: 1, KEKParams: go3mf.KEKParams{DigestMethod: "http://www.w3.org/2001/04/xmlenc#sha256", MgfAlgorithm: "http://www.w3.org/2009/xmlenc11#mgf1sha256"}}},
+				ResourceDatas: []go3mf.ResourceData{
+					{Path: "/3d/3dmodel.model", CEKParams: go3mf.CEKParams{Compression: go3mf.CompressionDeflate}},
+					{Path: "/3D/Texture/other.png", CEKParams: go3mf.CEKParams{AAD: []byte("aad")}},
+				},
+			},
+			{
+				KeyUUID:       "0b7b5c3a-1d1c-4f2e-8c8e-3a1d1e2f3a4b",
+				AccessRights:  []go3mf.AccessRight{{ConsumerIndex: 1}},
+				ResourceDatas: []go3mf.ResourceData{{Path: "/3d/other.model"}},
+			},
+		},
+	}
+}
+
+func newTestKeyProvider(t *testing.T) *RSAKeyProvider {
+	p := &RSAKeyProvider{PrivateKeys: make(map[string]*rsa.PrivateKey)}
+	for _, id := range []string{"consumer1", "consumer2"} {
+		key, err := rsa.GenerateKey(rand.Reader, 1024)
+		if err != nil {
+			t.Fatalf("rsa.GenerateKey() unexpected error = %v", err)
+		}
+		p.PrivateKeys[id] = key
+	}
+	return p
+}
+
+func TestEncoder_roundTrip_SecureContent(t *testing.T) {
+	provider := newTestKeyProvider(t)
+	want := newFixtureModel()
+	want.Resources = append(want.Resources, &go3mf.Texture2DResource{ID: 1, ModelPath: "/3d/other.model", Path: "/3D/Texture/other.png", ContentType: go3mf.TextureTypePNG})
+	want.Attachments = append(want.Attachments, &go3mf.Attachment{RelationshipType: relTypeTexture3D, Path: "/3D/Texture/other.png", Stream: bytes.NewReader([]byte("other"))})
+	if err := new(Decoder).processRootModel(context.Background(), rootModelFixture().build(), want); err != nil {
+		t.Fatalf("Decoder.processRootModel() unexpected error = %v", err)
+	}
+	want.KeyStore = newTestKeyStore()
+	buff := new(bytes.Buffer)
+	e := NewEncoder(buff)
+	e.KeyProvider = provider
+	if err := e.Encode(want); err != nil {
+		t.Fatalf("Encoder.Encode() unexpected error = %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	if err != nil {
+		t.Fatalf("zip.NewReader() unexpected error = %v", err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "3d/3dmodel.model" && f.Name != "3d/other.model" && f.Name != "3D/Texture/other.png" {
+			continue
+		}
+		r, _ := f.Open()
+		content, _ := ioutil.ReadAll(r)
+		r.Close()
+		if bytes.Contains(content, []byte("<model")) || bytes.Equal(content, []byte("other")) {
+			t.Errorf("Encoder.Encode() part %s is not encrypted", f.Name)
+		}
+	}
+
+	got := new(go3mf.Model)
+	d := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+	d.KeyProvider = &RSAKeyProvider{PrivateKeys: provider.PrivateKeys}
+	if err := d.Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() unexpected error = %v", err)
+	}
+	if got.KeyStore == nil {
+		t.Fatal("Decoder.Decode() missing keystore")
+	}
+	for i, g := range got.KeyStore.ResourceDataGroups {
+		for j := range g.AccessRights {
+			if len(g.AccessRights[j].CipherValue) == 0 {
+				t.Errorf("Encoder.Encode() access right %d of group %d without cipher value", j, i)
+			}
+			g.AccessRights[j].CipherValue = nil
+		}
+		for j := range g.ResourceDatas {
+			if len(g.ResourceDatas[j].CEKParams.IV) != ivSize || len(g.ResourceDatas[j].CEKParams.Tag) != 16 {
+				t.Errorf("Encoder.Encode() resource data %s without IV or tag", g.ResourceDatas[j].Path)
+			}
+			g.ResourceDatas[j].CEKParams.IV = nil
+			g.ResourceDatas[j].CEKParams.Tag = nil
+		}
+	}
+	want.ProductionAttachments = []*go3mf.ProductionAttachment{
+		{RelationshipType: relTypeModel3D, Path: "/2D/2Dmodel.model"},
+		{RelationshipType: relTypeModel3D, Path: "/3d/other.model"},
+	}
+	wantKeyStore := newTestKeyStore()
+	for i := range wantKeyStore.ResourceDataGroups {
+		g := &wantKeyStore.ResourceDataGroups[i]
+		for j := range g.AccessRights {
+			g.AccessRights[j].KEKParams.WrappingAlgorithm = algRSAOAEP
+		}
+		for j := range g.ResourceDatas {
+			g.ResourceDatas[j].CEKParams.EncryptionAlgorithm = algAES256GCM
+		}
+	}
+	want.KeyStore = wantKeyStore
+	deep.CompareUnexportedFields = true
+	deep.MaxDepth = 20
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Encoder.Encode() = %v", diff)
+	}
+}
+
+func TestDecoder_Decode_SecureContent_Fail(t *testing.T) {
+	provider := newTestKeyProvider(t)
+	model := &go3mf.Model{KeyStore: &go3mf.KeyStore{
+		UUID:      "ee2a5b3b-a5f2-4f3f-a4c9-0b2df9e1f1e3",
+		Consumers: []go3mf.Consumer{{ConsumerID: "consumer1"}},
+		ResourceDataGroups: []go3mf.ResourceDataGroup{{
+			KeyUUID:       "f4b8f9b5-8d2e-4a56-9f3d-9c3e0e5fa0f0",
+			AccessRights:  []go3mf.AccessRight{{ConsumerIndex: 0}},
+			ResourceDatas: []go3mf.ResourceData{{Path: uriDefault3DModel}},
+		}},
+	}}
+	buff := new(bytes.Buffer)
+	e := NewEncoder(buff)
+	e.KeyProvider = provider
+	if err := e.Encode(model); err != nil {
+		t.Fatalf("Encoder.Encode() unexpected error = %v", err)
+	}
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	tests := []struct {
+		name string
+		p    KeyProvider
+	}{
+		{"noProvider", nil},
+		{"noPrivateKey", &RSAKeyProvider{}},
+		{"wrongKey", &RSAKeyProvider{PrivateKeys: map[string]*rsa.PrivateKey{"consumer1": otherKey}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+			d.KeyProvider = tt.p
+			if err := d.Decode(new(go3mf.Model)); err == nil {
+				t.Error("Decoder.Decode() expected error")
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode_SecureContent_Fail(t *testing.T) {
+	tests := []struct {
+		name string
+		ks   *go3mf.KeyStore
+		p    KeyProvider
+	}{
+		{"noProvider", new(go3mf.KeyStore), nil},
+		{"invalidConsumer", &go3mf.KeyStore{ResourceDataGroups: []go3mf.ResourceDataGroup{{AccessRights: []go3mf.AccessRight{{ConsumerIndex: 1}}}}}, new(RSAKeyProvider)},
+		{"noPublicKey", &go3mf.KeyStore{Consumers: []go3mf.Consumer{{ConsumerID: "a"}}, ResourceDataGroups: []go3mf.ResourceDataGroup{{AccessRights: []go3mf.AccessRight{{ConsumerIndex: 0}}}}}, new(RSAKeyProvider)},
+		{"invalidKeyValue", &go3mf.KeyStore{Consumers: []go3mf.Consumer{{ConsumerID: "a", KeyValue: "a"}}, ResourceDataGroups: []go3mf.ResourceDataGroup{{AccessRights: []go3mf.AccessRight{{ConsumerIndex: 0}}}}}, new(RSAKeyProvider)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewEncoder(new(bytes.Buffer))
+			e.KeyProvider = tt.p
+			if err := e.Encode(&go3mf.Model{KeyStore: tt.ks}); err == nil {
+				t.Error("Encoder.Encode() expected error")
+			}
+		})
+	}
+}
+
+func TestRSAKeyProvider_publicKey(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 1024)
+	der, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	keyValue := string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))
+	p := &RSAKeyProvider{PublicKeys: map[string]*rsa.PublicKey{"pub": &key.PublicKey}, PrivateKeys: map[string]*rsa.PrivateKey{"priv": key}}
+	tests := []struct {
+		name    string
+		c       go3mf.Consumer
+		wantErr bool
+	}{
+		{"public", go3mf.Consumer{ConsumerID: "pub"}, false},
+		{"private", go3mf.Consumer{ConsumerID: "priv"}, false},
+		{"keyValue", go3mf.Consumer{ConsumerID: "other", KeyValue: keyValue}, false},
+		{"invalidKeyValue", go3mf.Consumer{ConsumerID: "other", KeyValue: "-----BEGIN PUBLIC KEY-----\nAA==\n-----END PUBLIC KEY-----"}, true},
+		{"missing", go3mf.Consumer{ConsumerID: "other"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := p.publicKey(tt.c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("RSAKeyProvider.publicKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.N.Cmp(key.N) != 0 {
+				t.Errorf("RSAKeyProvider.publicKey() = %v, want %v", got, &key.PublicKey)
+			}
+		})
+	}
+}
+
+func Test_oaepHash(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   go3mf.KEKParams
+		wantSize int
+		wantErr  bool
+	}{
+		{"default", go3mf.KEKParams{}, 20, false},
+		{"mgf1p", go3mf.KEKParams{WrappingAlgorithm: algRSAOAEPMGF}, 20, false},
+		{"sha256", go3mf.KEKParams{WrappingAlgorithm: algRSAOAEP, DigestMethod: "http://www.w3.org/2001/04/xmlenc#sha256", MgfAlgorithm: "http://www.w3.org/2009/xmlenc11#mgf1sha256"}, 32, false},
+		{"sha384", go3mf.KEKParams{DigestMethod: "http://www.w3.org/2001/04/xmldsig-more#sha384", MgfAlgorithm: "http://www.w3.org/2009/xmlenc11#mgf1sha384"}, 48, false},
+		{"sha512", go3mf.KEKParams{DigestMethod: "http://www.w3.org/2001/04/xmlenc#sha512", MgfAlgorithm: "http://www.w3.org/2009/xmlenc11#mgf1sha512"}, 64, false},
+		{"algorithm", go3mf.KEKParams{WrappingAlgorithm: "a"}, 0, true},
+		{"digest", go3mf.KEKParams{DigestMethod: "a"}, 0, true},
+		{"mgf", go3mf.KEKParams{MgfAlgorithm: "a"}, 0, true},
+		{"mixed", go3mf.KEKParams{DigestMethod: "http://www.w3.org/2001/04/xmlenc#sha256"}, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := oaepHash(tt.params)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("oaepHash() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got.Size() != tt.wantSize {
+				t.Errorf("oaepHash() = %v, want %v", got.Size(), tt.wantSize)
+			}
+		})
+	}
+}
+
+func Test_decryptContent(t *testing.T) {
+	key := make([]byte, cekSize)
+	params := go3mf.CEKParams{EncryptionAlgorithm: algAES256GCM, Compression: go3mf.CompressionDeflate}
+	data, err := encryptContent(key, &params, []byte("content"))
+	if err != nil {
+		t.Fatalf("encryptContent() unexpected error = %v", err)
+	}
+	tests := []struct {
+		name    string
+		key     []byte
+		params  go3mf.CEKParams
+		want    string
+		wantErr bool
+	}{
+		{"base", key, params, "content", false},
+		{"algorithm", key, go3mf.CEKParams{EncryptionAlgorithm: "a", IV: params.IV, Tag: params.Tag}, "", true},
+		{"noIV", key, go3mf.CEKParams{EncryptionAlgorithm: algAES256GCM, Tag: params.Tag}, "", true},
+		{"key", make([]byte, 3), params, "", true},
+		{"tag", key, go3mf.CEKParams{EncryptionAlgorithm: algAES256GCM, IV: params.IV, Tag: make([]byte, 16)}, "", true},
+		{"compression", key, go3mf.CEKParams{EncryptionAlgorithm: algAES256GCM, IV: params.IV, Tag: params.Tag}, "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := decryptContent(tt.key, tt.params, append([]byte(nil), data...))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("decryptContent() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.want != "" && string(got) != tt.want {
+				t.Errorf("decryptContent() = %v, want %v", string(got), tt.want)
+			}
+		})
+	}
+}
+
+func Test_keyStoreFileDecoder(t *testing.T) {
+	keystore := `<keystore xmlns="http://schemas.microsoft.com/3dmanufacturing/securecontent/2019/04" xmlns:xenc="http://www.w3.org/2001/04/xmlenc#" UUID="ee2a5b3b-a5f2-4f3f-a4c9-0b2df9e1f1e3">
+		<consumer consumerid="HP#MOP44B#SG5693454" keyid="HP#9823423">
+			<keyvalue>
+				-----BEGIN PUBLIC KEY-----
+			</keyvalue>
+		</consumer>
+		<resourcedatagroup keyuuid="f4b8f9b5-8d2e-4a56-9f3d-9c3e0e5fa0f0">
+			<accessright consumerindex="0">
+				<kekparams wrappingalgorithm="http://www.w3.org/2009/xmlenc11#rsa-oaep" mgfalgorithm="http://www.w3.org/2009/xmlenc11#mgf1sha1" digestmethod="http://www.w3.org/2000/09/xmldsig#sha1"/>
+				<cipherdata>
+					<xenc:CipherValue>
+						YWJj
+						ZGVm
+					</xenc:CipherValue>
+				</cipherdata>
+			</accessright>
+			<resourcedata path="/3D/3dmodel.model">
+				<cekparams encryptionalgorithm="http://www.w3.org/2009/xmlenc11#aes256-gcm" compression="deflate">
+					<iv>aXY=</iv>
+					<tag>dGFn</tag>
+					<aad>YWFk</aad>
+				</cekparams>
+			</resourcedata>
+		</resourcedatagroup>
+	</keystore>`
+	want := &go3mf.KeyStore{
+		UUID:      "ee2a5b3b-a5f2-4f3f-a4c9-0b2df9e1f1e3",
+		Consumers: []go3mf.Consumer{{ConsumerID: "HP#MOP44B#SG5693454", KeyID: "HP#9823423", KeyValue: "-----BEGIN PUBLIC KEY-----"}},
+		ResourceDataGroups: []go3mf.ResourceDataGroup{{
+			KeyUUID: "f4b8f9b5-8d2e-4a56-9f3d-9c3e0e5fa0f0",
+			AccessRights: []go3mf.AccessRight{{
+				ConsumerIndex: 0,
+				KEKParams:     go3mf.KEKParams{WrappingAlgorithm: algRSAOAEP, MgfAlgorithm: "http://www.w3.org/2009/xmlenc11#mgf1sha1", DigestMethod: "http://www.w3.org/2000/09/xmldsig#sha1"},
+				CipherValue:   []byte("abcdef"),
+			}},
+			ResourceDatas: []go3mf.ResourceData{{
+				Path:      "/3D/3dmodel.model",
+				CEKParams: go3mf.CEKParams{EncryptionAlgorithm: algAES256GCM, Compression: go3mf.CompressionDeflate, IV: []byte("iv"), Tag: []byte("tag"), AAD: []byte("aad")},
+			}},
+		}},
+	}
+	got := new(go3mf.KeyStore)
+	mf := modelFile{path: uriKeyStore, strict: true}
+	if err := mf.decode(context.Background(), xml.NewDecoder(strings.NewReader(keystore)), &keyStoreFileDecoder{ks: got}); err != nil {
+		t.Fatalf("modelFile.decode() unexpected error = %v", err)
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("modelFile.decode() = %v", diff)
+	}
+
+	buff := new(bytes.Buffer)
+	if err := writeKeyStore(buff, want); err != nil {
+		t.Fatalf("writeKeyStore() unexpected error = %v", err)
+	}
+	got = new(go3mf.KeyStore)
+	if err := mf.decode(context.Background(), xml.NewDecoder(buff), &keyStoreFileDecoder{ks: got}); err != nil {
+		t.Fatalf("modelFile.decode() unexpected error = %v", err)
+	}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("writeKeyStore() = %v", diff)
+	}
+}
+
+func Test_keyStoreFileDecoder_warns(t *testing.T) {
+	want := []error{
+		ParsePropertyError{ModelPath: uriKeyStore, Element: "keystore", Name: "UUID", Value: "a", Type: PropertyRequired},
+		MissingPropertyError{ModelPath: uriKeyStore, Element: "consumer", Name: "consumerid"},
+		MissingPropertyError{ModelPath: uriKeyStore, Element: "resourcedatagroup", Name: "keyuuid"},
+		ParsePropertyError{ModelPath: uriKeyStore, Element: "resourcedatagroup", Name: "keyuuid", Value: "a", Type: PropertyRequired},
+		MissingPropertyError{ModelPath: uriKeyStore, Element: "accessright", Name: "consumerindex"},
+		ParsePropertyError{ModelPath: uriKeyStore, Element: "accessright", Name: "consumerindex", Value: "a", Type: PropertyRequired},
+		MissingPropertyError{ModelPath: uriKeyStore, Element: "kekparams", Name: "wrappingalgorithm"},
+		ParsePropertyError{ModelPath: uriKeyStore, Element: "CipherValue", Name: "CipherValue", Value: "*", Type: PropertyRequired},
+		MissingPropertyError{ModelPath: uriKeyStore, Element: "resourcedata", Name: "path"},
+		ParsePropertyError{ModelPath: uriKeyStore, Element: "cekparams", Name: "compression", Value: "a", Type: PropertyOptional},
+		MissingPropertyError{ModelPath: uriKeyStore, Element: "cekparams", Name: "encryptionalgorithm"},
+		ParsePropertyError{ModelPath: uriKeyStore, Element: "iv", Name: "iv", Value: "*", Type: PropertyRequired},
+	}
+	keystore := `<keystore xmlns="http://schemas.microsoft.com/3dmanufacturing/securecontent/2019/04" xmlns:xenc="http://www.w3.org/2001/04/xmlenc#" UUID="a">
+		<consumer />
+		<resourcedatagroup />
+		<resourcedatagroup keyuuid="a">
+			<accessright />
+			<accessright consumerindex="a">
+				<kekparams />
+				<cipherdata><xenc:CipherValue>*</xenc:CipherValue></cipherdata>
+			</accessright>
+			<resourcedata />
+			<resourcedata path="/3D/3dmodel.model">
+				<cekparams compression="a" />
+				<cekparams encryptionalgorithm="a"><iv>*</iv></cekparams>
+			</resourcedata>
+		</resourcedatagroup>
+	</keystore>`
+	mf := modelFile{path: uriKeyStore}
+	if err := mf.decode(context.Background(), xml.NewDecoder(strings.NewReader(keystore)), &keyStoreFileDecoder{ks: new(go3mf.KeyStore)}); err != nil {
+		t.Fatalf("modelFile.decode() unexpected error = %v", err)
+	}
+	if diff := deep.Equal(mf.parser.Warnings, want); diff != nil {
+		t.Errorf("modelFile.decode() = %v", diff)
+	}
+}
@@ -0,0 +1,531 @@
+package io3mf
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/qmuntal/opc"
+)
+
+const (
+	nsXMLDSig                = "http://www.w3.org/2000/09/xmldsig#"
+	nsRelationships          = "http://schemas.openxmlformats.org/package/2006/relationships"
+	algRelationshipTransform = "http://schemas.openxmlformats.org/package/2006/RelationshipTransform"
+	contentTypeRelationships = "application/vnd.openxmlformats-package.relationships+xml"
+	contentTypesName         = "/[Content_Types].xml"
+)
+
+// VerifyOptions defines how the signing certificates are validated.
+type VerifyOptions struct {
+	// Roots is the trust pool the signing certificates must chain up to.
+	// The system roots are never used, so a nil pool makes every signature untrusted.
+	Roots *x509.CertPool
+	// CurrentTime is the time the certificates are validated against.
+	// If zero, the current time is used.
+	CurrentTime time.Time
+}
+
+// A SignedPart is a package part referenced by a signature.
+type SignedPart struct {
+	Path string
+	// Valid is false when the part is missing or its content does not match the signed digest.
+	Valid bool
+}
+
+// A PackageSignature is the verification result of an OPC digital signature.
+type PackageSignature struct {
+	Path        string
+	Certificate *x509.Certificate
+	Parts       []SignedPart
+	// TrustErr is not nil when the certificate does not chain up to the trust pool.
+	TrustErr error
+	// Err is not nil when the signature cannot be verified with the certificate.
+	Err error
+}
+
+// Verified returns true if the signature is trusted, valid and all its parts are unchanged.
+func (s *PackageSignature) Verified() bool {
+	if s.TrustErr != nil || s.Err != nil {
+		return false
+	}
+	for _, p := range s.Parts {
+		if !p.Valid {
+			return false
+		}
+	}
+	return true
+}
+
+// A SignatureReport lists the signatures of a package
+// and the parts that are not covered by any verified signature.
+type SignatureReport struct {
+	Signatures    []PackageSignature
+	UnsignedParts []string
+}
+
+// Verified returns true if the package is signed,
+// all the signatures are verified and there are no unsigned parts.
+func (r *SignatureReport) Verified() bool {
+	if len(r.Signatures) == 0 || len(r.UnsignedParts) > 0 {
+		return false
+	}
+	for i := range r.Signatures {
+		if !r.Signatures[i].Verified() {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifySignatures verifies the XML digital signatures of the OPC package read from r.
+// Parts added or modified after signing are reported, so a tampered package can be refused.
+// The signature parts, the origin part and the content types are not expected to be signed.
+// It does not need network access, as certificates are only checked against opts.Roots.
+func VerifySignatures(r io.ReaderAt, size int64, opts VerifyOptions) (*SignatureReport, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	or, err := opc.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	v := signatureVerifier{zr: zr, or: or, opts: opts}
+	return v.verify(), nil
+}
+
+type signatureVerifier struct {
+	zr   *zip.Reader
+	or   *opc.Reader
+	opts VerifyOptions
+}
+
+func (v *signatureVerifier) verify() *SignatureReport {
+	report := new(SignatureReport)
+	infrastructure := map[string]bool{strings.ToLower(contentTypesName): true}
+	signed := make(map[string]bool)
+	if origin, ok := v.findFile(findOPCFileURIFromRel(relTypeSigOrigin, v.or.Relationships)); ok {
+		infrastructure[strings.ToLower(origin.Name)] = true
+		infrastructure[strings.ToLower(relationshipsPath(origin.Name))] = true
+		for _, rel := range origin.Relationships {
+			if rel.Type != relTypeSignature {
+				continue
+			}
+			name := opc.ResolveRelationship(origin.Name, rel.TargetURI)
+			infrastructure[strings.ToLower(name)] = true
+			infrastructure[strings.ToLower(relationshipsPath(name))] = true
+			sig := v.verifySignature(name, infrastructure)
+			if sig.Verified() {
+				for _, p := range sig.Parts {
+					signed[strings.ToLower(p.Path)] = true
+				}
+			}
+			report.Signatures = append(report.Signatures, sig)
+		}
+	}
+	for _, f := range v.zr.File {
+		name := "/" + f.Name
+		if strings.HasSuffix(name, "/") || infrastructure[strings.ToLower(name)] || signed[strings.ToLower(name)] {
+			continue
+		}
+		report.UnsignedParts = append(report.UnsignedParts, name)
+	}
+	sort.Strings(report.UnsignedParts)
+	return report
+}
+
+func (v *signatureVerifier) verifySignature(name string, infrastructure map[string]bool) PackageSignature {
+	sig := PackageSignature{Path: name}
+	data, err := v.readPart(name)
+	if err != nil {
+		sig.Err = err
+		return sig
+	}
+	var xs xmlSignature
+	if err = xml.Unmarshal(data, &xs); err != nil {
+		sig.Err = err
+		return sig
+	}
+	if err = checkSignatureIDs(data); err != nil {
+		sig.Err = err
+		return sig
+	}
+	certs, err := v.certificates(name, &xs, infrastructure)
+	if err != nil {
+		sig.Err = err
+		return sig
+	}
+	sig.Certificate = certs[0]
+	sig.TrustErr = v.verifyChain(certs)
+	signedInfo, err := verifySignedInfo(data, xs.SignatureValue, sig.Certificate)
+	if err != nil {
+		sig.Err = err
+		return sig
+	}
+	for _, ref := range signedInfo.References {
+		if err = v.verifyObject(data, ref, &sig); err != nil {
+			sig.Err = err
+			return sig
+		}
+	}
+	return sig
+}
+
+// certificates returns the signing certificate followed by the rest of certificates
+// embedded in the signature, or the one stored in a certificate part.
+func (v *signatureVerifier) certificates(name string, xs *xmlSignature, infrastructure map[string]bool) ([]*x509.Certificate, error) {
+	var ders [][]byte
+	for _, c := range xs.KeyInfo.X509Data.Certificates {
+		der, err := decodeBase64(c)
+		if err != nil {
+			return nil, err
+		}
+		ders = append(ders, der)
+	}
+	if f, ok := v.findFile(name); ok {
+		for _, rel := range f.Relationships {
+			if rel.Type != relTypeSigCert {
+				continue
+			}
+			certName := opc.ResolveRelationship(name, rel.TargetURI)
+			infrastructure[strings.ToLower(certName)] = true
+			der, err := v.readPart(certName)
+			if err != nil {
+				return nil, err
+			}
+			ders = append(ders, der)
+		}
+	}
+	if len(ders) == 0 {
+		return nil, errors.New("go3mf: signature without certificate")
+	}
+	certs := make([]*x509.Certificate, len(ders))
+	for i, der := range ders {
+		cert, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, err
+		}
+		certs[i] = cert
+	}
+	return certs, nil
+}
+
+func (v *signatureVerifier) verifyChain(certs []*x509.Certificate) error {
+	if v.opts.Roots == nil {
+		return errors.New("go3mf: missing trust pool")
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs[1:] {
+		intermediates.AddCert(c)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         v.opts.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   v.opts.CurrentTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+// verifySignedInfo checks the signature value and returns the SignedInfo element
+// read from the same canonical bytes, so only the signed references are trusted.
+func verifySignedInfo(data []byte, signatureValue string, cert *x509.Certificate) (*xmlSignedInfo, error) {
+	signedInfo, err := canonicalize(data, nsXMLDSig, "SignedInfo", "")
+	if err != nil {
+		return nil, err
+	}
+	var si xmlSignedInfo
+	if err = xml.Unmarshal(signedInfo, &si); err != nil {
+		return nil, err
+	}
+	if si.CanonicalizationMethod.Algorithm != algC14N {
+		return nil, fmt.Errorf("go3mf: unsupported canonicalization method '%s'", si.CanonicalizationMethod.Algorithm)
+	}
+	algo, ok := newSignatureMethod(si.SignatureMethod.Algorithm)
+	if !ok {
+		return nil, fmt.Errorf("go3mf: unsupported signature method '%s'", si.SignatureMethod.Algorithm)
+	}
+	value, err := decodeBase64(signatureValue)
+	if err != nil {
+		return nil, err
+	}
+	switch algo {
+	case x509.ECDSAWithSHA1, x509.ECDSAWithSHA256, x509.ECDSAWithSHA384, x509.ECDSAWithSHA512:
+		// XML signatures store the raw r and s values, x509 expects them ASN.1 encoded.
+		half := len(value) / 2
+		value, err = asn1.Marshal(struct{ R, S *big.Int }{new(big.Int).SetBytes(value[:half]), new(big.Int).SetBytes(value[half:])})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err = cert.CheckSignature(algo, signedInfo, value); err != nil {
+		return nil, err
+	}
+	return &si, nil
+}
+
+// verifyObject checks the digest of a signed object and of the parts listed in its manifest.
+// The manifest is read from the digested element, so no other element can add parts to it.
+func (v *signatureVerifier) verifyObject(data []byte, ref xmlReference, sig *PackageSignature) error {
+	if !strings.HasPrefix(ref.URI, "#") {
+		return fmt.Errorf("go3mf: unsupported signature reference '%s'", ref.URI)
+	}
+	id := ref.URI[1:]
+	object, err := canonicalize(data, nsXMLDSig, "Object", id)
+	if err != nil {
+		return err
+	}
+	for _, t := range ref.Transforms {
+		if t.Algorithm != algC14N {
+			return fmt.Errorf("go3mf: unsupported transform '%s'", t.Algorithm)
+		}
+	}
+	if err = checkDigest(ref, object); err != nil {
+		return err
+	}
+	var xo xmlObject
+	if err = xml.Unmarshal(object, &xo); err != nil {
+		return err
+	}
+	for _, r := range xo.Manifest.References {
+		sig.Parts = append(sig.Parts, v.verifyPart(r))
+	}
+	return nil
+}
+
+// checkSignatureIDs rejects the signatures whose Id attributes are not unique,
+// that have more than one SignedInfo element
+// or that have an Object element outside the XML-DSig namespace,
+// which could otherwise be confused with the signed elements.
+func checkSignatureIDs(data []byte) error {
+	x := xml.NewDecoder(bytes.NewReader(data))
+	ids := make(map[string]struct{})
+	var signedInfo bool
+	for {
+		t, err := x.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		start, ok := t.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Local == "Object" && start.Name.Space != nsXMLDSig {
+			return errors.New("go3mf: signature object outside the XML-DSig namespace")
+		}
+		if start.Name.Local == "SignedInfo" {
+			if signedInfo {
+				return errors.New("go3mf: signature with more than one SignedInfo")
+			}
+			signedInfo = true
+		}
+		for _, a := range start.Attr {
+			if a.Name.Space != "" || a.Name.Local != "Id" {
+				continue
+			}
+			if _, ok := ids[a.Value]; ok {
+				return fmt.Errorf("go3mf: duplicated signature id '%s'", a.Value)
+			}
+			ids[a.Value] = struct{}{}
+		}
+	}
+}
+
+func (v *signatureVerifier) verifyPart(ref xmlReference) SignedPart {
+	name, contentType := ref.URI, ""
+	if i := strings.IndexByte(name, '?'); i >= 0 {
+		name, contentType = name[:i], strings.TrimPrefix(name[i+1:], "ContentType=")
+	}
+	part := SignedPart{Path: name}
+	data, err := v.readPart(name)
+	if err != nil || (contentType != "" && contentType != v.contentType(name)) {
+		return part
+	}
+	for _, t := range ref.Transforms {
+		switch t.Algorithm {
+		case algRelationshipTransform:
+			data, err = transformRelationships(data, t)
+		case algC14N:
+			data, err = canonicalize(data, "", "", "")
+		default:
+			err = fmt.Errorf("go3mf: unsupported transform '%s'", t.Algorithm)
+		}
+		if err != nil {
+			return part
+		}
+	}
+	part.Valid = checkDigest(ref, data) == nil
+	return part
+}
+
+func (v *signatureVerifier) findFile(name string) (*opc.File, bool) {
+	for _, f := range v.or.Files {
+		if strings.EqualFold(f.Name, name) {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func (v *signatureVerifier) contentType(name string) string {
+	if strings.HasSuffix(strings.ToLower(name), ".rels") {
+		return contentTypeRelationships
+	}
+	if f, ok := v.findFile(name); ok {
+		return f.ContentType
+	}
+	return ""
+}
+
+// readPart returns the raw content of a part, which includes relationship parts.
+func (v *signatureVerifier) readPart(name string) ([]byte, error) {
+	for _, f := range v.zr.File {
+		if strings.EqualFold("/"+f.Name, name) {
+			r, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			defer r.Close()
+			return ioutil.ReadAll(r)
+		}
+	}
+	return nil, fmt.Errorf("go3mf: missing part '%s'", name)
+}
+
+func relationshipsPath(name string) string {
+	dir, file := path.Split(name)
+	return dir + "_rels/" + file + ".rels"
+}
+
+func checkDigest(ref xmlReference, data []byte) error {
+	h, ok := newDigestMethod(ref.DigestMethod.Algorithm)
+	if !ok {
+		return fmt.Errorf("go3mf: unsupported digest method '%s'", ref.DigestMethod.Algorithm)
+	}
+	want, err := decodeBase64(ref.DigestValue)
+	if err != nil {
+		return err
+	}
+	d := h.New()
+	d.Write(data)
+	if !bytes.Equal(d.Sum(nil), want) {
+		return fmt.Errorf("go3mf: digest mismatch of reference '%s'", ref.URI)
+	}
+	return nil
+}
+
+// transformRelationships applies the OPC relationship transform,
+// which keeps the selected relationships sorted by ID in canonical form.
+func transformRelationships(data []byte, t xmlTransform) ([]byte, error) {
+	var rels struct {
+		Relationships []struct {
+			ID         string `xml:"Id,attr"`
+			Type       string `xml:",attr"`
+			Target     string `xml:",attr"`
+			TargetMode string `xml:",attr"`
+		} `xml:"Relationship"`
+	}
+	if err := xml.Unmarshal(data, &rels); err != nil {
+		return nil, err
+	}
+	selected := rels.Relationships[:0]
+	for _, r := range rels.Relationships {
+		if t.selects(r.ID, r.Type) {
+			selected = append(selected, r)
+		}
+	}
+	sort.Slice(selected, func(i, j int) bool { return selected[i].ID < selected[j].ID })
+	var out bytes.Buffer
+	out.WriteString(`<Relationships xmlns="` + nsRelationships + `">`)
+	for _, r := range selected {
+		if r.TargetMode == "" {
+			r.TargetMode = "Internal"
+		}
+		fmt.Fprintf(&out, `<Relationship Id="%s" Target="%s" TargetMode="%s" Type="%s"></Relationship>`,
+			escapeC14NAttr(r.ID), escapeC14NAttr(r.Target), escapeC14NAttr(r.TargetMode), escapeC14NAttr(r.Type))
+	}
+	out.WriteString("</Relationships>")
+	return out.Bytes(), nil
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.StdEncoding.DecodeString(strings.Join(strings.Fields(s), ""))
+}
+
+type xmlAlgorithm struct {
+	Algorithm string `xml:",attr"`
+}
+
+type xmlTransform struct {
+	Algorithm   string          `xml:",attr"`
+	SourceIDs   []xmlSourceID   `xml:"RelationshipReference"`
+	SourceTypes []xmlSourceType `xml:"RelationshipsGroupReference"`
+}
+
+type xmlSourceID struct {
+	SourceID string `xml:"SourceId,attr"`
+}
+
+type xmlSourceType struct {
+	SourceType string `xml:",attr"`
+}
+
+func (t *xmlTransform) selects(id, relType string) bool {
+	for _, s := range t.SourceIDs {
+		if s.SourceID == id {
+			return true
+		}
+	}
+	for _, s := range t.SourceTypes {
+		if s.SourceType == relType {
+			return true
+		}
+	}
+	return false
+}
+
+type xmlReference struct {
+	URI          string         `xml:",attr"`
+	Transforms   []xmlTransform `xml:"http://www.w3.org/2000/09/xmldsig# Transforms>Transform"`
+	DigestMethod xmlAlgorithm   `xml:"http://www.w3.org/2000/09/xmldsig# DigestMethod"`
+	DigestValue  string         `xml:"http://www.w3.org/2000/09/xmldsig# DigestValue"`
+}
+
+type xmlSignature struct {
+	SignatureValue string `xml:"http://www.w3.org/2000/09/xmldsig# SignatureValue"`
+	KeyInfo        struct {
+		X509Data struct {
+			Certificates []string `xml:"http://www.w3.org/2000/09/xmldsig# X509Certificate"`
+		} `xml:"http://www.w3.org/2000/09/xmldsig# X509Data"`
+	} `xml:"http://www.w3.org/2000/09/xmldsig# KeyInfo"`
+}
+
+type xmlSignedInfo struct {
+	XMLName                xml.Name       `xml:"http://www.w3.org/2000/09/xmldsig# SignedInfo"`
+	CanonicalizationMethod xmlAlgorithm   `xml:"http://www.w3.org/2000/09/xmldsig# CanonicalizationMethod"`
+	SignatureMethod        xmlAlgorithm   `xml:"http://www.w3.org/2000/09/xmldsig# SignatureMethod"`
+	References             []xmlReference `xml:"http://www.w3.org/2000/09/xmldsig# Reference"`
+}
+
+type xmlObject struct {
+	XMLName  xml.Name `xml:"http://www.w3.org/2000/09/xmldsig# Object"`
+	Manifest struct {
+		References []xmlReference `xml:"http://www.w3.org/2000/09/xmldsig# Reference"`
+	} `xml:"http://www.w3.org/2000/09/xmldsig# Manifest"`
+}
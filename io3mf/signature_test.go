@@ -0,0 +1,317 @@
+package io3mf
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+	"github.com/qmuntal/opc"
+)
+
+const (
+	testSignaturePath = "/package/services/digital-signature/xml-signature/sig.psdsxs"
+	testOriginPath    = "/package/services/digital-signature/origin.psdsor"
+)
+
+type testPart struct {
+	name, contentType, content string
+}
+
+// packageSigner builds a signed package following the OPC digital signature layout.
+type packageSigner struct {
+	key       crypto.Signer
+	method    string
+	cert      []byte
+	parts     []testPart
+	signed    []string
+	signRels  bool
+	tampered  map[string]string
+	extraRel  bool
+	badValue  bool
+	noOrigin  bool
+	certParts bool
+	// wrapping is appended to the signature after the signed object.
+	wrapping string
+}
+
+func sha256Base64(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// element writes the element in canonical form or in an equivalent non-canonical one.
+func element(canonical bool, name, attrs, content string) string {
+	if !canonical && content == "" {
+		return "<" + name + attrs + " />"
+	}
+	return "<" + name + attrs + ">" + content + "</" + name + ">"
+}
+
+func (s *packageSigner) object(canonical bool) string {
+	var refs string
+	for _, name := range s.signed {
+		for _, p := range s.parts {
+			if p.name == name {
+				refs += element(canonical, "Reference", ` URI="`+p.name+`?ContentType=`+p.contentType+`"`,
+					element(canonical, "DigestMethod", ` Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"`, "")+
+						element(canonical, "DigestValue", "", sha256Base64(p.content)))
+			}
+		}
+	}
+	if s.signRels {
+		rels := `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+			`<Relationship Id="rel0" Target="/3D/3dmodel.model" TargetMode="Internal" Type="` + relTypeModel3D + `"></Relationship></Relationships>`
+		transforms := element(canonical, "Transform", ` Algorithm="`+algRelationshipTransform+`"`,
+			element(canonical, "mdssi:RelationshipsGroupReference", ` xmlns:mdssi="http://schemas.openxmlformats.org/package/2006/digital-signature" SourceType="`+relTypeModel3D+`"`, ""))
+		transforms += element(canonical, "Transform", ` Algorithm="`+algC14N+`"`, "")
+		refs += element(canonical, "Reference", ` URI="/_rels/.rels?ContentType=`+contentTypeRelationships+`"`,
+			element(canonical, "Transforms", "", transforms)+
+				element(canonical, "DigestMethod", ` Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"`, "")+
+				element(canonical, "DigestValue", "", sha256Base64(rels)))
+	}
+	ns := ""
+	if canonical {
+		ns = ` xmlns="` + nsXMLDSig + `"`
+	}
+	return element(canonical, "Object", ns+` Id="idPackageObject"`, element(canonical, "Manifest", "", refs))
+}
+
+func (s *packageSigner) signedInfo(canonical bool) string {
+	refAttrs := ` URI="#idPackageObject" Type="http://www.w3.org/2000/09/xmldsig#Object"`
+	ns := ""
+	if canonical {
+		refAttrs = ` Type="http://www.w3.org/2000/09/xmldsig#Object" URI="#idPackageObject"`
+		ns = ` xmlns="` + nsXMLDSig + `"`
+	}
+	return element(canonical, "SignedInfo", ns,
+		element(canonical, "CanonicalizationMethod", ` Algorithm="`+algC14N+`"`, "")+
+			element(canonical, "SignatureMethod", ` Algorithm="`+s.method+`"`, "")+
+			element(canonical, "Reference", refAttrs,
+				element(canonical, "DigestMethod", ` Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"`, "")+
+					element(canonical, "DigestValue", "", sha256Base64(s.object(true)))))
+}
+
+func (s *packageSigner) signature(t *testing.T) string {
+	digest := sha256.Sum256([]byte(s.signedInfo(true)))
+	value, err := s.key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("Sign() unexpected error = %v", err)
+	}
+	if _, ok := s.key.(*ecdsa.PrivateKey); ok {
+		var sig struct{ R, S *big.Int }
+		asn1.Unmarshal(value, &sig)
+		value = make([]byte, 64)
+		r, s := sig.R.Bytes(), sig.S.Bytes()
+		copy(value[32-len(r):], r)
+		copy(value[64-len(s):], s)
+	}
+	if s.badValue {
+		value[0] ^= 0xff
+	}
+	var keyInfo string
+	if !s.certParts {
+		keyInfo = "<KeyInfo><X509Data><X509Certificate>" + base64.StdEncoding.EncodeToString(s.cert) + "</X509Certificate></X509Data></KeyInfo>"
+	}
+	return `<?xml version="1.0" encoding="UTF-8"?><Signature xmlns="` + nsXMLDSig + `" Id="idSignature">` + s.signedInfo(false) +
+		"<SignatureValue>\n" + base64.StdEncoding.EncodeToString(value) + "\n</SignatureValue>" + keyInfo + s.object(false) + s.wrapping + "</Signature>"
+}
+
+func (s *packageSigner) build(t *testing.T) []byte {
+	buff := new(bytes.Buffer)
+	w := opc.NewWriter(buff)
+	w.Relationships = []*opc.Relationship{{ID: "rel0", Type: relTypeModel3D, TargetURI: "/3D/3dmodel.model"}}
+	if !s.noOrigin {
+		w.Relationships = append(w.Relationships, &opc.Relationship{ID: "rel1", Type: relTypeSigOrigin, TargetURI: testOriginPath})
+	}
+	if s.extraRel {
+		w.Relationships = append(w.Relationships, &opc.Relationship{ID: "rel2", Type: relTypeModel3D, TargetURI: "/3D/other.model"})
+	}
+	write := func(part *opc.Part, content string) {
+		pw, err := w.CreatePart(part, opc.CompressionNormal)
+		if err != nil {
+			t.Fatalf("opc.Writer.CreatePart() unexpected error = %v", err)
+		}
+		pw.Write([]byte(content))
+	}
+	for _, p := range s.parts {
+		content := p.content
+		if c, ok := s.tampered[p.name]; ok {
+			content = c
+		}
+		write(&opc.Part{Name: p.name, ContentType: p.contentType}, content)
+	}
+	if !s.noOrigin {
+		write(&opc.Part{Name: testOriginPath, ContentType: "application/vnd.openxmlformats-package.digital-signature-origin",
+			Relationships: []*opc.Relationship{{ID: "rel0", Type: relTypeSignature, TargetURI: testSignaturePath}}}, "")
+		sigPart := &opc.Part{Name: testSignaturePath, ContentType: "application/vnd.openxmlformats-package.digital-signature-xmlsignature+xml"}
+		if s.certParts {
+			sigPart.Relationships = []*opc.Relationship{{ID: "rel0", Type: relTypeSigCert, TargetURI: "/package/services/digital-signature/certificate/cert.cer"}}
+		}
+		write(sigPart, s.signature(t))
+		if s.certParts {
+			write(&opc.Part{Name: "/package/services/digital-signature/certificate/cert.cer", ContentType: "application/vnd.openxmlformats-package.digital-signature-certificate"}, string(s.cert))
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("opc.Writer.Close() unexpected error = %v", err)
+	}
+	return buff.Bytes()
+}
+
+func newTestCertificate(t *testing.T, pub, signer crypto.Signer, parent *x509.Certificate, isCA bool) (*x509.Certificate, []byte) {
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	tmpl := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "go3mf test " + serial.String()},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  isCA,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+	}
+	if parent == nil {
+		parent = tmpl
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, pub.Public(), signer)
+	if err != nil {
+		t.Fatalf("x509.CreateCertificate() unexpected error = %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return cert, der
+}
+
+func TestVerifySignatures(t *testing.T) {
+	rootKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	root, _ := newTestCertificate(t, rootKey, rootKey, nil, true)
+	otherRoot, _ := newTestCertificate(t, rootKey, rootKey, nil, true)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 1024)
+	rsaCert, rsaDER := newTestCertificate(t, rsaKey, rootKey, root, false)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, ecDER := newTestCertificate(t, ecKey, rootKey, root, false)
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	others := x509.NewCertPool()
+	others.AddCert(otherRoot)
+
+	model := testPart{"/3D/3dmodel.model", contentType3DModel, `<model unit="millimeter" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02"><resources /><build /></model>`}
+	thumb := testPart{"/Metadata/thumbnail.png", "image/png", "thumbnail"}
+	extra := testPart{"/Metadata/extra.xml", "application/xml", "<extra/>"}
+	newSigner := func() *packageSigner {
+		return &packageSigner{
+			key: rsaKey, method: "http://www.w3.org/2001/04/xmldsig-more#rsa-sha256", cert: rsaDER,
+			parts: []testPart{model, thumb}, signed: []string{model.name, thumb.name}, signRels: true,
+		}
+	}
+	allParts := []SignedPart{{model.name, true}, {thumb.name, true}, {"/_rels/.rels", true}}
+	// wrapped returns an unsigned object that lists the extra part, added after signing.
+	wrapped := func(attrs string) string {
+		return "<Object" + attrs + "><Manifest><Reference URI=\"" + extra.name + "?ContentType=" + extra.contentType + "\">" +
+			`<DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/><DigestValue>` + sha256Base64(extra.content) +
+			"</DigestValue></Reference></Manifest></Object>"
+	}
+	tests := []struct {
+		name         string
+		signer       func(*packageSigner)
+		opts         VerifyOptions
+		wantVerified bool
+		wantParts    []SignedPart
+		wantUnsigned []string
+		wantTrustErr bool
+		wantErr      bool
+	}{
+		{"valid", func(s *packageSigner) {}, VerifyOptions{Roots: roots}, true, allParts, nil, false, false},
+		{"certificatePart", func(s *packageSigner) { s.certParts = true }, VerifyOptions{Roots: roots}, true, allParts, nil, false, false},
+		{"ecdsa", func(s *packageSigner) {
+			s.key, s.cert, s.method = ecKey, ecDER, "http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256"
+		}, VerifyOptions{Roots: roots}, true, allParts, nil, false, false},
+		{"unsignedPart", func(s *packageSigner) { s.parts = append(s.parts, extra) }, VerifyOptions{Roots: roots}, false, allParts, []string{extra.name}, false, false},
+		{"unsignedRels", func(s *packageSigner) { s.signRels = false }, VerifyOptions{Roots: roots}, false, allParts[:2], []string{"/_rels/.rels"}, false, false},
+		{"tamperedPart", func(s *packageSigner) { s.tampered = map[string]string{model.name: "<model/>"} }, VerifyOptions{Roots: roots}, false,
+			[]SignedPart{{model.name, false}, {thumb.name, true}, {"/_rels/.rels", true}}, []string{model.name, thumb.name, "/_rels/.rels"}, false, false},
+		{"tamperedRels", func(s *packageSigner) { s.extraRel = true }, VerifyOptions{Roots: roots}, false,
+			[]SignedPart{{model.name, true}, {thumb.name, true}, {"/_rels/.rels", false}}, []string{model.name, thumb.name, "/_rels/.rels"}, false, false},
+		{"untrusted", func(s *packageSigner) {}, VerifyOptions{Roots: others}, false, allParts, []string{model.name, thumb.name, "/_rels/.rels"}, true, false},
+		{"noRoots", func(s *packageSigner) {}, VerifyOptions{}, false, allParts, []string{model.name, thumb.name, "/_rels/.rels"}, true, false},
+		{"expired", func(s *packageSigner) {}, VerifyOptions{Roots: roots, CurrentTime: time.Now().Add(24 * time.Hour)}, false, allParts, []string{model.name, thumb.name, "/_rels/.rels"}, true, false},
+		{"badSignatureValue", func(s *packageSigner) { s.badValue = true }, VerifyOptions{Roots: roots}, false, nil, []string{model.name, thumb.name, "/_rels/.rels"}, false, true},
+		{"duplicatedObject", func(s *packageSigner) {
+			s.parts, s.wrapping = append(s.parts, extra), wrapped(` Id="idPackageObject"`)
+		}, VerifyOptions{Roots: roots}, false, nil, []string{model.name, extra.name, thumb.name, "/_rels/.rels"}, false, true},
+		{"foreignObject", func(s *packageSigner) {
+			s.parts, s.wrapping = append(s.parts, extra), "<x:Wrapper xmlns:x=\"urn:other\">"+wrapped(` xmlns="urn:other" Id="idOther"`)+"</x:Wrapper>"
+		}, VerifyOptions{Roots: roots}, false, nil, []string{model.name, extra.name, thumb.name, "/_rels/.rels"}, false, true},
+		{"secondSignedInfo", func(s *packageSigner) {
+			s.parts, s.wrapping = append(s.parts, extra), `<SignedInfo><Reference URI="#idEvil">`+
+				`<DigestMethod Algorithm="http://www.w3.org/2001/04/xmlenc#sha256"/><DigestValue>`+sha256Base64(wrapped(` Id="idEvil"`))+
+				"</DigestValue></Reference></SignedInfo>"+wrapped(` Id="idEvil"`)
+		}, VerifyOptions{Roots: roots}, false, nil, []string{model.name, extra.name, thumb.name, "/_rels/.rels"}, false, true},
+		{"unsupportedMethod", func(s *packageSigner) { s.method = "other" }, VerifyOptions{Roots: roots}, false, nil, []string{model.name, thumb.name, "/_rels/.rels"}, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newSigner()
+			tt.signer(s)
+			data := s.build(t)
+			got, err := VerifySignatures(bytes.NewReader(data), int64(len(data)), tt.opts)
+			if err != nil {
+				t.Fatalf("VerifySignatures() unexpected error = %v", err)
+			}
+			if got.Verified() != tt.wantVerified {
+				t.Errorf("SignatureReport.Verified() = %v, want %v", got.Verified(), tt.wantVerified)
+			}
+			if len(got.Signatures) != 1 {
+				t.Fatalf("VerifySignatures() signatures = %v, want 1", len(got.Signatures))
+			}
+			sig := got.Signatures[0]
+			if sig.Path != testSignaturePath {
+				t.Errorf("VerifySignatures() path = %v, want %v", sig.Path, testSignaturePath)
+			}
+			if (sig.TrustErr != nil) != tt.wantTrustErr {
+				t.Errorf("VerifySignatures() trust error = %v, wantTrustErr %v", sig.TrustErr, tt.wantTrustErr)
+			}
+			if (sig.Err != nil) != tt.wantErr {
+				t.Errorf("VerifySignatures() error = %v, wantErr %v", sig.Err, tt.wantErr)
+			}
+			if tt.name == "valid" && !sig.Certificate.Equal(rsaCert) {
+				t.Errorf("VerifySignatures() certificate = %v, want %v", sig.Certificate.Subject, rsaCert.Subject)
+			}
+			if diff := deep.Equal(sig.Parts, tt.wantParts); diff != nil {
+				t.Errorf("VerifySignatures() parts = %v", diff)
+			}
+			if diff := deep.Equal(got.UnsignedParts, tt.wantUnsigned); diff != nil {
+				t.Errorf("VerifySignatures() unsigned parts = %v", diff)
+			}
+		})
+	}
+}
+
+func TestVerifySignatures_Unsigned(t *testing.T) {
+	data := (&packageSigner{noOrigin: true, parts: []testPart{{"/3D/3dmodel.model", contentType3DModel, "<model/>"}}}).build(t)
+	got, err := VerifySignatures(bytes.NewReader(data), int64(len(data)), VerifyOptions{})
+	if err != nil {
+		t.Fatalf("VerifySignatures() unexpected error = %v", err)
+	}
+	want := &SignatureReport{UnsignedParts: []string{"/3D/3dmodel.model", "/_rels/.rels"}}
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("VerifySignatures() = %v", diff)
+	}
+	if got.Verified() {
+		t.Error("SignatureReport.Verified() = true, want false")
+	}
+	if _, err := VerifySignatures(strings.NewReader("a"), 1, VerifyOptions{}); err == nil {
+		t.Error("VerifySignatures() expected error")
+	}
+}
@@ -2,6 +2,7 @@ package io3mf
 
 import (
 	"encoding/xml"
+	"strconv"
 
 	go3mf "github.com/qmuntal/go3mf"
 	"github.com/qmuntal/go3mf/geo"
@@ -245,3 +246,40 @@ func (d *polygonSegmentDecoder) Attributes(attrs []xml.Attr) bool {
 	}
 	return ok
 }
+
+func (w *modelWriter) writeSliceStack(r *go3mf.SliceStackResource) {
+	w.startNS(nsSliceSpec, attrSliceStack, w.attr(attrID, formatUint32(r.ID)), w.attr(attrZBottom, formatFloat32(r.Stack.BottomZ)))
+	for _, s := range r.Stack.Slices {
+		w.writeSlice(s)
+	}
+	for _, ref := range r.Stack.Refs {
+		attrs := []xml.Attr{w.attr(attrSliceRefID, formatUint32(ref.SliceStackID))}
+		if ref.Path != "" {
+			attrs = append(attrs, w.attr(attrSlicePath, ref.Path))
+		}
+		w.elementNS(nsSliceSpec, attrSliceRef, attrs...)
+	}
+	w.endNS(nsSliceSpec, attrSliceStack)
+}
+
+func (w *modelWriter) writeSlice(s *geo.Slice) {
+	w.startNS(nsSliceSpec, attrSlice, w.attr(attrZTop, formatFloat32(s.TopZ)))
+	if len(s.Vertices) > 0 {
+		w.startNS(nsSliceSpec, attrVertices)
+		for _, v := range s.Vertices {
+			w.elementNS(nsSliceSpec, attrVertex, w.attr(attrX, formatFloat32(v.X())), w.attr(attrY, formatFloat32(v.Y())))
+		}
+		w.endNS(nsSliceSpec, attrVertices)
+	}
+	for _, p := range s.Polygons {
+		if len(p) == 0 {
+			continue
+		}
+		w.startNS(nsSliceSpec, attrPolygon, w.attr(attrStartV, strconv.Itoa(p[0])))
+		for _, index := range p[1:] {
+			w.elementNS(nsSliceSpec, attrSegment, w.attr(attrV2, strconv.Itoa(index)))
+		}
+		w.endNS(nsSliceSpec, attrPolygon)
+	}
+	w.endNS(nsSliceSpec, attrSlice)
+}
@@ -17,17 +17,37 @@ type asciiDecoder struct {
 	units float32
 }
 
-func (d *asciiDecoder) decode(ctx context.Context, m *geo.Mesh) (err error) {
-	m.StartCreation(geo.CreationOptions{CalculateConnectivity: true})
-	defer m.EndCreation()
-	position := 0
+// decode creates a mesh for each solid of the stream, which are named after the solid.
+func (d *asciiDecoder) decode(ctx context.Context) (solids []solid, err error) {
+	defer func() {
+		for _, s := range solids {
+			s.mesh.EndCreation()
+		}
+	}()
+	var (
+		m         *geo.Mesh
+		nodes     [3]uint32
+		position  int
+		faceCount int
+	)
 	nextFaceCheck := checkEveryFaces
-	var nodes [3]uint32
 	scanner := bufio.NewScanner(d.r)
 	for scanner.Scan() {
-		line := scanner.Text()
-		fields := strings.Fields(line)
-		if len(fields) == 4 && fields[0] == "vertex" {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "solid":
+			solids = append(solids, newSolid(strings.Join(fields[1:], " ")))
+			m, position = solids[len(solids)-1].mesh, 0
+		case fields[0] == "endsolid":
+			m, position = nil, 0
+		case len(fields) == 4 && fields[0] == "vertex":
+			if m == nil {
+				solids = append(solids, newSolid(""))
+				m = solids[len(solids)-1].mesh
+			}
 			var f [3]float64
 			f[0], _ = strconv.ParseFloat(fields[1], 32)
 			f[1], _ = strconv.ParseFloat(fields[2], 32)
@@ -38,31 +58,38 @@ func (d *asciiDecoder) decode(ctx context.Context, m *geo.Mesh) (err error) {
 			if position == 3 {
 				position = 0
 				m.AddFace(nodes[0], nodes[1], nodes[2])
-				if len(m.Faces) > nextFaceCheck {
+				faceCount++
+				if faceCount > nextFaceCheck {
 					select {
 					case <-ctx.Done():
-						err = ctx.Err()
-						break
+						return solids, ctx.Err()
 					default: // Default is must to avoid blocking
 					}
 					nextFaceCheck += checkEveryFaces
 				}
 			}
 		}
-		if err != nil {
-			return err
-		}
 	}
-	return scanner.Err()
+	return solids, scanner.Err()
+}
+
+func newSolid(name string) solid {
+	m := new(geo.Mesh)
+	m.StartCreation(geo.CreationOptions{CalculateConnectivity: true})
+	return solid{name: name, mesh: m}
 }
 
 type asciiEncoder struct {
 	w io.Writer
 }
 
-const pstr = "solid\nfacet normal %f %f %f\nouter loop\nvertex %f %f %f\nvertex %f %f %f\nvertex %f %f %f\nendloop\nendfacet\nendsolid\n"
+const pstr = "facet normal %f %f %f\nouter loop\nvertex %f %f %f\nvertex %f %f %f\nvertex %f %f %f\nendloop\nendfacet\n"
 
+// encode writes all the faces of the mesh in a single solid.
 func (e *asciiEncoder) encode(m *geo.Mesh) error {
+	if _, err := io.WriteString(e.w, "solid\n"); err != nil {
+		return err
+	}
 	for i := range m.Faces {
 		n1, n2, n3 := m.FaceNodes(uint32(i))
 		n := faceNormal(*n1, *n2, *n3)
@@ -72,6 +99,6 @@ func (e *asciiEncoder) encode(m *geo.Mesh) error {
 			return err
 		}
 	}
-
-	return nil
+	_, err := io.WriteString(e.w, "endsolid\n")
+	return err
 }
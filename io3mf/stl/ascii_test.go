@@ -15,29 +15,51 @@ func Test_asciiDecoder_decode(t *testing.T) {
 	cancel()
 	checkEveryFaces = 1
 	triangle := createASCIITriangle()
+	twoSolids := "solid first part\n" +
+		"facet normal 0 0 0\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nvertex 0 1 0\nendloop\nendfacet\n" +
+		"endsolid first part\n" +
+		"solid second\n" +
+		"facet normal 0 0 0\nouter loop\nvertex 0 0 1\nvertex 1 0 1\nvertex 0 1 1\nendloop\nendfacet\n" +
+		"endsolid second\n" +
+		"facet normal 0 0 0\nouter loop\nvertex 0 0 2\nvertex 1 0 2\nvertex 0 1 2\nendloop\nendfacet\n"
+	triangleAt := func(z float32) *geo.Mesh {
+		m := new(geo.Mesh)
+		m.Nodes = []geo.Point3D{{0, 0, z}, {1, 0, z}, {0, 1, z}}
+		m.AddFace(0, 1, 2)
+		return m
+	}
 	tests := []struct {
-		name    string
-		d       *asciiDecoder
-		ctx     context.Context
-		want    *geo.Mesh
-		wantErr bool
+		name      string
+		d         *asciiDecoder
+		ctx       context.Context
+		wantNames []string
+		want      []*geo.Mesh
+		wantErr   bool
 	}{
-		{"eof", &asciiDecoder{r: bytes.NewReader(make([]byte, 0))}, context.Background(), new(geo.Mesh), false},
-		{"base", &asciiDecoder{r: bytes.NewBufferString(triangle)}, context.Background(), createMeshTriangle(), false},
-		{"cancel", &asciiDecoder{r: bytes.NewBufferString(triangle)}, ctx, createMeshTriangle(), true},
+		{"eof", &asciiDecoder{r: bytes.NewReader(make([]byte, 0))}, context.Background(), nil, nil, false},
+		{"base", &asciiDecoder{r: bytes.NewBufferString(triangle)}, context.Background(), []string{""}, []*geo.Mesh{createMeshTriangle()}, false},
+		{"solids", &asciiDecoder{r: bytes.NewBufferString(twoSolids)}, context.Background(), []string{"first part", "second", ""}, []*geo.Mesh{triangleAt(0), triangleAt(1), triangleAt(2)}, false},
+		{"cancel", &asciiDecoder{r: bytes.NewBufferString(triangle)}, ctx, nil, nil, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := new(geo.Mesh)
-			err := tt.d.decode(tt.ctx, got)
+			got, err := tt.d.decode(tt.ctx)
 			if (err != nil) != tt.wantErr {
 				t.Errorf("asciiDecoder.decode() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !tt.wantErr {
-				if diff := deep.Equal(got, tt.want); diff != nil {
+				var names []string
+				var meshes []*geo.Mesh
+				for _, s := range got {
+					names = append(names, s.name)
+					meshes = append(meshes, s.mesh)
+				}
+				if diff := deep.Equal(names, tt.wantNames); diff != nil {
+					t.Errorf("asciiDecoder.decode() names = %v", diff)
+				}
+				if diff := deep.Equal(meshes, tt.want); diff != nil {
 					t.Errorf("asciiDecoder.decode() = %v", diff)
-					return
 				}
 			}
 		})
@@ -57,6 +79,8 @@ func Test_asciiEncoder_encode(t *testing.T) {
 	}{
 		{"base", &asciiEncoder{w: new(bytes.Buffer)}, args{triangle}, false},
 		{"error", &asciiEncoder{w: new(errorWriter)}, args{triangle}, true},
+		{"errorFace", &asciiEncoder{w: &errorWriter{max: 1}}, args{triangle}, true},
+		{"errorEnd", &asciiEncoder{w: &errorWriter{max: 7}}, args{triangle}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -67,9 +91,11 @@ func Test_asciiEncoder_encode(t *testing.T) {
 			if !tt.wantErr {
 				// We do decoder and then encoder again, and the result must be the same
 				decoder := &asciiDecoder{r: tt.e.w.(*bytes.Buffer)}
-				got := new(geo.Mesh)
-				decoder.decode(context.Background(), got)
-				if diff := deep.Equal(got, tt.args.m); diff != nil {
+				got, _ := decoder.decode(context.Background())
+				if len(got) != 1 {
+					t.Fatalf("asciiDecoder.encode() solids = %d, want 1", len(got))
+				}
+				if diff := deep.Equal(got[0].mesh, tt.args.m); diff != nil {
 					t.Errorf("asciiDecoder.encode() = %v", diff)
 					return
 				}
@@ -1,27 +1,32 @@
 package stl
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
+	"fmt"
 	"io"
+	"strings"
 
+	"github.com/qmuntal/go3mf"
 	"github.com/qmuntal/go3mf/geo"
 )
 
 type binaryHeader struct {
-	_         [80]byte
+	Data      [80]byte
 	FaceCount uint32
 }
 
 type binaryFace struct {
-	Normal   [3]float32
-	Vertices [3][3]float32
-	_        uint16
+	Normal    [3]float32
+	Vertices  [3][3]float32
+	Attribute uint16
 }
 
 // binaryDecoder can create a Mesh from a Read stream that is feeded with a binary STL.
 type binaryDecoder struct {
-	r io.Reader
+	r        io.Reader
+	metadata []go3mf.Metadata // COLOR and MATERIAL entries of the header.
 }
 
 // decode loads a binary stl from a io.Reader.
@@ -33,6 +38,7 @@ func (d *binaryDecoder) decode(ctx context.Context, m *geo.Mesh) error {
 	if err != nil {
 		return err
 	}
+	d.metadata = headerMetadata(header.Data)
 
 	nextFaceCheck := checkEveryFaces
 	var facet binaryFace
@@ -66,8 +72,37 @@ func (d *binaryDecoder) decodeFace(facet *binaryFace, m *geo.Mesh) {
 	m.AddFace(nodes[0], nodes[1], nodes[2])
 }
 
+// headerMetadata returns the COLOR and MATERIAL entries of a header,
+// following the Materialise Magics convention, as hex colors with the format #rrggbbaa.
+// COLOR is followed by the four bytes of the default color and
+// MATERIAL by the diffuse, the specular and the ambient colors.
+func headerMetadata(header [80]byte) []go3mf.Metadata {
+	var metadata []go3mf.Metadata
+	for _, entry := range []struct {
+		name   string
+		colors int
+	}{{"COLOR", 1}, {"MATERIAL", 3}} {
+		i := bytes.Index(header[:], []byte(entry.name+"="))
+		if i < 0 {
+			continue
+		}
+		data := header[i+len(entry.name)+1:]
+		if len(data) < 4*entry.colors {
+			continue
+		}
+		values := make([]string, entry.colors)
+		for j := range values {
+			c := data[4*j : 4*j+4]
+			values[j] = fmt.Sprintf("#%02x%02x%02x%02x", c[0], c[1], c[2], c[3])
+		}
+		metadata = append(metadata, go3mf.Metadata{Name: entry.name, Value: strings.Join(values, " ")})
+	}
+	return metadata
+}
+
 type binaryEncoder struct {
-	w io.Writer
+	w          io.Writer
+	attributes []uint16 // attribute of each face, if any.
 }
 
 func (e *binaryEncoder) encode(m *geo.Mesh) error {
@@ -85,6 +120,9 @@ func (e *binaryEncoder) encode(m *geo.Mesh) error {
 			Normal:   [3]float32{normal[0], normal[1], normal[2]},
 			Vertices: [3][3]float32{{n1.X(), n1.Y(), n1.Z()}, {n2.X(), n2.Y(), n2.Z()}, {n3.X(), n3.Y(), n3.Z()}},
 		}
+		if int(i) < len(e.attributes) {
+			facet.Attribute = e.attributes[i]
+		}
 		err := binary.Write(e.w, binary.LittleEndian, facet)
 		if err != nil {
 			return err
@@ -7,6 +7,7 @@ import (
 	"testing"
 
 	"github.com/go-test/deep"
+	"github.com/qmuntal/go3mf"
 	"github.com/qmuntal/go3mf/geo"
 )
 
@@ -241,3 +242,31 @@ func createBinaryTriangle() []byte {
 	stl[377] = 0x41
 	return stl
 }
+
+func Test_headerMetadata(t *testing.T) {
+	var header, colorHeader, materialHeader, truncated [80]byte
+	copy(header[:], "binary stl")
+	copy(colorHeader[:], "exported COLOR=\xff\x80\x00\x7f")
+	copy(materialHeader[:], "COLOR=\x01\x02\x03\x04,MATERIAL=\x10\x20\x30\x40\xff\xff\xff\xff\x00\x00\x00\xff")
+	copy(truncated[74:], "COLOR=")
+	tests := []struct {
+		name   string
+		header [80]byte
+		want   []go3mf.Metadata
+	}{
+		{"none", header, nil},
+		{"color", colorHeader, []go3mf.Metadata{{Name: "COLOR", Value: "#ff80007f"}}},
+		{"material", materialHeader, []go3mf.Metadata{
+			{Name: "COLOR", Value: "#01020304"},
+			{Name: "MATERIAL", Value: "#10203040 #ffffffff #000000ff"},
+		}},
+		{"truncated", truncated, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := deep.Equal(headerMetadata(tt.header), tt.want); diff != nil {
+				t.Errorf("headerMetadata() = %v", diff)
+			}
+		})
+	}
+}
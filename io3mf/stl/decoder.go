@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"context"
 	"io"
+	"math"
 	"strings"
 	"unicode/utf8"
 
@@ -17,8 +18,22 @@ const sizeOfHeader = 300 // minimum size of a closed mesh in binary is 384 bytes
 
 // Decoder can decode an stl to a geo.
 // It supports automatic detection of binary or ascii stl encoding.
+// Each solid of an ascii stl is decoded as a MeshResource named after the solid,
+// and the COLOR and MATERIAL entries of a binary header are decoded as metadata of its MeshResource.
 type Decoder struct {
 	r io.Reader
+	// WeldTolerance, if greater than zero, merges each vertex with the first previous one
+	// that is not farther than this distance, so the facets that are not exactly connected share their vertices.
+	// The coincident vertices are always merged.
+	WeldTolerance float32
+	// RemoveDegenerateFaces drops the facets that have zero area.
+	// The facets whose vertices have been merged are always dropped, as 3MF does not allow them.
+	RemoveDegenerateFaces bool
+	// WeldedVertices is the number of vertices merged by the last decoding because of WeldTolerance.
+	WeldedVertices int
+	// RemovedFaces is the number of facets dropped by the last decoding,
+	// either because their vertices have been merged or because of RemoveDegenerateFaces.
+	RemovedFaces int
 }
 
 // NewDecoder creates a new decoder.
@@ -35,29 +50,51 @@ func (d *Decoder) Decode(m *go3mf.Model) error {
 
 // DecodeContext creates a mesh from a read stream.
 func (d *Decoder) DecodeContext(ctx context.Context, m *go3mf.Model) error {
+	d.WeldedVertices, d.RemovedFaces = 0, 0
 	b := bufio.NewReader(d.r)
 	isASCII, err := d.isASCII(b)
 	if err != nil {
 		return err
 	}
-	newMesh := new(geo.Mesh)
+	var solids []solid
 	if isASCII {
 		decoder := asciiDecoder{r: b}
-		err = decoder.decode(ctx, newMesh)
+		solids, err = decoder.decode(ctx)
 	} else {
 		decoder := binaryDecoder{r: b}
+		newMesh := new(geo.Mesh)
 		err = decoder.decode(ctx, newMesh)
+		solids = []solid{{mesh: newMesh, metadata: decoder.metadata}}
+	}
+	if err != nil {
+		return err
 	}
-	if err == nil {
+	for _, s := range solids {
+		if d.WeldTolerance > 0 {
+			d.WeldedVertices += weld(s.mesh, d.WeldTolerance)
+		}
+		d.RemovedFaces += removeCollapsedFaces(s.mesh)
+		if d.RemoveDegenerateFaces {
+			d.RemovedFaces += removeDegenerateFaces(s.mesh)
+		}
 		m.Resources = append(m.Resources, &go3mf.MeshResource{
 			ObjectResource: go3mf.ObjectResource{
 				ModelPath: m.Path,
 				ID:        m.UnusedID(),
+				Name:      s.name,
+				Metadata:  s.metadata,
 			},
-			Mesh: newMesh,
+			Mesh: s.mesh,
 		})
 	}
-	return err
+	return nil
+}
+
+// solid is a decoded mesh with its name and metadata.
+type solid struct {
+	name     string
+	metadata []go3mf.Metadata
+	mesh     *geo.Mesh
 }
 
 func (d *Decoder) isASCII(r *bufio.Reader) (bool, error) {
@@ -83,3 +120,83 @@ func isASCII(s string) bool {
 	}
 	return true
 }
+
+// weld merges each node with the first kept node that is not farther than tolerance
+// and returns the number of merged nodes.
+// The kept nodes are indexed in a grid of size tolerance, so the candidates
+// are the ones in the cell of the node and in its neighbouring cells.
+func weld(m *geo.Mesh, tolerance float32) int {
+	cells := make(map[[3]int64][]uint32, len(m.Nodes))
+	remap := make([]uint32, len(m.Nodes))
+	nodes := m.Nodes[:0]
+	for i, n := range m.Nodes {
+		var c [3]int64
+		for j := range c {
+			c[j] = int64(math.Floor(float64(n[j] / tolerance)))
+		}
+		index, ok := findNode(nodes, cells, c, n, tolerance)
+		if !ok {
+			index = uint32(len(nodes))
+			cells[c] = append(cells[c], index)
+			nodes = append(nodes, n)
+		}
+		remap[i] = index
+	}
+	welded := len(m.Nodes) - len(nodes)
+	m.Nodes = nodes
+	for i := range m.Faces {
+		f := &m.Faces[i]
+		f.NodeIndices = [3]uint32{remap[f.NodeIndices[0]], remap[f.NodeIndices[1]], remap[f.NodeIndices[2]]}
+	}
+	return welded
+}
+
+// findNode returns the first node of the cells around c that is not farther than tolerance from n.
+func findNode(nodes []geo.Point3D, cells map[[3]int64][]uint32, c [3]int64, n geo.Point3D, tolerance float32) (uint32, bool) {
+	var (
+		found bool
+		best  uint32
+	)
+	for dx := int64(-1); dx <= 1; dx++ {
+		for dy := int64(-1); dy <= 1; dy++ {
+			for dz := int64(-1); dz <= 1; dz++ {
+				for _, index := range cells[[3]int64{c[0] + dx, c[1] + dy, c[2] + dz}] {
+					if (!found || index < best) && nodes[index].Sub(n).Len() <= tolerance {
+						found, best = true, index
+					}
+				}
+			}
+		}
+	}
+	return best, found
+}
+
+// removeCollapsedFaces removes the faces that use the same node more than once
+// and returns the number of removed faces.
+func removeCollapsedFaces(m *geo.Mesh) int {
+	faces := m.Faces[:0]
+	for _, f := range m.Faces {
+		i := f.NodeIndices
+		if i[0] != i[1] && i[0] != i[2] && i[1] != i[2] {
+			faces = append(faces, f)
+		}
+	}
+	removed := len(m.Faces) - len(faces)
+	m.Faces = faces
+	return removed
+}
+
+// removeDegenerateFaces removes the faces with zero area and returns the number of removed faces.
+func removeDegenerateFaces(m *geo.Mesh) int {
+	faces := m.Faces[:0]
+	for _, f := range m.Faces {
+		n1, n2, n3 := m.Nodes[f.NodeIndices[0]], m.Nodes[f.NodeIndices[1]], m.Nodes[f.NodeIndices[2]]
+		if n2.Sub(n1).Cross(n3.Sub(n1)).Len() == 0 {
+			continue
+		}
+		faces = append(faces, f)
+	}
+	removed := len(m.Faces) - len(faces)
+	m.Faces = faces
+	return removed
+}
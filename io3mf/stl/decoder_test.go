@@ -2,12 +2,15 @@ package stl
 
 import (
 	"bytes"
+	"fmt"
 	"io"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/go-test/deep"
 	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
 )
 
 func TestNewDecoder(t *testing.T) {
@@ -71,3 +74,137 @@ func TestDecoder_Decode(t *testing.T) {
 		})
 	}
 }
+
+func TestDecoder_Decode_Cleanup(t *testing.T) {
+	facet := "facet normal 0 0 0\n outer loop\n  vertex %s\n  vertex %s\n  vertex %s\n endloop\nendfacet\n"
+	stl := "solid cleanup\n" +
+		fmt.Sprintf(facet, "0 0 0", "1 0 0", "0 1 0") +
+		fmt.Sprintf(facet, "1.001 0 0", "1 1 0", "0 1 0") +
+		fmt.Sprintf(facet, "0 0 0", "0.001 0 0", "0 1 0") +
+		fmt.Sprintf(facet, "0 0 0", "1 0 0", "2 0 0") +
+		"endsolid cleanup\n"
+	tests := []struct {
+		name        string
+		tolerance   float32
+		remove      bool
+		wantNodes   []geo.Point3D
+		wantFaces   [][3]uint32
+		wantWelded  int
+		wantRemoved int
+	}{
+		{"none", 0, false, []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1.001, 0, 0}, {1, 1, 0}, {0.001, 0, 0}, {2, 0, 0}},
+			[][3]uint32{{0, 1, 2}, {3, 4, 2}, {0, 5, 2}, {0, 1, 6}}, 0, 0},
+		{"remove", 0, true, []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1.001, 0, 0}, {1, 1, 0}, {0.001, 0, 0}, {2, 0, 0}},
+			[][3]uint32{{0, 1, 2}, {3, 4, 2}, {0, 5, 2}}, 0, 1},
+		{"weld", 0.01, false, []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {2, 0, 0}},
+			[][3]uint32{{0, 1, 2}, {1, 3, 2}, {0, 1, 4}}, 2, 1},
+		{"weldRemove", 0.01, true, []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {1, 1, 0}, {2, 0, 0}},
+			[][3]uint32{{0, 1, 2}, {1, 3, 2}}, 2, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := NewDecoder(bytes.NewBufferString(stl))
+			d.WeldTolerance = tt.tolerance
+			d.RemoveDegenerateFaces = tt.remove
+			got := new(go3mf.Model)
+			if err := d.Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() unexpected error = %v", err)
+			}
+			mesh := got.Resources[0].(*go3mf.MeshResource).Mesh
+			var faces [][3]uint32
+			for _, f := range mesh.Faces {
+				faces = append(faces, f.NodeIndices)
+			}
+			if diff := deep.Equal(mesh.Nodes, tt.wantNodes); diff != nil {
+				t.Errorf("Decoder.Decode() nodes = %v", diff)
+			}
+			if diff := deep.Equal(faces, tt.wantFaces); diff != nil {
+				t.Errorf("Decoder.Decode() faces = %v", diff)
+			}
+			if d.WeldedVertices != tt.wantWelded || d.RemovedFaces != tt.wantRemoved {
+				t.Errorf("Decoder.Decode() welded = %d, removed = %d, want %d and %d", d.WeldedVertices, d.RemovedFaces, tt.wantWelded, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func Test_weld(t *testing.T) {
+	tests := []struct {
+		name       string
+		nodes      []geo.Point3D
+		want       []geo.Point3D
+		wantRemap  [3]uint32
+		wantWelded int
+	}{
+		{"sameCell", []geo.Point3D{{0.001, 0, 0}, {0.009, 0, 0}, {1, 0, 0}}, []geo.Point3D{{0.001, 0, 0}, {1, 0, 0}}, [3]uint32{0, 0, 1}, 1},
+		{"cellBoundary", []geo.Point3D{{0.0099, 0, 0}, {0.0101, 0, 0}, {1, 0, 0}}, []geo.Point3D{{0.0099, 0, 0}, {1, 0, 0}}, [3]uint32{0, 0, 1}, 1},
+		{"halfCell", []geo.Point3D{{0.0049, 0, 0}, {0.0051, 0, 0}, {1, 0, 0}}, []geo.Point3D{{0.0049, 0, 0}, {1, 0, 0}}, [3]uint32{0, 0, 1}, 1},
+		{"diagonal", []geo.Point3D{{0.0099, 0.0099, 0.0099}, {0.0101, 0.0101, 0.0101}, {1, 0, 0}}, []geo.Point3D{{0.0099, 0.0099, 0.0099}, {1, 0, 0}}, [3]uint32{0, 0, 1}, 1},
+		{"sameCellTooFar", []geo.Point3D{{0, 0, 0}, {0.009, 0.009, 0}, {1, 0, 0}}, []geo.Point3D{{0, 0, 0}, {0.009, 0.009, 0}, {1, 0, 0}}, [3]uint32{0, 1, 2}, 0},
+		{"first", []geo.Point3D{{0, 0, 0}, {0.015, 0, 0}, {0.008, 0, 0}}, []geo.Point3D{{0, 0, 0}, {0.015, 0, 0}}, [3]uint32{0, 1, 0}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := new(geo.Mesh)
+			m.Nodes = tt.nodes
+			m.AddFace(0, 1, 2)
+			if got := weld(m, 0.01); got != tt.wantWelded {
+				t.Errorf("weld() = %v, want %v", got, tt.wantWelded)
+			}
+			if diff := deep.Equal(m.Nodes, tt.want); diff != nil {
+				t.Errorf("weld() nodes = %v", diff)
+			}
+			if m.Faces[0].NodeIndices != tt.wantRemap {
+				t.Errorf("weld() face = %v, want %v", m.Faces[0].NodeIndices, tt.wantRemap)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_Collapsed(t *testing.T) {
+	facet := "facet normal 0 0 0\n outer loop\n  vertex %s\n  vertex %s\n  vertex %s\n endloop\nendfacet\n"
+	stl := "solid collapsed\n" + fmt.Sprintf(facet, "0 0 0", "1 0 0", "0 1 0") + fmt.Sprintf(facet, "0 0 0", "0 0 0", "0 1 0") + "endsolid collapsed\n" + strings.Repeat(" ", sizeOfHeader)
+	d := NewDecoder(bytes.NewBufferString(stl))
+	got := new(go3mf.Model)
+	if err := d.Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() unexpected error = %v", err)
+	}
+	if faces := got.Resources[0].(*go3mf.MeshResource).Mesh.Faces; len(faces) != 1 || d.RemovedFaces != 1 {
+		t.Errorf("Decoder.Decode() faces = %v, removed = %d, want 1 face and 1 removed", faces, d.RemovedFaces)
+	}
+}
+
+func TestDecoder_Decode_Solids(t *testing.T) {
+	facet := "facet normal 0 0 0\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nvertex 0 1 0\nendloop\nendfacet\n"
+	ascii := "solid base\n" + facet + "endsolid base\nsolid lid\n" + facet + "endsolid lid\n" + strings.Repeat(" ", sizeOfHeader)
+	binaryTriangle := createBinaryTriangle()
+	copy(binaryTriangle, "COLOR=\xff\x00\x00\xff")
+	tests := []struct {
+		name string
+		r    io.Reader
+		want []go3mf.ObjectResource
+	}{
+		{"ascii", bytes.NewBufferString(ascii), []go3mf.ObjectResource{
+			{ID: 1, ModelPath: "/3D/3dmodel.model", Name: "base"},
+			{ID: 2, ModelPath: "/3D/3dmodel.model", Name: "lid"},
+		}},
+		{"binary", bytes.NewReader(binaryTriangle), []go3mf.ObjectResource{
+			{ID: 1, ModelPath: "/3D/3dmodel.model", Metadata: []go3mf.Metadata{{Name: "COLOR", Value: "#ff0000ff"}}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &go3mf.Model{Path: "/3D/3dmodel.model"}
+			if err := NewDecoder(tt.r).Decode(got); err != nil {
+				t.Fatalf("Decoder.Decode() unexpected error = %v", err)
+			}
+			var objects []go3mf.ObjectResource
+			for _, r := range got.Resources {
+				objects = append(objects, r.(*go3mf.MeshResource).ObjectResource)
+			}
+			if diff := deep.Equal(objects, tt.want); diff != nil {
+				t.Errorf("Decoder.Decode() = %v", diff)
+			}
+		})
+	}
+}
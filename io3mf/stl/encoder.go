@@ -20,6 +20,9 @@ const (
 type Encoder struct {
 	w            io.Writer
 	encodingType EncodingType
+	// Colors writes the color of each facet of the models in its attribute,
+	// following the VisCAM and SolidView convention. It is only supported by binary files.
+	Colors bool
 }
 
 // NewEncoder creates a new binary encoder.
@@ -0,0 +1,64 @@
+package stl
+
+import (
+	"image/color"
+
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+// EncodeModel encodes all the build items of a model as a single mesh,
+// applying the transforms of the items and of their nested components.
+func (e *Encoder) EncodeModel(m *go3mf.Model) error {
+	meshes, err := m.Flatten()
+	if err != nil {
+		return err
+	}
+	mesh := new(geo.Mesh)
+	var attributes []uint16
+	for _, wm := range meshes {
+		offset := uint32(len(mesh.Nodes))
+		mesh.Nodes = append(mesh.Nodes, wm.Mesh.Nodes...)
+		for i := range wm.Mesh.Faces {
+			face := &wm.Mesh.Faces[i]
+			mesh.AddFace(face.NodeIndices[0]+offset, face.NodeIndices[1]+offset, face.NodeIndices[2]+offset)
+			if e.Colors {
+				attributes = append(attributes, faceColor(wm.Properties[face.Resource], face.ResourceIndices))
+			}
+		}
+	}
+	if e.encodingType == ASCII {
+		encoder := asciiEncoder{w: e.w}
+		return encoder.encode(mesh)
+	}
+	encoder := binaryEncoder{w: e.w, attributes: attributes}
+	return encoder.encode(mesh)
+}
+
+// faceColor returns the VisCAM and SolidView attribute of a face property, which is zero if it has no color.
+// The colors of a color group are averaged.
+func faceColor(res go3mf.Resource, indices [3]uint32) uint16 {
+	switch res := res.(type) {
+	case *go3mf.BaseMaterialsResource:
+		if int(indices[0]) < len(res.Materials) {
+			return visCAMColor(res.Materials[indices[0]].Color)
+		}
+	case *go3mf.ColorGroupResource:
+		var sum [3]int
+		for _, index := range indices {
+			if int(index) >= len(res.Colors) {
+				return 0
+			}
+			c := res.Colors[index]
+			sum[0], sum[1], sum[2] = sum[0]+int(c.R), sum[1]+int(c.G), sum[2]+int(c.B)
+		}
+		return visCAMColor(color.RGBA{R: uint8(sum[0] / 3), G: uint8(sum[1] / 3), B: uint8(sum[2] / 3)})
+	}
+	return 0
+}
+
+// visCAMColor packs a color in 15 bits, 5 bits per channel with the blue in the lowest ones,
+// and sets the highest bit to mark the color as valid.
+func visCAMColor(c color.RGBA) uint16 {
+	return 1<<15 | uint16(c.R>>3)<<10 | uint16(c.G>>3)<<5 | uint16(c.B>>3)
+}
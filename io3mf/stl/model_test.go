@@ -0,0 +1,76 @@
+package stl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"image/color"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+func TestEncoder_EncodeModel(t *testing.T) {
+	base := &go3mf.BaseMaterialsResource{ID: 1, Materials: []go3mf.BaseMaterial{{Name: "red", Color: color.RGBA{255, 0, 0, 255}}}}
+	colors := &go3mf.ColorGroupResource{ID: 2, Colors: []color.RGBA{{0, 0, 255, 255}, {0, 255, 0, 255}}}
+	mesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 3, DefaultPropertyID: 1}, Mesh: new(geo.Mesh)}
+	mesh.Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+	mesh.Mesh.Faces = []geo.Face{
+		{NodeIndices: [3]uint32{0, 2, 1}},
+		{NodeIndices: [3]uint32{0, 1, 3}, Resource: 2},
+		{NodeIndices: [3]uint32{1, 2, 3}, Resource: 2, ResourceIndices: [3]uint32{0, 1, 1}},
+		{NodeIndices: [3]uint32{0, 3, 2}, Resource: 9},
+	}
+	model := &go3mf.Model{Resources: []go3mf.Resource{base, colors, mesh}, BuildItems: []*go3mf.BuildItem{
+		{Object: mesh}, {Object: mesh, Transform: geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 5, 0, 0, 1}},
+	}}
+	attributes := []uint16{0xfc00, 0x801f, 0x82aa, 0}
+	tests := []struct {
+		name           string
+		colors         bool
+		wantAttributes []uint16
+	}{
+		{"noColors", false, []uint16{0, 0, 0, 0, 0, 0, 0, 0}},
+		{"colors", true, append(attributes, attributes...)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := new(bytes.Buffer)
+			e := NewEncoder(w)
+			e.Colors = tt.colors
+			if err := e.EncodeModel(model); err != nil {
+				t.Fatalf("Encoder.EncodeModel() unexpected error = %v", err)
+			}
+			var header binaryHeader
+			binary.Read(w, binary.LittleEndian, &header)
+			if header.FaceCount != 8 {
+				t.Fatalf("Encoder.EncodeModel() faces = %d, want 8", header.FaceCount)
+			}
+			var got []uint16
+			var facet binaryFace
+			for binary.Read(w, binary.LittleEndian, &facet) == nil {
+				got = append(got, facet.Attribute)
+			}
+			if diff := deep.Equal(got, tt.wantAttributes); diff != nil {
+				t.Errorf("Encoder.EncodeModel() attributes = %v", diff)
+			}
+		})
+	}
+	w := new(bytes.Buffer)
+	e := NewEncoderType(w, ASCII)
+	e.Colors = true
+	if err := e.EncodeModel(model); err != nil {
+		t.Fatalf("Encoder.EncodeModel() unexpected error = %v", err)
+	}
+	got, _ := (&asciiDecoder{r: w}).decode(context.Background())
+	if len(got) != 1 || len(got[0].mesh.Faces) != 8 || len(got[0].mesh.Nodes) != 8 {
+		t.Errorf("Encoder.EncodeModel() ascii = %v, want a solid with 8 faces and 8 nodes", got)
+	}
+	cycle := &go3mf.ComponentsResource{ObjectResource: go3mf.ObjectResource{ID: 1}}
+	cycle.Components = []*go3mf.Component{{Object: cycle}}
+	if err := NewEncoder(new(bytes.Buffer)).EncodeModel(&go3mf.Model{BuildItems: []*go3mf.BuildItem{{Object: cycle}}}); err == nil {
+		t.Error("Encoder.EncodeModel() expected cycle error")
+	}
+}
@@ -0,0 +1,174 @@
+package io3mf
+
+import (
+	"encoding/xml"
+	"sort"
+
+	"github.com/qmuntal/go3mf/geo"
+)
+
+type triangleSetsDecoder struct {
+	emptyDecoder
+	mesh *meshDecoder
+}
+
+func (d *triangleSetsDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsTriangleSetsSpec && name.Local == attrTriangleSet {
+		child = &triangleSetDecoder{mesh: d.mesh}
+	}
+	return
+}
+
+type triangleSetDecoder struct {
+	emptyDecoder
+	mesh               *meshDecoder
+	triangleSet        geo.TriangleSet
+	ranges             [][2]uint32
+	triangleRefDecoder triangleRefDecoder
+	refRangeDecoder    triangleRefRangeDecoder
+}
+
+func (d *triangleSetDecoder) Open() {
+	d.triangleRefDecoder.set = d
+	d.refRangeDecoder.set = d
+}
+
+// Close adds the set with the refs sorted and without duplicates,
+// which are only expanded once all the ranges are merged so overlapping ranges are not expanded twice.
+func (d *triangleSetDecoder) Close() bool {
+	sort.Slice(d.ranges, func(i, j int) bool { return d.ranges[i][0] < d.ranges[j][0] })
+	var next uint32
+	for _, r := range d.ranges {
+		if len(d.triangleSet.Refs) > 0 && r[1] < next {
+			continue
+		}
+		if len(d.triangleSet.Refs) > 0 && r[0] < next {
+			r[0] = next
+		}
+		for i := r[0]; i <= r[1]; i++ {
+			d.triangleSet.Refs = append(d.triangleSet.Refs, i)
+		}
+		next = r[1] + 1
+	}
+	d.mesh.resource.Mesh.TriangleSets = append(d.mesh.resource.Mesh.TriangleSets, d.triangleSet)
+	return true
+}
+
+func (d *triangleSetDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrName:
+			d.triangleSet.Name = a.Value
+		case attrIdentifier:
+			d.triangleSet.Identifier = a.Value
+		}
+	}
+	return true
+}
+
+func (d *triangleSetDecoder) Child(name xml.Name) (child nodeDecoder) {
+	if name.Space == nsTriangleSetsSpec {
+		if name.Local == attrRef {
+			child = &d.triangleRefDecoder
+		} else if name.Local == attrRefRange {
+			child = &d.refRangeDecoder
+		}
+	}
+	return
+}
+
+// addRefs adds the triangles between start and end, both included, to the set.
+func (d *triangleSetDecoder) addRefs(start, end uint32) bool {
+	if end >= d.mesh.triangleCount {
+		return d.file.parser.GenericError(true, "triangle set indices are out of range")
+	}
+	d.ranges = append(d.ranges, [2]uint32{start, end})
+	return true
+}
+
+type triangleRefDecoder struct {
+	emptyDecoder
+	set *triangleSetDecoder
+}
+
+func (d *triangleRefDecoder) Attributes(attrs []xml.Attr) bool {
+	for _, a := range attrs {
+		if a.Name.Space == "" && a.Name.Local == attrIndex {
+			index, ok := d.file.parser.ParseUint32Required(attrIndex, a.Value)
+			if ok {
+				return d.set.addRefs(index, index)
+			}
+			break
+		}
+	}
+	return d.file.parser.MissingAttr(attrIndex)
+}
+
+// triangleRefRangeDecoder adds to the set all the triangles between startindex and endindex, both included.
+type triangleRefRangeDecoder struct {
+	emptyDecoder
+	set *triangleSetDecoder
+}
+
+func (d *triangleRefRangeDecoder) Attributes(attrs []xml.Attr) bool {
+	var (
+		start, end           uint32
+		hasStart, hasEnd, ok bool
+	)
+	for _, a := range attrs {
+		if a.Name.Space != "" {
+			continue
+		}
+		switch a.Name.Local {
+		case attrStartIndex:
+			if start, ok = d.file.parser.ParseUint32Required(attrStartIndex, a.Value); !ok {
+				return false
+			}
+			hasStart = true
+		case attrEndIndex:
+			if end, ok = d.file.parser.ParseUint32Required(attrEndIndex, a.Value); !ok {
+				return false
+			}
+			hasEnd = true
+		}
+	}
+	if !hasStart {
+		return d.file.parser.MissingAttr(attrStartIndex)
+	}
+	if !hasEnd {
+		return d.file.parser.MissingAttr(attrEndIndex)
+	}
+	if start > end {
+		return d.file.parser.GenericError(true, "triangle set range start is greater than its end")
+	}
+	return d.set.addRefs(start, end)
+}
+
+func (w *modelWriter) writeTriangleSets(sets []geo.TriangleSet) {
+	w.startNS(nsTriangleSetsSpec, attrTriangleSets)
+	for _, set := range sets {
+		w.writeTriangleSet(set)
+	}
+	w.endNS(nsTriangleSetsSpec, attrTriangleSets)
+}
+
+// writeTriangleSet writes the consecutive refs as ranges.
+func (w *modelWriter) writeTriangleSet(set geo.TriangleSet) {
+	w.startNS(nsTriangleSetsSpec, attrTriangleSet, w.attr(attrName, set.Name), w.attr(attrIdentifier, set.Identifier))
+	for i := 0; i < len(set.Refs); {
+		j := i
+		for j+1 < len(set.Refs) && set.Refs[j+1] == set.Refs[j]+1 {
+			j++
+		}
+		if j == i {
+			w.elementNS(nsTriangleSetsSpec, attrRef, w.attr(attrIndex, formatUint32(set.Refs[i])))
+		} else {
+			w.elementNS(nsTriangleSetsSpec, attrRefRange, w.attr(attrStartIndex, formatUint32(set.Refs[i])), w.attr(attrEndIndex, formatUint32(set.Refs[j])))
+		}
+		i = j + 1
+	}
+	w.endNS(nsTriangleSetsSpec, attrTriangleSet)
+}
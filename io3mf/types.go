@@ -1,6 +1,11 @@
 package io3mf
 
 import (
+	"crypto"
+	_ "crypto/sha1"   // register hash
+	_ "crypto/sha256" // register hash
+	_ "crypto/sha512" // register hash
+	"crypto/x509"
 	"errors"
 
 	go3mf "github.com/qmuntal/go3mf"
@@ -10,19 +15,31 @@ import (
 var checkEveryBytes = int64(4 * 1024 * 1024)
 
 const (
-	nsXML             = "http://www.w3.org/XML/1998/namespace"
-	nsXMLNs           = "http://www.w3.org/2000/xmlns/"
-	nsCoreSpec        = "http://schemas.microsoft.com/3dmanufacturing/core/2015/02"
-	nsMaterialSpec    = "http://schemas.microsoft.com/3dmanufacturing/material/2015/02"
-	nsProductionSpec  = "http://schemas.microsoft.com/3dmanufacturing/production/2015/06"
-	nsBeamLatticeSpec = "http://schemas.microsoft.com/3dmanufacturing/beamlattice/2017/02"
-	nsSliceSpec       = "http://schemas.microsoft.com/3dmanufacturing/slice/2015/07"
+	nsXML              = "http://www.w3.org/XML/1998/namespace"
+	nsXMLNs            = "http://www.w3.org/2000/xmlns/"
+	nsCoreSpec         = "http://schemas.microsoft.com/3dmanufacturing/core/2015/02"
+	nsMaterialSpec     = "http://schemas.microsoft.com/3dmanufacturing/material/2015/02"
+	nsProductionSpec   = "http://schemas.microsoft.com/3dmanufacturing/production/2015/06"
+	nsAlternativesSpec = "http://schemas.microsoft.com/3dmanufacturing/production/alternatives/2021/04"
+	nsBeamLatticeSpec  = "http://schemas.microsoft.com/3dmanufacturing/beamlattice/2017/02"
+	nsBallsSpec        = "http://schemas.microsoft.com/3dmanufacturing/beamlattice/balls/2020/07"
+	nsSliceSpec        = "http://schemas.microsoft.com/3dmanufacturing/slice/2015/07"
+	nsDisplacementSpec = "http://schemas.microsoft.com/3dmanufacturing/displacement/2022/07"
+	nsTriangleSetsSpec = "http://schemas.microsoft.com/3dmanufacturing/trianglesets/2021/07"
+	nsBooleanSpec      = "http://schemas.3mf.io/3dmanufacturing/booleanoperations/2023/07"
+	nsSecureContent    = "http://schemas.microsoft.com/3dmanufacturing/securecontent/2019/04"
+	nsXMLEnc           = "http://www.w3.org/2001/04/xmlenc#"
 )
 
 const (
 	relTypeTexture3D = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dtexture"
 	relTypeThumbnail = "http://schemas.openxmlformats.org/package/2006/relationships/metadata/thumbnail"
 	relTypeModel3D   = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+	relTypeKeyStore  = "http://schemas.microsoft.com/3dmanufacturing/2019/04/keystore"
+	relTypeEncrypted = "http://schemas.openxmlformats.org/package/2006/relationships/encryptedfile"
+	relTypeSigOrigin = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/origin"
+	relTypeSignature = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/signature"
+	relTypeSigCert   = "http://schemas.openxmlformats.org/package/2006/relationships/digital-signature/certificate"
 )
 
 const (
@@ -108,6 +125,11 @@ const (
 	attrIdentifier         = "identifier"
 	attrRef                = "ref"
 	attrIndex              = "index"
+	attrTriangleSets       = "trianglesets"
+	attrTriangleSet        = "triangleset"
+	attrRefRange           = "refrange"
+	attrStartIndex         = "startindex"
+	attrEndIndex           = "endindex"
 	attrPreserve           = "preserve"
 	attrMetadata           = "metadata"
 	attrMetadataGroup      = "metadatagroup"
@@ -121,6 +143,81 @@ const (
 	attrPIndices           = "pindices"
 	attrPIDs               = "pids"
 	attrBlendMethods       = "blendmethods"
+	attrDisplayPropsID     = "displaypropertiesid"
+	attrPBSpecularDisplay  = "pbspeculardisplayproperties"
+	attrPBSpecular         = "pbspecular"
+	attrSpecularColor      = "specularcolor"
+	attrGlossiness         = "glossiness"
+	attrPBMetallicDisplay  = "pbmetallicdisplayproperties"
+	attrPBMetallic         = "pbmetallic"
+	attrMetallicness       = "metallicness"
+	attrRoughness          = "roughness"
+	attrPBSpecularTexture  = "pbspeculartexturedisplayproperties"
+	attrSpecularTextureID  = "speculartextureid"
+	attrGlossTextureID     = "glossinesstextureid"
+	attrDiffuseFactor      = "diffusefactor"
+	attrSpecularFactor     = "specularfactor"
+	attrGlossinessFactor   = "glossinessfactor"
+	attrPBMetallicTexture  = "pbmetallictexturedisplayproperties"
+	attrMetallicTextureID  = "metallictextureid"
+	attrRoughnessTextureID = "roughnesstextureid"
+	attrMetallicFactor     = "metallicfactor"
+	attrRoughnessFactor    = "roughnessfactor"
+	attrTranslucentDisplay = "translucentdisplayproperties"
+	attrTranslucent        = "translucent"
+	attrAttenuation        = "attenuation"
+	attrRefractiveIndex    = "refractiveindex"
+	attrKeyStore           = "keystore"
+	attrConsumer           = "consumer"
+	attrConsumerID         = "consumerid"
+	attrKeyID              = "keyid"
+	attrKeyValue           = "keyvalue"
+	attrResourceDataGroup  = "resourcedatagroup"
+	attrKeyUUID            = "keyuuid"
+	attrAccessRight        = "accessright"
+	attrConsumerIndex      = "consumerindex"
+	attrKEKParams          = "kekparams"
+	attrWrappingAlgorithm  = "wrappingalgorithm"
+	attrMgfAlgorithm       = "mgfalgorithm"
+	attrDigestMethod       = "digestmethod"
+	attrCipherData         = "cipherdata"
+	attrCipherValue        = "CipherValue"
+	attrResourceData       = "resourcedata"
+	attrCEKParams          = "cekparams"
+	attrEncryptionAlgo     = "encryptionalgorithm"
+	attrCompression        = "compression"
+	attrIV                 = "iv"
+	attrTag                = "tag"
+	attrAAD                = "aad"
+	attrDisplacement2D     = "displacement2d"
+	attrChannel            = "channel"
+	attrNormVectorGroup    = "normvectorgroup"
+	attrNormVector         = "normvector"
+	attrDisp2DGroup        = "disp2dgroup"
+	attrDisp2DCoord        = "disp2dcoord"
+	attrDispID             = "dispid"
+	attrNID                = "nid"
+	attrHeight             = "height"
+	attrOffset             = "offset"
+	attrN                  = "n"
+	attrF                  = "f"
+	attrDisplacementMesh   = "displacementmesh"
+	attrDID                = "did"
+	attrD1                 = "d1"
+	attrD2                 = "d2"
+	attrD3                 = "d3"
+	attrBooleanShape       = "booleanshape"
+	attrBoolean            = "boolean"
+	attrOperation          = "operation"
+	attrBalls              = "balls"
+	attrBall               = "ball"
+	attrBallMode           = "ballmode"
+	attrBallRadius         = "ballradius"
+	attrVIndex             = "vindex"
+	attrR                  = "r"
+	attrAlternatives       = "alternatives"
+	attrAlternative        = "alternative"
+	attrModelResolution    = "modelresolution"
 )
 
 // WarningLevel defines the level of a reader warning.
@@ -157,6 +254,15 @@ func newCapMode(s string) (t geo.CapMode, ok bool) {
 	return
 }
 
+func newBallMode(s string) (b geo.BallMode, ok bool) {
+	b, ok = map[string]geo.BallMode{
+		"none":  geo.BallModeNone,
+		"mixed": geo.BallModeMixed,
+		"all":   geo.BallModeAll,
+	}[s]
+	return
+}
+
 func newTextureFilter(s string) (t go3mf.TextureFilter, ok bool) {
 	t, ok = map[string]go3mf.TextureFilter{
 		"auto":    go3mf.TextureFilterAuto,
@@ -212,6 +318,15 @@ func newClipMode(s string) (c go3mf.ClipMode, ok bool) {
 	return
 }
 
+func newBooleanOperation(s string) (b go3mf.BooleanOperation, ok bool) {
+	b, ok = map[string]go3mf.BooleanOperation{
+		"union":        go3mf.BooleanUnion,
+		"difference":   go3mf.BooleanDifference,
+		"intersection": go3mf.BooleanIntersection,
+	}[s]
+	return
+}
+
 func newUnits(s string) (u go3mf.Units, ok bool) {
 	u, ok = map[string]go3mf.Units{
 		"millimeter": go3mf.UnitMillimeter,
@@ -231,3 +346,45 @@ func newBlendMethod(s string) (b go3mf.BlendMethod, ok bool) {
 	}[s]
 	return
 }
+
+func newChannel(s string) (c go3mf.Channel, ok bool) {
+	c, ok = map[string]go3mf.Channel{
+		"G": go3mf.ChannelG,
+		"R": go3mf.ChannelR,
+		"B": go3mf.ChannelB,
+		"A": go3mf.ChannelA,
+	}[s]
+	return
+}
+
+func newCompression(s string) (c go3mf.Compression, ok bool) {
+	c, ok = map[string]go3mf.Compression{
+		"none":    go3mf.CompressionNone,
+		"deflate": go3mf.CompressionDeflate,
+	}[s]
+	return
+}
+
+func newDigestMethod(s string) (h crypto.Hash, ok bool) {
+	h, ok = map[string]crypto.Hash{
+		"http://www.w3.org/2000/09/xmldsig#sha1":        crypto.SHA1,
+		"http://www.w3.org/2001/04/xmlenc#sha256":       crypto.SHA256,
+		"http://www.w3.org/2001/04/xmldsig-more#sha384": crypto.SHA384,
+		"http://www.w3.org/2001/04/xmlenc#sha512":       crypto.SHA512,
+	}[s]
+	return
+}
+
+func newSignatureMethod(s string) (a x509.SignatureAlgorithm, ok bool) {
+	a, ok = map[string]x509.SignatureAlgorithm{
+		"http://www.w3.org/2000/09/xmldsig#rsa-sha1":          x509.SHA1WithRSA,
+		"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256":   x509.SHA256WithRSA,
+		"http://www.w3.org/2001/04/xmldsig-more#rsa-sha384":   x509.SHA384WithRSA,
+		"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512":   x509.SHA512WithRSA,
+		"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha1":   x509.ECDSAWithSHA1,
+		"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256": x509.ECDSAWithSHA256,
+		"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha384": x509.ECDSAWithSHA384,
+		"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512": x509.ECDSAWithSHA512,
+	}[s]
+	return
+}
@@ -1,6 +1,8 @@
 package io3mf
 
 import (
+	"crypto"
+	"crypto/x509"
 	"reflect"
 	"testing"
 
@@ -157,6 +159,30 @@ func Test_newClipMode(t *testing.T) {
 	}
 }
 
+func Test_newBooleanOperation(t *testing.T) {
+	tests := []struct {
+		name   string
+		wantB  go3mf.BooleanOperation
+		wantOk bool
+	}{
+		{"union", go3mf.BooleanUnion, true},
+		{"difference", go3mf.BooleanDifference, true},
+		{"intersection", go3mf.BooleanIntersection, true},
+		{"empty", go3mf.BooleanUnion, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotB, gotOk := newBooleanOperation(tt.name)
+			if !reflect.DeepEqual(gotB, tt.wantB) {
+				t.Errorf("newBooleanOperation() gotB = %v, want %v", gotB, tt.wantB)
+			}
+			if gotOk != tt.wantOk {
+				t.Errorf("newBooleanOperation() gotOk = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
+
 func Test_newUnits(t *testing.T) {
 	tests := []struct {
 		name  string
@@ -209,6 +235,30 @@ func Test_newCapMode(t *testing.T) {
 	}
 }
 
+func Test_newBallMode(t *testing.T) {
+	tests := []struct {
+		name   string
+		wantB  geo.BallMode
+		wantOk bool
+	}{
+		{"none", geo.BallModeNone, true},
+		{"mixed", geo.BallModeMixed, true},
+		{"all", geo.BallModeAll, true},
+		{"empty", geo.BallModeNone, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotB, gotOk := newBallMode(tt.name)
+			if !reflect.DeepEqual(gotB, tt.wantB) {
+				t.Errorf("newBallMode() gotB = %v, want %v", gotB, tt.wantB)
+			}
+			if gotOk != tt.wantOk {
+				t.Errorf("newBallMode() gotOk = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
+
 func Test_newBlendMethod(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -231,3 +281,105 @@ func Test_newBlendMethod(t *testing.T) {
 		})
 	}
 }
+
+func Test_newCompression(t *testing.T) {
+	tests := []struct {
+		name   string
+		wantC  go3mf.Compression
+		wantOk bool
+	}{
+		{"none", go3mf.CompressionNone, true},
+		{"deflate", go3mf.CompressionDeflate, true},
+		{"empty", go3mf.CompressionNone, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotC, gotOk := newCompression(tt.name)
+			if !reflect.DeepEqual(gotC, tt.wantC) {
+				t.Errorf("newCompression() gotC = %v, want %v", gotC, tt.wantC)
+			}
+			if gotOk != tt.wantOk {
+				t.Errorf("newCompression() gotOk = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_newChannel(t *testing.T) {
+	tests := []struct {
+		name   string
+		wantC  go3mf.Channel
+		wantOk bool
+	}{
+		{"G", go3mf.ChannelG, true},
+		{"R", go3mf.ChannelR, true},
+		{"B", go3mf.ChannelB, true},
+		{"A", go3mf.ChannelA, true},
+		{"empty", go3mf.ChannelG, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotC, gotOk := newChannel(tt.name)
+			if !reflect.DeepEqual(gotC, tt.wantC) {
+				t.Errorf("newChannel() gotC = %v, want %v", gotC, tt.wantC)
+			}
+			if gotOk != tt.wantOk {
+				t.Errorf("newChannel() gotOk = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_newDigestMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		wantH  crypto.Hash
+		wantOk bool
+	}{
+		{"http://www.w3.org/2000/09/xmldsig#sha1", crypto.SHA1, true},
+		{"http://www.w3.org/2001/04/xmlenc#sha256", crypto.SHA256, true},
+		{"http://www.w3.org/2001/04/xmldsig-more#sha384", crypto.SHA384, true},
+		{"http://www.w3.org/2001/04/xmlenc#sha512", crypto.SHA512, true},
+		{"empty", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotH, gotOk := newDigestMethod(tt.name)
+			if !reflect.DeepEqual(gotH, tt.wantH) {
+				t.Errorf("newDigestMethod() gotH = %v, want %v", gotH, tt.wantH)
+			}
+			if gotOk != tt.wantOk {
+				t.Errorf("newDigestMethod() gotOk = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
+
+func Test_newSignatureMethod(t *testing.T) {
+	tests := []struct {
+		name   string
+		wantA  x509.SignatureAlgorithm
+		wantOk bool
+	}{
+		{"http://www.w3.org/2000/09/xmldsig#rsa-sha1", x509.SHA1WithRSA, true},
+		{"http://www.w3.org/2001/04/xmldsig-more#rsa-sha256", x509.SHA256WithRSA, true},
+		{"http://www.w3.org/2001/04/xmldsig-more#rsa-sha384", x509.SHA384WithRSA, true},
+		{"http://www.w3.org/2001/04/xmldsig-more#rsa-sha512", x509.SHA512WithRSA, true},
+		{"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha1", x509.ECDSAWithSHA1, true},
+		{"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha256", x509.ECDSAWithSHA256, true},
+		{"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha384", x509.ECDSAWithSHA384, true},
+		{"http://www.w3.org/2001/04/xmldsig-more#ecdsa-sha512", x509.ECDSAWithSHA512, true},
+		{"empty", x509.UnknownSignatureAlgorithm, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotA, gotOk := newSignatureMethod(tt.name)
+			if !reflect.DeepEqual(gotA, tt.wantA) {
+				t.Errorf("newSignatureMethod() gotA = %v, want %v", gotA, tt.wantA)
+			}
+			if gotOk != tt.wantOk {
+				t.Errorf("newSignatureMethod() gotOk = %v, want %v", gotOk, tt.wantOk)
+			}
+		})
+	}
+}
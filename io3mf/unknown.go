@@ -0,0 +1,92 @@
+package io3mf
+
+import (
+	"encoding/xml"
+
+	go3mf "github.com/qmuntal/go3mf"
+)
+
+// isForeignNamespace returns true if ns is not one of the namespaces supported natively
+// nor one of the reserved xml and xmlns namespaces.
+func isForeignNamespace(ns string) bool {
+	if ns == "" || ns == nsXML || ns == attrXmlns {
+		return false
+	}
+	for _, native := range nativeNamespaces {
+		if ns == native {
+			return false
+		}
+	}
+	return true
+}
+
+func (d *modelFile) preserveUnknown() bool {
+	return d.d != nil && d.d.PreserveUnknown
+}
+
+// unknownAttr stores the attribute in u if it belongs to a foreign namespace.
+func (d *modelFile) unknownAttr(u *go3mf.UnknownTokens, a xml.Attr) {
+	if d.preserveUnknown() && isForeignNamespace(a.Name.Space) {
+		u.Attr = append(u.Attr, a)
+	}
+}
+
+// unknownElement returns a decoder that records the element in u if it belongs to a foreign namespace,
+// else it returns nil so the element is skipped.
+func (d *modelFile) unknownElement(u *go3mf.UnknownTokens, name xml.Name) nodeDecoder {
+	if d.preserveUnknown() && isForeignNamespace(name.Space) {
+		return &tokenRecorder{name: name, tokens: &u.Tokens}
+	}
+	return nil
+}
+
+func (w *modelWriter) registerUnknownNamespaces(u go3mf.UnknownTokens) {
+	for _, a := range u.Attr {
+		w.registerForeignNamespace(a.Name.Space)
+	}
+	for _, t := range u.Tokens {
+		if t, ok := t.(xml.StartElement); ok {
+			w.registerForeignNamespace(t.Name.Space)
+			for _, a := range t.Attr {
+				w.registerForeignNamespace(a.Name.Space)
+			}
+		}
+	}
+}
+
+// unknownName returns the name qualified by the prefix registered for its namespace.
+func (w *modelWriter) unknownName(n xml.Name) xml.Name {
+	if n.Space == nsXML {
+		return xml.Name{Local: "xml:" + n.Local}
+	}
+	return w.name(n.Space, n.Local)
+}
+
+// unknownAttrs converts the attributes to their prefixed form,
+// dropping the namespace declarations as the model element already declares all of them.
+func (w *modelWriter) unknownAttrs(attrs []xml.Attr) []xml.Attr {
+	var out []xml.Attr
+	for _, a := range attrs {
+		if a.Name.Space == attrXmlns || (a.Name.Space == "" && a.Name.Local == attrXmlns) {
+			continue
+		}
+		out = append(out, xml.Attr{Name: w.unknownName(a.Name), Value: a.Value})
+	}
+	return out
+}
+
+func (w *modelWriter) writeUnknownTokens(u go3mf.UnknownTokens) {
+	for _, t := range u.Tokens {
+		if w.err != nil {
+			return
+		}
+		switch t := t.(type) {
+		case xml.StartElement:
+			w.err = w.x.EncodeToken(xml.StartElement{Name: w.unknownName(t.Name), Attr: w.unknownAttrs(t.Attr)})
+		case xml.EndElement:
+			w.err = w.x.EncodeToken(xml.EndElement{Name: w.unknownName(t.Name)})
+		case xml.CharData:
+			w.err = w.x.EncodeToken(t)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package io3mf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-test/deep"
+	go3mf "github.com/qmuntal/go3mf"
+)
+
+const nsOtherSpec = "http://www.other.com/3mf/2021"
+
+func unknownModelFixture() *modelBuilder {
+	return new(modelBuilder).withElement(`<model unit="millimeter" xmlns="` + nsCoreSpec + `" xmlns:qm="http://www.qmuntal.com" xmlns:o="` + nsOtherSpec + `" qm:tool="a">
+		<qm:settings qm:mode="fast" xml:lang="en"><qm:layer>0.2</qm:layer><o:empty /></qm:settings>
+		<resources>
+			<object id="1" type="model" qm:color="red">
+				<mesh o:precision="high">
+					<vertices><vertex x="0" y="0" z="0" /><vertex x="1" y="0" z="0" /><vertex x="0" y="1" z="0" /></vertices>
+					<triangles><triangle v1="0" v2="1" v3="2" /></triangles>
+					<o:hint>smooth</o:hint>
+				</mesh>
+				<qm:support />
+			</object>
+		</resources>
+		<build><item objectid="1" o:copies="2"><o:placement x="1" /></item></build>
+		<other />
+		</model>`)
+}
+
+func TestDecoder_processRootModel_PreserveUnknown(t *testing.T) {
+	tests := []struct {
+		name     string
+		preserve bool
+		model    go3mf.UnknownTokens
+		object   go3mf.UnknownTokens
+		mesh     go3mf.UnknownTokens
+		item     go3mf.UnknownTokens
+	}{
+		{"skip", false, go3mf.UnknownTokens{}, go3mf.UnknownTokens{}, go3mf.UnknownTokens{}, go3mf.UnknownTokens{}},
+		{"preserve", true,
+			go3mf.UnknownTokens{
+				Attr: []xml.Attr{{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "tool"}, Value: "a"}},
+				Tokens: []xml.Token{
+					xml.StartElement{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "settings"}, Attr: []xml.Attr{
+						{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "mode"}, Value: "fast"},
+						{Name: xml.Name{Space: nsXML, Local: "lang"}, Value: "en"},
+					}},
+					xml.StartElement{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "layer"}, Attr: []xml.Attr{}},
+					xml.CharData("0.2"),
+					xml.EndElement{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "layer"}},
+					xml.StartElement{Name: xml.Name{Space: nsOtherSpec, Local: "empty"}, Attr: []xml.Attr{}},
+					xml.EndElement{Name: xml.Name{Space: nsOtherSpec, Local: "empty"}},
+					xml.EndElement{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "settings"}},
+				},
+			},
+			go3mf.UnknownTokens{
+				Attr: []xml.Attr{{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "color"}, Value: "red"}},
+				Tokens: []xml.Token{
+					xml.StartElement{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "support"}, Attr: []xml.Attr{}},
+					xml.EndElement{Name: xml.Name{Space: "http://www.qmuntal.com", Local: "support"}},
+				},
+			},
+			go3mf.UnknownTokens{
+				Attr: []xml.Attr{{Name: xml.Name{Space: nsOtherSpec, Local: "precision"}, Value: "high"}},
+				Tokens: []xml.Token{
+					xml.StartElement{Name: xml.Name{Space: nsOtherSpec, Local: "hint"}, Attr: []xml.Attr{}},
+					xml.CharData("smooth"),
+					xml.EndElement{Name: xml.Name{Space: nsOtherSpec, Local: "hint"}},
+				},
+			},
+			go3mf.UnknownTokens{
+				Attr: []xml.Attr{{Name: xml.Name{Space: nsOtherSpec, Local: "copies"}, Value: "2"}},
+				Tokens: []xml.Token{
+					xml.StartElement{Name: xml.Name{Space: nsOtherSpec, Local: "placement"}, Attr: []xml.Attr{{Name: xml.Name{Local: "x"}, Value: "1"}}},
+					xml.EndElement{Name: xml.Name{Space: nsOtherSpec, Local: "placement"}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := &go3mf.Model{Path: "/3d/3dmodel.model"}
+			d := &Decoder{Strict: true, PreserveUnknown: tt.preserve}
+			if err := d.processRootModel(context.Background(), unknownModelFixture().build(), got); err != nil {
+				t.Errorf("Decoder.processRootModel() unexpected error = %v", err)
+				return
+			}
+			mesh := got.Resources[0].(*go3mf.MeshResource)
+			if diff := deep.Equal(got.Unknown, tt.model); diff != nil {
+				t.Errorf("Decoder.processRootModel() model = %v", diff)
+			}
+			if diff := deep.Equal(mesh.Unknown, tt.object); diff != nil {
+				t.Errorf("Decoder.processRootModel() object = %v", diff)
+			}
+			if diff := deep.Equal(mesh.MeshUnknown, tt.mesh); diff != nil {
+				t.Errorf("Decoder.processRootModel() mesh = %v", diff)
+			}
+			if diff := deep.Equal(got.BuildItems[0].Unknown, tt.item); diff != nil {
+				t.Errorf("Decoder.processRootModel() item = %v", diff)
+			}
+		})
+	}
+}
+
+func TestEncoder_roundTrip_PreserveUnknown(t *testing.T) {
+	want, got := &go3mf.Model{Path: "/3d/3dmodel.model"}, &go3mf.Model{Path: "/3d/3dmodel.model"}
+	d := &Decoder{Strict: true, PreserveUnknown: true}
+	if err := d.processRootModel(context.Background(), unknownModelFixture().build(), want); err != nil {
+		t.Fatalf("Decoder.processRootModel() unexpected error = %v", err)
+	}
+	buff := new(bytes.Buffer)
+	mw := modelWriter{model: want, path: want.Path, isRoot: true}
+	if err := mw.Encode(context.Background(), buff); err != nil {
+		t.Fatalf("modelWriter.Encode() unexpected error = %v", err)
+	}
+	f := new(mockFile)
+	f.On("Name").Return(want.Path).Maybe()
+	f.On("Open").Return(ioutil.NopCloser(buff), nil).Maybe()
+	d = &Decoder{Strict: true, PreserveUnknown: true}
+	if err := d.processRootModel(context.Background(), f, got); err != nil {
+		t.Fatalf("Decoder.processRootModel() unexpected error = %v", err)
+	}
+	deep.CompareUnexportedFields = true
+	deep.MaxDepth = 20
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Encoder.Encode() = %v", diff)
+	}
+}
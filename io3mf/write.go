@@ -0,0 +1,777 @@
+package io3mf
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"image/color"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	go3mf "github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+)
+
+const (
+	uriDefault3DModel  = "/3D/3dmodel.model"
+	contentType3DModel = "application/vnd.ms-package.3dmanufacturing-3dmodel+xml"
+)
+
+type packageWriter interface {
+	Create(name, contentType string, rels []relationship) (io.Writer, error)
+	AddRelationship(relationship)
+	Close() error
+}
+
+type packageRelationship struct {
+	relType   string
+	targetURI string
+}
+
+func (r *packageRelationship) Type() string {
+	return r.relType
+}
+
+func (r *packageRelationship) TargetURI() string {
+	return r.targetURI
+}
+
+// WriteCloser wrapps an Encoder than can be closed.
+type WriteCloser struct {
+	f *os.File
+	*Encoder
+}
+
+// CreateWriter will create the 3MF file specified by name and return a WriteCloser.
+func CreateWriter(name string) (*WriteCloser, error) {
+	f, err := os.Create(name)
+	if err != nil {
+		return nil, err
+	}
+	return &WriteCloser{f: f, Encoder: NewEncoder(f)}, nil
+}
+
+// Close closes the 3MF file, rendering it unusable for I/O.
+func (w *WriteCloser) Close() error {
+	return w.f.Close()
+}
+
+// A MeshStream produces the vertices and triangles of a mesh object
+// at the time it is encoded, so they never have to be held in memory.
+type MeshStream interface {
+	// Vertices calls yield for each vertex, in index order.
+	// It must stop and return the error as soon as yield fails.
+	Vertices(yield func(geo.Point3D) error) error
+	// Faces calls yield for each triangle, in order.
+	// It must stop and return the error as soon as yield fails.
+	Faces(yield func(geo.Face) error) error
+}
+
+// Encoder implements a 3mf file encoder.
+// A model read by a Decoder is encoded back without losing any of the decoded data,
+// so a decode-encode-decode cycle produces an equivalent model.
+// The data decoded by a registered Extension is encoded by the same extension,
+// so encoding fails if it does not implement ResourceEncoder or ObjectEncoder.
+//
+// When the model has a KeyStore the parts it lists are encrypted with new content encryption keys,
+// which are wrapped for each consumer using the KeyProvider.
+// Encrypted parts are buffered in memory, even if their meshes come from a MeshStream.
+type Encoder struct {
+	KeyProvider KeyProvider
+
+	w         packageWriter
+	streams   map[*go3mf.MeshResource]MeshStream
+	encrypter *contentEncrypter
+}
+
+// NewEncoder returns a new Encoder writing a 3mf file to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{
+		w: newOpcWriter(w),
+	}
+}
+
+// SetMeshStream sets s as the source of the vertices and triangles of the mesh object r.
+// The stream replaces the nodes and faces of r.Mesh, which can be empty,
+// and it is written straight into the package without any intermediate buffer.
+// The triangle sets and the beam lattice, if any, are still taken from r.Mesh.
+func (e *Encoder) SetMeshStream(r *go3mf.MeshResource, s MeshStream) {
+	if e.streams == nil {
+		e.streams = make(map[*go3mf.MeshResource]MeshStream)
+	}
+	e.streams[r] = s
+}
+
+// Encode writes the model and all its attachments into the 3mf package.
+// The package is finished once Encode returns, but the underlying writer is not closed.
+func (e *Encoder) Encode(model *go3mf.Model) error {
+	return e.EncodeContext(context.Background(), model)
+}
+
+// EncodeContext writes the model and all its attachments into the 3mf package.
+// The package is finished once EncodeContext returns, but the underlying writer is not closed.
+// Resources whose ModelPath is not the root path are written in separate model parts,
+// which are referenced from the root model as production attachments.
+func (e *Encoder) EncodeContext(ctx context.Context, model *go3mf.Model) error {
+	rootPath := model.Path
+	if rootPath == "" {
+		rootPath = uriDefault3DModel
+	}
+	encrypter, err := newContentEncrypter(model.KeyStore, e.KeyProvider)
+	if err != nil {
+		return err
+	}
+	e.encrypter = encrypter
+	e.w.AddRelationship(&packageRelationship{relType: relTypeModel3D, targetURI: rootPath})
+	paths := modelPaths(model, rootPath)
+	for _, path := range paths {
+		if err := e.writeModelPart(ctx, model, path, false, attachmentRelationships(model, rootPath, path)); err != nil {
+			return err
+		}
+	}
+	rels := attachmentRelationships(model, rootPath, rootPath)
+	if e.writeThumbnail(model) {
+		rels = append(rels, &packageRelationship{relType: relTypeThumbnail, targetURI: model.Thumbnail.Path})
+	}
+	for _, path := range paths {
+		rels = append(rels, &packageRelationship{relType: relTypeModel3D, targetURI: path})
+	}
+	if err := e.writeModelPart(ctx, model, rootPath, true, rels); err != nil {
+		return err
+	}
+	if err := e.writeAttachments(model); err != nil {
+		return err
+	}
+	if err := e.writeKeyStore(); err != nil {
+		return err
+	}
+	return e.w.Close()
+}
+
+func (e *Encoder) writeModelPart(ctx context.Context, model *go3mf.Model, path string, isRoot bool, rels []relationship) error {
+	return e.writePart(path, contentType3DModel, rels, func(w io.Writer) error {
+		mw := modelWriter{model: model, path: path, isRoot: isRoot, streams: e.streams}
+		return mw.Encode(ctx, w)
+	})
+}
+
+// writePart creates a part and fills it with write,
+// encrypting the content when the part is protected by the keystore.
+func (e *Encoder) writePart(name, contentType string, rels []relationship, write func(io.Writer) error) error {
+	w, err := e.w.Create(name, contentType, rels)
+	if err != nil {
+		return err
+	}
+	if !e.encrypter.protects(name) {
+		return write(w)
+	}
+	buff := new(bytes.Buffer)
+	if err = write(buff); err != nil {
+		return err
+	}
+	data, err := e.encrypter.encrypt(name, buff.Bytes())
+	if err == nil {
+		_, err = w.Write(data)
+	}
+	return err
+}
+
+// writeKeyStore writes the keystore once all the encrypted parts are written,
+// as it holds the IVs and tags used to encrypt them.
+func (e *Encoder) writeKeyStore() error {
+	if e.encrypter == nil {
+		return nil
+	}
+	w, err := e.w.Create(uriKeyStore, contentTypeKeyStore, e.encrypter.relationships())
+	if err != nil {
+		return err
+	}
+	e.w.AddRelationship(&packageRelationship{relType: relTypeKeyStore, targetURI: uriKeyStore})
+	return writeKeyStore(w, &e.encrypter.ks)
+}
+
+// attachmentRelationships returns the relationships from the model part to its attachments.
+// Textures belong to the model part that references them, the rest belong to the root model.
+func attachmentRelationships(model *go3mf.Model, rootPath, path string) []relationship {
+	textures := textureParts(model, rootPath)
+	var rels []relationship
+	for _, a := range model.Attachments {
+		owner, ok := textures[a.Path]
+		if !ok || a.RelationshipType != relTypeTexture3D {
+			owner = rootPath
+		}
+		if owner == path {
+			rels = append(rels, &packageRelationship{relType: a.RelationshipType, targetURI: a.Path})
+		}
+	}
+	return rels
+}
+
+// modelPaths returns the non-root model parts, first the ones listed
+// in the production attachments and then the ones only known by the resources.
+func modelPaths(model *go3mf.Model, rootPath string) []string {
+	var paths []string
+	add := func(path string) {
+		if path == "" || path == rootPath {
+			return
+		}
+		for _, p := range paths {
+			if p == path {
+				return
+			}
+		}
+		paths = append(paths, path)
+	}
+	for _, a := range model.ProductionAttachments {
+		add(a.Path)
+	}
+	for _, r := range model.Resources {
+		path, _ := r.Identify()
+		add(path)
+	}
+	return paths
+}
+
+// textureParts maps each texture path, including the displacement textures,
+// to the model part that references it.
+// The root model takes precedence when more than one part references the same texture.
+func textureParts(model *go3mf.Model, rootPath string) map[string]string {
+	parts := make(map[string]string)
+	for _, r := range model.Resources {
+		var texture string
+		switch t := r.(type) {
+		case *go3mf.Texture2DResource:
+			texture = t.Path
+		case *go3mf.Displacement2DResource:
+			texture = t.Path
+		default:
+			continue
+		}
+		path, _ := r.Identify()
+		if path == "" {
+			path = rootPath
+		}
+		if _, ok := parts[texture]; !ok || path == rootPath {
+			parts[texture] = path
+		}
+	}
+	return parts
+}
+
+// writeThumbnail returns true if the model thumbnail is not already
+// part of the attachments and has to be written as a standalone part.
+func (e *Encoder) writeThumbnail(model *go3mf.Model) bool {
+	if model.Thumbnail == nil {
+		return false
+	}
+	for _, a := range model.Attachments {
+		if a.Path == model.Thumbnail.Path {
+			return false
+		}
+	}
+	return true
+}
+
+func (e *Encoder) writeAttachments(model *go3mf.Model) error {
+	for _, a := range model.Attachments {
+		if err := e.writeAttachment(a); err != nil {
+			return err
+		}
+	}
+	if e.writeThumbnail(model) {
+		return e.writeAttachment(model.Thumbnail)
+	}
+	return nil
+}
+
+func (e *Encoder) writeAttachment(a *go3mf.Attachment) error {
+	return e.writePart(a.Path, attachmentContentType(a.Path), nil, func(w io.Writer) (err error) {
+		if a.Stream != nil {
+			_, err = io.Copy(w, attachmentReader(a.Stream))
+		}
+		return
+	})
+}
+
+// attachmentReader avoids draining the attachment stream when it supports
+// random access, so the same model can be encoded more than once.
+func attachmentReader(r io.Reader) io.Reader {
+	if ra, ok := r.(interface {
+		io.ReaderAt
+		Size() int64
+	}); ok {
+		return io.NewSectionReader(ra, 0, ra.Size())
+	}
+	return r
+}
+
+func attachmentContentType(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png":
+		return go3mf.TextureTypePNG.String()
+	case ".jpeg", ".jpg":
+		return go3mf.TextureTypeJPEG.String()
+	case ".model":
+		return contentType3DModel
+	case ".xml":
+		return "application/xml"
+	}
+	return "application/octet-stream"
+}
+
+type modelWriter struct {
+	x            *xml.Encoder
+	model        *go3mf.Model
+	path         string
+	isRoot       bool
+	streams      map[*go3mf.MeshResource]MeshStream
+	prefixes     map[string]string
+	namespaces   []string
+	requiredExts []string
+	// extensions holds the tokens encoded by the registered extensions,
+	// for the extension resources and for the object extension data.
+	extensions map[interface{}]go3mf.UnknownTokens
+	// usedExtensions holds the namespaces of the extensions that encoded any data.
+	usedExtensions map[string]struct{}
+	err            error
+}
+
+func (w *modelWriter) Encode(ctx context.Context, iw io.Writer) error {
+	w.x = xml.NewEncoder(iw)
+	w.registerNamespaces()
+	if w.err != nil {
+		return w.err
+	}
+	w.writeProcInst()
+	w.writeModel(ctx)
+	if w.err == nil {
+		w.err = w.x.Flush()
+	}
+	return w.err
+}
+
+func (w *modelWriter) writeProcInst() {
+	if w.err == nil {
+		w.err = w.x.EncodeToken(xml.ProcInst{Target: "xml", Inst: []byte(`version="1.0" encoding="UTF-8"`)})
+	}
+}
+
+func (w *modelWriter) registerNamespaces() {
+	w.prefixes = make(map[string]string)
+	var (
+		uses     struct{ material, production, beamLattice, balls, slice, displacement, triangleSets, booleans, alternatives bool }
+		metadata [][]go3mf.Metadata
+	)
+	var unknowns []go3mf.UnknownTokens
+	if w.isRoot {
+		uses.production = w.model.UUID != ""
+		metadata = append(metadata, w.model.Metadata)
+		unknowns = append(unknowns, w.model.Unknown)
+		for _, item := range w.model.BuildItems {
+			uses.production = uses.production || item.UUID != "" || w.isExternal(item.Object)
+			metadata = append(metadata, item.Metadata)
+			unknowns = append(unknowns, item.Unknown)
+		}
+	}
+	for _, r := range w.resources() {
+		switch r := r.(type) {
+		case *go3mf.BaseMaterialsResource:
+		case *go3mf.ColorGroupResource, *go3mf.Texture2DResource, *go3mf.Texture2DGroupResource,
+			*go3mf.CompositeMaterialsResource, *go3mf.MultiPropertiesResource,
+			*go3mf.PBSpecularDisplayPropertiesResource, *go3mf.PBMetallicDisplayPropertiesResource,
+			*go3mf.PBSpecularTextureDisplayPropertiesResource, *go3mf.PBMetallicTextureDisplayPropertiesResource,
+			*go3mf.TranslucentDisplayPropertiesResource:
+			uses.material = true
+		case *go3mf.SliceStackResource:
+			uses.slice = true
+		case *go3mf.Displacement2DResource, *go3mf.NormVectorGroupResource, *go3mf.Disp2DGroupResource:
+			uses.displacement = true
+		case *go3mf.DisplacementMeshResource:
+			uses.displacement = true
+			uses.production = uses.production || r.UUID != ""
+			uses.slice = uses.slice || r.SliceStackID != 0
+			uses.alternatives = uses.alternatives || len(r.Alternatives) > 0
+			unknowns = append(unknowns, w.encodeObjectExtensions(&r.ObjectResource))
+			metadata = append(metadata, r.Metadata)
+			unknowns = append(unknowns, r.Unknown)
+		case *go3mf.MeshResource:
+			uses.production = uses.production || r.UUID != ""
+			uses.slice = uses.slice || r.SliceStackID != 0
+			uses.beamLattice = uses.beamLattice || hasBeamLattice(r)
+			uses.balls = uses.balls || hasBalls(r)
+			uses.triangleSets = uses.triangleSets || (r.Mesh != nil && len(r.Mesh.TriangleSets) > 0)
+			uses.alternatives = uses.alternatives || len(r.Alternatives) > 0
+			unknowns = append(unknowns, w.encodeObjectExtensions(&r.ObjectResource))
+			metadata = append(metadata, r.Metadata)
+			unknowns = append(unknowns, r.Unknown, r.MeshUnknown)
+		case *go3mf.BooleanShapeResource:
+			uses.booleans = true
+			uses.production = uses.production || r.UUID != "" || w.isExternal(r.Object)
+			uses.slice = uses.slice || r.SliceStackID != 0
+			for _, op := range r.Operands {
+				uses.production = uses.production || w.isExternal(op.Object)
+			}
+			uses.alternatives = uses.alternatives || len(r.Alternatives) > 0
+			unknowns = append(unknowns, w.encodeObjectExtensions(&r.ObjectResource))
+			metadata = append(metadata, r.Metadata)
+			unknowns = append(unknowns, r.Unknown)
+		case *go3mf.ComponentsResource:
+			uses.production = uses.production || r.UUID != ""
+			uses.slice = uses.slice || r.SliceStackID != 0
+			for _, c := range r.Components {
+				uses.production = uses.production || c.UUID != "" || w.isExternal(c.Object)
+			}
+			uses.alternatives = uses.alternatives || len(r.Alternatives) > 0
+			unknowns = append(unknowns, w.encodeObjectExtensions(&r.ObjectResource))
+			metadata = append(metadata, r.Metadata)
+			unknowns = append(unknowns, r.Unknown)
+		default:
+			unknowns = append(unknowns, w.encodeExtensionResource(r))
+		}
+	}
+	if uses.material {
+		w.registerNamespace("m", nsMaterialSpec, true)
+	}
+	if uses.production {
+		w.registerNamespace("p", nsProductionSpec, true)
+	}
+	if uses.beamLattice {
+		w.registerNamespace("b", nsBeamLatticeSpec, true)
+	}
+	if uses.balls {
+		w.registerNamespace("b2", nsBallsSpec, true)
+	}
+	if uses.slice {
+		w.registerNamespace("s", nsSliceSpec, true)
+	}
+	if uses.displacement {
+		w.registerNamespace("d", nsDisplacementSpec, true)
+	}
+	if uses.triangleSets {
+		w.registerNamespace("t", nsTriangleSetsSpec, false)
+	}
+	if uses.booleans {
+		w.registerNamespace("bo", nsBooleanSpec, true)
+	}
+	if uses.alternatives {
+		w.registerNamespace("pa", nsAlternativesSpec, false)
+	}
+	w.registerRequiredExtensions()
+	for _, m := range metadata {
+		w.registerMetadataNamespaces(m)
+	}
+	for _, u := range unknowns {
+		w.registerUnknownNamespaces(u)
+	}
+}
+
+func (w *modelWriter) encodeExtensionResource(r go3mf.Resource) go3mf.UnknownTokens {
+	var (
+		u  go3mf.UnknownTokens
+		ns string
+	)
+	if w.err == nil {
+		if ns, u.Tokens, w.err = encodeExtensionResource(r); w.err == nil {
+			w.setExtension(r, u)
+			w.useExtension(ns)
+		}
+	}
+	return u
+}
+
+func (w *modelWriter) encodeObjectExtensions(o *go3mf.ObjectResource) go3mf.UnknownTokens {
+	var u go3mf.UnknownTokens
+	if w.err == nil && len(o.Extensions) > 0 {
+		u, w.err = encodeExtensionObject(o)
+		w.setExtension(o, u)
+		for ns := range o.Extensions {
+			w.useExtension(ns)
+		}
+	}
+	return u
+}
+
+func (w *modelWriter) useExtension(ns string) {
+	if w.usedExtensions == nil {
+		w.usedExtensions = make(map[string]struct{})
+	}
+	w.usedExtensions[ns] = struct{}{}
+}
+
+// registerRequiredExtensions registers the namespaces of the used extensions that are required,
+// before they are registered as foreign namespaces, which are never required.
+func (w *modelWriter) registerRequiredExtensions() {
+	namespaces := make([]string, 0, len(w.usedExtensions))
+	for ns := range w.usedExtensions {
+		if requiredExtension(ns) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		w.registerNamespace("ns"+strconv.Itoa(len(w.namespaces)), ns, true)
+	}
+}
+
+func (w *modelWriter) setExtension(key interface{}, u go3mf.UnknownTokens) {
+	if w.extensions == nil {
+		w.extensions = make(map[interface{}]go3mf.UnknownTokens)
+	}
+	w.extensions[key] = u
+}
+
+func (w *modelWriter) registerNamespace(prefix, ns string, required bool) {
+	if _, ok := w.prefixes[ns]; ok {
+		return
+	}
+	w.prefixes[ns] = prefix
+	w.namespaces = append(w.namespaces, ns)
+	if required {
+		w.requiredExts = append(w.requiredExts, prefix)
+	}
+}
+
+func (w *modelWriter) registerMetadataNamespaces(metadata []go3mf.Metadata) {
+	for _, m := range metadata {
+		i := strings.LastIndexByte(m.Name, ':')
+		if i <= 0 || m.Name[:i] == nsCoreSpec {
+			continue
+		}
+		w.registerForeignNamespace(m.Name[:i])
+	}
+}
+
+// registerForeignNamespace registers a namespace that is not required by the model,
+// generating a prefix for the ones that are not supported natively.
+func (w *modelWriter) registerForeignNamespace(ns string) {
+	if ns == "" || ns == nsCoreSpec || ns == nsXML || ns == attrXmlns {
+		return
+	}
+	if prefix, ok := map[string]string{
+		nsMaterialSpec:     "m",
+		nsProductionSpec:   "p",
+		nsAlternativesSpec: "pa",
+		nsBeamLatticeSpec:  "b",
+		nsBallsSpec:        "b2",
+		nsSliceSpec:        "s",
+		nsDisplacementSpec: "d",
+		nsTriangleSetsSpec: "t",
+		nsBooleanSpec:      "bo",
+	}[ns]; ok {
+		w.registerNamespace(prefix, ns, false)
+	} else {
+		w.registerNamespace("ns"+strconv.Itoa(len(w.namespaces)), ns, false)
+	}
+}
+
+// resources returns the resources that are stored in the model file.
+func (w *modelWriter) resources() []go3mf.Resource {
+	var rs []go3mf.Resource
+	for _, r := range w.model.Resources {
+		path, _ := r.Identify()
+		if w.inModelFile(path) {
+			rs = append(rs, r)
+		}
+	}
+	return rs
+}
+
+// isExternal returns true if the object is not stored in the model file.
+func (w *modelWriter) isExternal(o go3mf.Object) bool {
+	if o == nil {
+		return false
+	}
+	path, _ := o.Identify()
+	return !w.inModelFile(path)
+}
+
+// inModelFile returns true if path points to the model file,
+// an empty path always points to the root model.
+func (w *modelWriter) inModelFile(path string) bool {
+	if path == "" {
+		return w.isRoot
+	}
+	return path == w.path
+}
+
+func (w *modelWriter) writeModel(ctx context.Context) {
+	attrs := []xml.Attr{
+		w.attr(attrUnit, w.model.Units.String()),
+	}
+	if w.isRoot && w.model.Language != "" {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xml:" + attrLang}, Value: w.model.Language})
+	}
+	attrs = append(attrs, w.attr(attrXmlns, nsCoreSpec))
+	for _, ns := range w.namespaces {
+		attrs = append(attrs, w.attr(attrXmlns+":"+w.prefixes[ns], ns))
+	}
+	if len(w.requiredExts) > 0 {
+		attrs = append(attrs, w.attr(attrReqExt, strings.Join(w.requiredExts, " ")))
+	}
+	if w.isRoot {
+		attrs = append(attrs, w.unknownAttrs(w.model.Unknown.Attr)...)
+	}
+	w.start(attrModel, attrs...)
+	if w.isRoot {
+		for _, m := range w.model.Metadata {
+			w.writeMetadata(m)
+		}
+	}
+	w.writeResources(ctx)
+	if w.isRoot {
+		w.writeBuild()
+	} else {
+		// Non-root models must have an empty build.
+		w.start(attrBuild)
+		w.end(attrBuild)
+	}
+	if w.isRoot {
+		w.writeUnknownTokens(w.model.Unknown)
+	}
+	w.end(attrModel)
+}
+
+func (w *modelWriter) writeResources(ctx context.Context) {
+	w.start(attrResources)
+	for _, r := range w.resources() {
+		if w.err != nil {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			w.err = ctx.Err()
+			return
+		default: // Default is must to avoid blocking
+		}
+		w.writeResource(r)
+	}
+	w.end(attrResources)
+}
+
+func (w *modelWriter) writeResource(r go3mf.Resource) {
+	switch r := r.(type) {
+	case *go3mf.BaseMaterialsResource:
+		w.writeBaseMaterials(r)
+	case *go3mf.ColorGroupResource:
+		w.writeColorGroup(r)
+	case *go3mf.Texture2DResource:
+		w.writeTexture2D(r)
+	case *go3mf.Texture2DGroupResource:
+		w.writeTexture2DGroup(r)
+	case *go3mf.CompositeMaterialsResource:
+		w.writeCompositeMaterials(r)
+	case *go3mf.MultiPropertiesResource:
+		w.writeMultiProperties(r)
+	case *go3mf.PBSpecularDisplayPropertiesResource:
+		w.writePBSpecularDisplay(r)
+	case *go3mf.PBMetallicDisplayPropertiesResource:
+		w.writePBMetallicDisplay(r)
+	case *go3mf.PBSpecularTextureDisplayPropertiesResource:
+		w.writePBSpecularTexture(r)
+	case *go3mf.PBMetallicTextureDisplayPropertiesResource:
+		w.writePBMetallicTexture(r)
+	case *go3mf.TranslucentDisplayPropertiesResource:
+		w.writeTranslucentDisplay(r)
+	case *go3mf.SliceStackResource:
+		w.writeSliceStack(r)
+	case *go3mf.MeshResource:
+		w.writeMeshObject(r)
+	case *go3mf.ComponentsResource:
+		w.writeComponentsObject(r)
+	case *go3mf.BooleanShapeResource:
+		w.writeBooleanShapeObject(r)
+	case *go3mf.Displacement2DResource:
+		w.writeDisplacement2D(r)
+	case *go3mf.NormVectorGroupResource:
+		w.writeNormVectorGroup(r)
+	case *go3mf.Disp2DGroupResource:
+		w.writeDisp2DGroup(r)
+	case *go3mf.DisplacementMeshResource:
+		w.writeDisplacementMeshObject(r)
+	default:
+		w.writeUnknownTokens(w.extensions[r])
+	}
+}
+
+// name returns the qualified name of an element or attribute.
+// Namespaces without prefix, such as the core one, are the default namespace.
+func (w *modelWriter) name(ns, local string) xml.Name {
+	if prefix := w.prefixes[ns]; prefix != "" {
+		return xml.Name{Local: prefix + ":" + local}
+	}
+	return xml.Name{Local: local}
+}
+
+func (w *modelWriter) attr(local, value string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: local}, Value: value}
+}
+
+func (w *modelWriter) attrNS(ns, local, value string) xml.Attr {
+	return xml.Attr{Name: w.name(ns, local), Value: value}
+}
+
+func (w *modelWriter) start(local string, attrs ...xml.Attr) {
+	w.startNS(nsCoreSpec, local, attrs...)
+}
+
+func (w *modelWriter) startNS(ns, local string, attrs ...xml.Attr) {
+	if w.err == nil {
+		w.err = w.x.EncodeToken(xml.StartElement{Name: w.name(ns, local), Attr: attrs})
+	}
+}
+
+func (w *modelWriter) end(local string) {
+	w.endNS(nsCoreSpec, local)
+}
+
+func (w *modelWriter) endNS(ns, local string) {
+	if w.err == nil {
+		w.err = w.x.EncodeToken(xml.EndElement{Name: w.name(ns, local)})
+	}
+}
+
+func (w *modelWriter) element(local string, attrs ...xml.Attr) {
+	w.start(local, attrs...)
+	w.end(local)
+}
+
+func (w *modelWriter) elementNS(ns, local string, attrs ...xml.Attr) {
+	w.startNS(ns, local, attrs...)
+	w.endNS(ns, local)
+}
+
+func (w *modelWriter) text(s string) {
+	if w.err == nil {
+		w.err = w.x.EncodeToken(xml.CharData(s))
+	}
+}
+
+func formatUint32(v uint32) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+func formatFloat32(v float32) string {
+	return strconv.FormatFloat(float64(v), 'f', -1, 32)
+}
+
+func formatFloat64(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+func formatRGBA(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x%02x", c.R, c.G, c.B, c.A)
+}
+
+func formatMatrix(t geo.Matrix) string {
+	values := [12]float32{t[0], t[1], t[2], t[4], t[5], t[6], t[8], t[9], t[10], t[12], t[13], t[14]}
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = formatFloat32(v)
+	}
+	return strings.Join(s, " ")
+}
@@ -0,0 +1,617 @@
+package io3mf
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image/color"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/go-test/deep"
+	go3mf "github.com/qmuntal/go3mf"
+	"github.com/qmuntal/go3mf/geo"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPackageWriter struct {
+	mock.Mock
+}
+
+func newMockPackageWriter(createErr, closeErr bool) *mockPackageWriter {
+	m := new(mockPackageWriter)
+	var err error
+	if createErr {
+		err = errors.New("")
+	}
+	m.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(new(bytes.Buffer), err).Maybe()
+	m.On("AddRelationship", mock.Anything).Return().Maybe()
+	err = nil
+	if closeErr {
+		err = errors.New("")
+	}
+	m.On("Close").Return(err).Maybe()
+	return m
+}
+
+func (m *mockPackageWriter) Create(name, contentType string, rels []relationship) (io.Writer, error) {
+	args := m.Called(name, contentType, rels)
+	return args.Get(0).(io.Writer), args.Error(1)
+}
+
+func (m *mockPackageWriter) AddRelationship(r relationship) {
+	m.Called(r)
+}
+
+func (m *mockPackageWriter) Close() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func TestNewEncoder(t *testing.T) {
+	tests := []struct {
+		name string
+	}{
+		{"base"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewEncoder(new(bytes.Buffer))
+			if _, ok := got.w.(*opcWriter); !ok {
+				t.Errorf("NewEncoder() = %v, want an opcWriter", got.w)
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	const rootPath = "/3D/3dmodel.model"
+	baseMaterials := &go3mf.BaseMaterialsResource{ID: 5, ModelPath: rootPath, Materials: []go3mf.BaseMaterial{
+		{Name: "Blue PLA", Color: color.RGBA{0, 0, 255, 255}},
+		{Name: "Red ABS", Color: color.RGBA{255, 0, 0, 255}},
+	}}
+	baseTexture := &go3mf.Texture2DResource{ID: 6, ModelPath: rootPath, Path: "/3D/Texture/msLogo.png", ContentType: go3mf.TextureTypePNG, TileStyleU: go3mf.TileWrap, TileStyleV: go3mf.TileMirror, Filter: go3mf.TextureFilterNearest}
+	colorGroup := &go3mf.ColorGroupResource{ID: 1, ModelPath: rootPath, Colors: []color.RGBA{{R: 255, G: 255, B: 255, A: 255}, {R: 0, G: 0, B: 0, A: 255}, {R: 26, G: 181, B: 103, A: 0}}}
+	texGroup := &go3mf.Texture2DGroupResource{ID: 2, ModelPath: rootPath, TextureID: 6, Coords: []go3mf.TextureCoord{{0.3, 0.5}, {0.3, 0.8}, {0.5, 0.8}, {0.5, 0.5}}}
+	compositeGroup := &go3mf.CompositeMaterialsResource{ID: 4, ModelPath: rootPath, MaterialID: 5, Indices: []uint32{1, 2}, Composites: []go3mf.Composite{{Values: []float64{0.5, 0.5}}, {Values: []float64{0.2, 0.8}}}}
+	multiGroup := &go3mf.MultiPropertiesResource{ID: 9, ModelPath: rootPath, BlendMethods: []go3mf.BlendMethod{go3mf.BlendMultiply}, Resources: []uint32{5, 2}, Multis: []go3mf.Multi{{ResourceIndices: []uint32{0, 0}}, {ResourceIndices: []uint32{1, 0}}}}
+	sliceStack := &go3mf.SliceStackResource{ID: 3, ModelPath: rootPath, Stack: go3mf.SliceStack{
+		BottomZ: 1,
+		Slices: []*geo.Slice{
+			{
+				TopZ:     0.1,
+				Vertices: []geo.Point2D{{1.01, 1.02}, {9.03, 1.04}, {9.05, 9.06}, {1.07, 9.08}},
+				Polygons: [][]int{{0, 1, 2, 3, 0}},
+			},
+		},
+	}}
+	sliceStackRef := &go3mf.SliceStackResource{ID: 7, ModelPath: rootPath, Stack: go3mf.SliceStack{BottomZ: 1.1, Refs: []go3mf.SliceRef{{SliceStackID: 3}}}}
+	meshRes := &go3mf.MeshResource{
+		ObjectResource: go3mf.ObjectResource{
+			ID: 8, Name: "Box 1", ModelPath: rootPath, SliceStackID: 3, SliceResoultion: go3mf.ResolutionLow, Thumbnail: "/a.png",
+			DefaultPropertyID: 5, DefaultPropertyIndex: 1, PartNumber: "11111111-1111-1111-1111-111111111111", UUID: "cb828680-8895-4e08-a1fc-be63e033df17",
+		},
+		Mesh: new(geo.Mesh),
+	}
+	meshRes.Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {100, 0, 0}, {100, 100, 0}, {0, 100, 0}, {0, 0, 100.5}}
+	meshRes.Mesh.Faces = []geo.Face{
+		{NodeIndices: [3]uint32{3, 2, 1}, Resource: 5, ResourceIndices: [3]uint32{1, 1, 1}},
+		{NodeIndices: [3]uint32{1, 0, 3}, Resource: 5, ResourceIndices: [3]uint32{0, 0, 0}},
+		{NodeIndices: [3]uint32{0, 1, 4}, Resource: 2, ResourceIndices: [3]uint32{0, 1, 2}},
+		{NodeIndices: [3]uint32{1, 2, 4}, Resource: 1, ResourceIndices: [3]uint32{2, 1, 2}},
+	}
+	meshRes.Alternatives = []go3mf.Alternative{{ObjectID: 15, UUID: "cb828680-8895-4e08-a1fc-be63e033df20", Path: "/3D/low.model", ModelResolution: go3mf.ResolutionLow}}
+	meshRes.Mesh.TriangleSets = []geo.TriangleSet{{Name: "Set", Identifier: "set_id", Refs: []uint32{0, 1, 3}}, {Name: "Empty", Identifier: "empty_id"}}
+	meshLattice := &go3mf.MeshResource{
+		ObjectResource:        go3mf.ObjectResource{ID: 15, ModelPath: rootPath, ObjectType: go3mf.ObjectTypeSupport, UUID: "cb828680-8895-4e08-a1fc-be63e033df18"},
+		BeamLatticeAttributes: go3mf.BeamLatticeAttributes{ClipMode: go3mf.ClipOutside, ClippingMeshID: 8, RepresentationMeshID: 8},
+		Mesh:                  new(geo.Mesh),
+	}
+	meshLattice.Mesh.MinLength = 0.0001
+	meshLattice.Mesh.CapMode = geo.CapModeHemisphere
+	meshLattice.Mesh.DefaultRadius = 1
+	meshLattice.Mesh.Nodes = []geo.Point3D{{45, 55, 55}, {45, 45, 55}, {45, 55, 45}}
+	meshLattice.Mesh.Faces = make([]geo.Face, 0)
+	meshLattice.Mesh.Beams = []geo.Beam{
+		{NodeIndices: [2]uint32{0, 1}, Radius: [2]float64{1.5, 1.6}, CapMode: [2]geo.CapMode{geo.CapModeSphere, geo.CapModeButt}},
+		{NodeIndices: [2]uint32{2, 0}, Radius: [2]float64{1, 1}, CapMode: [2]geo.CapMode{geo.CapModeHemisphere, geo.CapModeHemisphere}},
+	}
+	meshLattice.Mesh.BeamSets = []geo.BeamSet{{Name: "test", Identifier: "set_id", Refs: []uint32{1, 0}}}
+	meshLattice.Mesh.BallMode = geo.BallModeAll
+	meshLattice.Mesh.DefaultBallRadius = 1.2
+	meshLattice.Mesh.Balls = []geo.Ball{{NodeIndex: 1, Radius: 1.2}, {NodeIndex: 0, Radius: 2}}
+	components := &go3mf.ComponentsResource{
+		ObjectResource: go3mf.ObjectResource{
+			ID: 20, UUID: "cb828680-8895-4e08-a1fc-be63e033df15", ModelPath: rootPath, ObjectType: go3mf.ObjectTypeModel,
+			Metadata: []go3mf.Metadata{{Name: nsProductionSpec + ":CustomMetadata3", Type: "xs:boolean", Value: "1"}},
+		},
+		Components: []*go3mf.Component{{UUID: "cb828680-8895-4e08-a1fc-be63e033df16", Object: meshRes,
+			Transform: geo.Matrix{3, 0, 0, 0, 0, 1, 0, 0, 0, 0, 2, 0, -66.4, -87.1, 8.8, 1}}},
+	}
+	booleanShape := &go3mf.BooleanShapeResource{
+		ObjectResource: go3mf.ObjectResource{ID: 21, UUID: "cb828680-8895-4e08-a1fc-be63e033df19", ModelPath: rootPath, Name: "Cut"},
+		Object:         meshRes,
+		Transform:      geo.Matrix{1, 0, 0, 0, 0, 1, 0, 0, 0, 0, 1, 0, 0, 0, 5, 1},
+		Operation:      go3mf.BooleanIntersection,
+		Operands:       []*go3mf.Boolean{{Object: meshRes, Transform: geo.Matrix{2, 0, 0, 0, 0, 2, 0, 0, 0, 0, 2, 0, 1, 1, 1, 1}}, {Object: meshRes}},
+	}
+	want := &go3mf.Model{
+		Path: rootPath, Units: go3mf.UnitCentimeter, Language: "en-US", UUID: "e9e25302-6428-402e-8633-cc95528d0ed3",
+		Resources: []go3mf.Resource{baseMaterials, baseTexture, colorGroup, texGroup, compositeGroup, multiGroup, sliceStack, sliceStackRef, meshRes, meshLattice, components, booleanShape},
+		Metadata: []go3mf.Metadata{
+			{Name: "Application", Value: "go3mf & co"},
+			{Name: nsProductionSpec + ":CustomMetadata1", Preserve: true, Type: "xs:string", Value: "CE8A91FB-C44E-4F00-B634-BAA411465F6A"},
+			{Name: "http://www.example.com:Other", Value: "a"},
+		},
+		Attachments: []*go3mf.Attachment{{RelationshipType: relTypeTexture3D, Path: "/3D/Texture/msLogo.png", Stream: bytes.NewReader([]byte("fake png"))}},
+	}
+	want.BuildItems = []*go3mf.BuildItem{
+		{Object: components, PartNumber: "bob", UUID: "e9e25302-6428-402e-8633-cc95528d0ed2", Transform: geo.Matrix{1, 0, 0, 0, 0, 2, 0, 0, 0, 0, 3, 0, -66.4, -87.1, 8.8, 1}},
+		{Object: meshRes, UUID: "e9e25302-6428-402e-8633-cc95528d0ed4", Metadata: []go3mf.Metadata{{Name: nsProductionSpec + ":CustomMetadata3", Type: "xs:boolean", Value: "1"}}},
+	}
+
+	t.Run("base", func(t *testing.T) {
+		buff := new(bytes.Buffer)
+		if err := NewEncoder(buff).Encode(want); err != nil {
+			t.Errorf("Encoder.Encode() unexpected error = %v", err)
+			return
+		}
+		got := new(go3mf.Model)
+		if err := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len())).Decode(got); err != nil {
+			t.Errorf("Decoder.Decode() unexpected error = %v", err)
+			return
+		}
+		deep.CompareUnexportedFields = true
+		deep.MaxDepth = 20
+		if diff := deep.Equal(got, want); diff != nil {
+			t.Errorf("Encoder.Encode() = %v", diff)
+		}
+	})
+}
+
+func TestEncoder_Encode_Thumbnail(t *testing.T) {
+	tests := []struct {
+		name  string
+		model *go3mf.Model
+		want  []*go3mf.Attachment
+	}{
+		{"thumbnail", &go3mf.Model{Thumbnail: &go3mf.Attachment{Path: "/Metadata/thumbnail.png", RelationshipType: relTypeThumbnail, Stream: bytes.NewReader([]byte("thumb"))}}, []*go3mf.Attachment{
+			{Path: "/Metadata/thumbnail.png", RelationshipType: relTypeThumbnail, Stream: bytes.NewReader([]byte("thumb"))},
+		}},
+		{"attachment", &go3mf.Model{
+			Thumbnail:   &go3mf.Attachment{Path: "/Metadata/thumbnail.png", RelationshipType: relTypeThumbnail, Stream: bytes.NewReader([]byte("thumb"))},
+			Attachments: []*go3mf.Attachment{{Path: "/Metadata/thumbnail.png", RelationshipType: relTypeThumbnail, Stream: bytes.NewReader([]byte("thumb"))}},
+		}, []*go3mf.Attachment{
+			{Path: "/Metadata/thumbnail.png", RelationshipType: relTypeThumbnail, Stream: bytes.NewReader([]byte("thumb"))},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buff := new(bytes.Buffer)
+			if err := NewEncoder(buff).Encode(tt.model); err != nil {
+				t.Errorf("Encoder.Encode() unexpected error = %v", err)
+				return
+			}
+			got := new(go3mf.Model)
+			if err := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len())).Decode(got); err != nil {
+				t.Errorf("Decoder.Decode() unexpected error = %v", err)
+				return
+			}
+			if diff := deep.Equal(got.Attachments, tt.want); diff != nil {
+				t.Errorf("Encoder.Encode() = %v", diff)
+			}
+			if got.Thumbnail == nil {
+				t.Error("Encoder.Encode() thumbnail not found")
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode_Fail(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	model := &go3mf.Model{
+		Resources:   []go3mf.Resource{&go3mf.BaseMaterialsResource{ID: 1}},
+		Attachments: []*go3mf.Attachment{{Path: "/a.png", RelationshipType: relTypeTexture3D}},
+	}
+	tests := []struct {
+		name string
+		e    *Encoder
+		ctx  context.Context
+	}{
+		{"create", &Encoder{w: newMockPackageWriter(true, false)}, context.Background()},
+		{"close", &Encoder{w: newMockPackageWriter(false, true)}, context.Background()},
+		{"canceled", &Encoder{w: newMockPackageWriter(false, false)}, ctx},
+		{"duplicatedPart", NewEncoder(new(bytes.Buffer)), context.Background()},
+		{"booleanWithoutObject", NewEncoder(new(bytes.Buffer)), context.Background()},
+		{"booleanOperandWithoutObject", NewEncoder(new(bytes.Buffer)), context.Background()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := *model
+			switch tt.name {
+			case "duplicatedPart":
+				m.Attachments = append(m.Attachments, m.Attachments[0])
+			case "booleanWithoutObject":
+				m.Resources = []go3mf.Resource{&go3mf.BooleanShapeResource{ObjectResource: go3mf.ObjectResource{ID: 1}}}
+			case "booleanOperandWithoutObject":
+				mesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 1}, Mesh: new(geo.Mesh)}
+				m.Resources = []go3mf.Resource{mesh, &go3mf.BooleanShapeResource{ObjectResource: go3mf.ObjectResource{ID: 2}, Object: mesh, Operands: []*go3mf.Boolean{{}}}}
+			}
+			if err := tt.e.EncodeContext(tt.ctx, &m); err == nil {
+				t.Error("Encoder.EncodeContext() expected error")
+			}
+		})
+	}
+}
+
+func TestEncoder_Encode_BallModeNone(t *testing.T) {
+	mesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 1}, Mesh: new(geo.Mesh)}
+	mesh.Mesh.Nodes = []geo.Point3D{{0, 0, 0}, {1, 0, 0}}
+	mesh.Mesh.DefaultRadius = 1
+	mesh.Mesh.Beams = []geo.Beam{{NodeIndices: [2]uint32{0, 1}, Radius: [2]float64{1, 1}}}
+	mesh.Mesh.Balls = []geo.Ball{{NodeIndex: 1, Radius: 2}}
+	model := &go3mf.Model{Path: "/3D/3dmodel.model", Resources: []go3mf.Resource{mesh}}
+	buff := new(bytes.Buffer)
+	mw := modelWriter{model: model, path: model.Path, isRoot: true}
+	if err := mw.Encode(context.Background(), buff); err == nil {
+		t.Error("modelWriter.Encode() expected error with balls and ballmode none")
+	}
+}
+
+func TestEncoder_roundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		f    *modelBuilder
+	}{
+		{"full", rootModelFixture()},
+		{"empty", new(modelBuilder).withDefaultModel()},
+		{"coreOnly", new(modelBuilder).withElement(`<model unit="inch" xml:lang="es-ES" xmlns="` + nsCoreSpec + `" xmlns:qm="http://www.qmuntal.com">
+			<metadata name="qm:Other" preserve="1">a b</metadata>
+			<resources>
+				<object id="1" type="support">
+					<mesh>
+						<vertices><vertex x="0" y="0" z="0" /><vertex x="1.5" y="0" z="0" /><vertex x="0" y="1.5" z="0" /></vertices>
+						<triangles><triangle v1="0" v2="1" v3="2" /></triangles>
+					</mesh>
+				</object>
+			</resources>
+			<build><item objectid="1" transform="1 0 0 0 1 0 0 0 1 0.5 0.5 0.5" /></build>
+			</model>`)},
+		{"ballModeNone", new(modelBuilder).withDefaultModel().withElement(`
+			<resources>
+				<object id="1">
+					<mesh>
+						<vertices><vertex x="0" y="0" z="0" /><vertex x="1" y="0" z="0" /></vertices>
+						<b:beamlattice radius="1" minlength="0.0001" b2:ballmode="none">
+							<b:beams><b:beam v1="0" v2="1" /></b:beams>
+							<b2:balls><b2:ball vindex="1" r="2" /></b2:balls>
+						</b:beamlattice>
+					</mesh>
+				</object>
+			</resources>
+			<build><item objectid="1" /></build>`)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want, got := newFixtureModel(), newFixtureModel()
+			if err := new(Decoder).processRootModel(context.Background(), tt.f.build(), want); err != nil {
+				t.Errorf("Decoder.processRootModel() unexpected error = %v", err)
+				return
+			}
+			buff := new(bytes.Buffer)
+			mw := modelWriter{model: want, path: want.Path, isRoot: true}
+			if err := mw.Encode(context.Background(), buff); err != nil {
+				t.Errorf("modelWriter.Encode() unexpected error = %v", err)
+				return
+			}
+			f := new(mockFile)
+			f.On("Name").Return(want.Path).Maybe()
+			f.On("Open").Return(ioutil.NopCloser(buff), nil).Maybe()
+			if err := new(Decoder).processRootModel(context.Background(), f, got); err != nil {
+				t.Errorf("Decoder.processRootModel() unexpected error = %v", err)
+				return
+			}
+			deep.CompareUnexportedFields = true
+			deep.MaxDepth = 20
+			if diff := deep.Equal(got, want); diff != nil {
+				t.Errorf("Encoder.Encode() = %v", diff)
+			}
+		})
+	}
+}
+
+func TestEncoder_roundTrip_Parts(t *testing.T) {
+	want := newFixtureModel()
+	want.Resources = append(want.Resources, &go3mf.Texture2DResource{ID: 1, ModelPath: "/3d/other.model", Path: "/3D/Texture/other.png", ContentType: go3mf.TextureTypePNG})
+	want.Attachments = append(want.Attachments, &go3mf.Attachment{RelationshipType: relTypeTexture3D, Path: "/3D/Texture/other.png", Stream: bytes.NewReader([]byte("other"))})
+	if err := new(Decoder).processRootModel(context.Background(), rootModelFixture().build(), want); err != nil {
+		t.Fatalf("Decoder.processRootModel() unexpected error = %v", err)
+	}
+	buff := new(bytes.Buffer)
+	if err := NewEncoder(buff).Encode(want); err != nil {
+		t.Fatalf("Encoder.Encode() unexpected error = %v", err)
+	}
+	got := new(go3mf.Model)
+	if err := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len())).Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() unexpected error = %v", err)
+	}
+	want.ProductionAttachments = []*go3mf.ProductionAttachment{
+		{RelationshipType: relTypeModel3D, Path: "/2D/2Dmodel.model"},
+		{RelationshipType: relTypeModel3D, Path: "/3d/other.model"},
+	}
+	deep.CompareUnexportedFields = true
+	deep.MaxDepth = 20
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Encoder.Encode() = %v", diff)
+	}
+}
+
+func TestEncoder_roundTrip_Attachments(t *testing.T) {
+	model := &go3mf.Model{
+		Thumbnail: &go3mf.Attachment{Path: "/Metadata/thumb.png", RelationshipType: relTypeThumbnail, Stream: bytes.NewReader([]byte("thumb"))},
+		Attachments: []*go3mf.Attachment{
+			{Path: "/3D/Texture/a.png", RelationshipType: relTypeTexture3D, Stream: bytes.NewReader([]byte("a"))},
+			{Path: "/3D/Texture/b.png", RelationshipType: relTypeTexture3D, Stream: bytes.NewReader([]byte("b"))},
+			{Path: "/Metadata/a.xml", RelationshipType: "custom", Stream: bytes.NewReader([]byte("<a/>"))},
+			{Path: "/Metadata/b.xml", RelationshipType: "custom", Stream: bytes.NewReader([]byte("<b/>"))},
+		},
+	}
+	decode := func(model *go3mf.Model) *go3mf.Model {
+		buff := new(bytes.Buffer)
+		if err := NewEncoder(buff).Encode(model); err != nil {
+			t.Fatalf("Encoder.Encode() unexpected error = %v", err)
+		}
+		got := new(go3mf.Model)
+		d := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len()))
+		d.AttachmentRelations = []string{"custom"}
+		if err := d.Decode(got); err != nil {
+			t.Fatalf("Decoder.Decode() unexpected error = %v", err)
+		}
+		return got
+	}
+	want := decode(model)
+	// The decoder also lists the thumbnail as an attachment.
+	if len(want.Attachments) != 5 || want.Thumbnail == nil || want.Thumbnail.Path != "/Metadata/thumb.png" {
+		t.Fatalf("Decoder.Decode() attachments not preserved = %v", want.Attachments)
+	}
+	got := decode(want)
+	deep.CompareUnexportedFields = true
+	deep.MaxDepth = 20
+	if diff := deep.Equal(got, want); diff != nil {
+		t.Errorf("Encoder.Encode() = %v", diff)
+	}
+}
+
+// gridStream generates a flat grid of n x n vertices.
+type gridStream struct {
+	n   uint32
+	err error
+}
+
+func (g *gridStream) Vertices(yield func(geo.Point3D) error) error {
+	for i := uint32(0); i < g.n*g.n; i++ {
+		if err := yield(geo.Point3D{float32(i % g.n), float32(i / g.n), 0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *gridStream) Faces(yield func(geo.Face) error) error {
+	if g.err != nil {
+		return g.err
+	}
+	for y := uint32(0); y < g.n-1; y++ {
+		for x := uint32(0); x < g.n-1; x++ {
+			i := y*g.n + x
+			if err := yield(geo.Face{NodeIndices: [3]uint32{i, i + 1, i + g.n}}); err != nil {
+				return err
+			}
+			if err := yield(geo.Face{NodeIndices: [3]uint32{i + 1, i + g.n + 1, i + g.n}, Resource: 1, ResourceIndices: [3]uint32{1, 1, 1}}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func TestEncoder_SetMeshStream(t *testing.T) {
+	stream := &gridStream{n: 50}
+	want := new(geo.Mesh)
+	stream.Vertices(func(n geo.Point3D) error {
+		want.Nodes = append(want.Nodes, n)
+		return nil
+	})
+	stream.Faces(func(f geo.Face) error {
+		want.Faces = append(want.Faces, f)
+		return nil
+	})
+	want.TriangleSets = []geo.TriangleSet{{Name: "set", Identifier: "id", Refs: []uint32{0, 3}}}
+	mesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 2}, Mesh: new(geo.Mesh)}
+	mesh.Mesh.TriangleSets = want.TriangleSets
+	model := &go3mf.Model{Resources: []go3mf.Resource{&go3mf.BaseMaterialsResource{ID: 1, Materials: []go3mf.BaseMaterial{{Name: "a"}, {Name: "b"}}}, mesh}}
+	model.BuildItems = append(model.BuildItems, &go3mf.BuildItem{Object: mesh})
+
+	buff := new(bytes.Buffer)
+	e := NewEncoder(buff)
+	e.SetMeshStream(mesh, stream)
+	if err := e.Encode(model); err != nil {
+		t.Fatalf("Encoder.Encode() unexpected error = %v", err)
+	}
+	got := new(go3mf.Model)
+	if err := NewDecoder(bytes.NewReader(buff.Bytes()), int64(buff.Len())).Decode(got); err != nil {
+		t.Fatalf("Decoder.Decode() unexpected error = %v", err)
+	}
+	r, ok := got.FindResource(got.Path, 2)
+	if !ok {
+		t.Fatal("Encoder.Encode() streamed mesh not found")
+	}
+	deep.CompareUnexportedFields = true
+	deep.MaxDepth = 20
+	if diff := deep.Equal(r.(*go3mf.MeshResource).Mesh, want); diff != nil {
+		t.Errorf("Encoder.Encode() = %v", diff)
+	}
+}
+
+type errWriter struct {
+	err error
+}
+
+func (w *errWriter) Write([]byte) (int, error) {
+	return 0, w.err
+}
+
+func TestEncoder_SetMeshStream_Fail(t *testing.T) {
+	streamErr, writeErr := errors.New("stream error"), errors.New("write error")
+	failWriter := new(mockPackageWriter)
+	failWriter.On("AddRelationship", mock.Anything).Return()
+	failWriter.On("Create", mock.Anything, mock.Anything, mock.Anything).Return(&errWriter{writeErr}, nil)
+	tests := []struct {
+		name   string
+		w      packageWriter
+		stream MeshStream
+		want   error
+	}{
+		{"stream", newOpcWriter(new(bytes.Buffer)), &gridStream{n: 3, err: streamErr}, streamErr},
+		{"write", failWriter, &gridStream{n: 1000}, writeErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mesh := &go3mf.MeshResource{ObjectResource: go3mf.ObjectResource{ID: 1}}
+			e := &Encoder{w: tt.w}
+			e.SetMeshStream(mesh, tt.stream)
+			if err := e.Encode(&go3mf.Model{Resources: []go3mf.Resource{mesh}}); err != tt.want {
+				t.Errorf("Encoder.Encode() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func Test_modelPaths(t *testing.T) {
+	tests := []struct {
+		name  string
+		model *go3mf.Model
+		want  []string
+	}{
+		{"empty", new(go3mf.Model), nil},
+		{"root", &go3mf.Model{Path: "/3D/a.model", Resources: []go3mf.Resource{
+			&go3mf.BaseMaterialsResource{ModelPath: "/3D/a.model"}, &go3mf.BaseMaterialsResource{},
+		}}, nil},
+		{"parts", &go3mf.Model{
+			ProductionAttachments: []*go3mf.ProductionAttachment{{Path: "/3D/c.model"}},
+			Resources: []go3mf.Resource{
+				&go3mf.BaseMaterialsResource{ModelPath: "/3D/b.model"}, &go3mf.BaseMaterialsResource{ModelPath: uriDefault3DModel},
+				&go3mf.BaseMaterialsResource{ModelPath: "/3D/c.model"}, &go3mf.BaseMaterialsResource{ModelPath: "/3D/b.model"},
+			}}, []string{"/3D/c.model", "/3D/b.model"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rootPath := tt.model.Path
+			if rootPath == "" {
+				rootPath = uriDefault3DModel
+			}
+			if diff := deep.Equal(modelPaths(tt.model, rootPath), tt.want); diff != nil {
+				t.Errorf("modelPaths() = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_attachmentRelationships(t *testing.T) {
+	model := &go3mf.Model{
+		Resources: []go3mf.Resource{
+			&go3mf.Texture2DResource{ModelPath: "/3D/b.model", Path: "/a.png"},
+			&go3mf.Texture2DResource{ModelPath: "/3D/b.model", Path: "/b.png"},
+			&go3mf.Texture2DResource{Path: "/b.png"},
+			&go3mf.Displacement2DResource{ModelPath: "/3D/d.model", Path: "/d.png"},
+		},
+		Attachments: []*go3mf.Attachment{
+			{RelationshipType: relTypeTexture3D, Path: "/a.png"},
+			{RelationshipType: relTypeTexture3D, Path: "/b.png"},
+			{RelationshipType: "other", Path: "/a.png"},
+			{RelationshipType: relTypeTexture3D, Path: "/c.png"},
+			{RelationshipType: relTypeTexture3D, Path: "/d.png"},
+		},
+	}
+	tests := []struct {
+		name string
+		path string
+		want []relationship
+	}{
+		{"root", uriDefault3DModel, []relationship{
+			&packageRelationship{relType: relTypeTexture3D, targetURI: "/b.png"},
+			&packageRelationship{relType: "other", targetURI: "/a.png"},
+			&packageRelationship{relType: relTypeTexture3D, targetURI: "/c.png"},
+		}},
+		{"part", "/3D/b.model", []relationship{&packageRelationship{relType: relTypeTexture3D, targetURI: "/a.png"}}},
+		{"displacement", "/3D/d.model", []relationship{&packageRelationship{relType: relTypeTexture3D, targetURI: "/d.png"}}},
+		{"empty", "/3D/c.model", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deep.CompareUnexportedFields = true
+			if diff := deep.Equal(attachmentRelationships(model, uriDefault3DModel, tt.path), tt.want); diff != nil {
+				t.Errorf("attachmentRelationships() = %v", diff)
+			}
+		})
+	}
+}
+
+func Test_formatMatrix(t *testing.T) {
+	tests := []struct {
+		name string
+		t    geo.Matrix
+		want string
+	}{
+		{"identity", geo.Identity(), "1 0 0 0 1 0 0 0 1 0 0 0"},
+		{"other", geo.Matrix{0, 1, 2, 0, 10, 11, 12, 0, 20, 21, 22, 0, 30.5, -31, 32.1, 1}, "0 1 2 10 11 12 20 21 22 30.5 -31 32.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatMatrix(tt.t); got != tt.want {
+				t.Errorf("formatMatrix() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_formatRGBA(t *testing.T) {
+	tests := []struct {
+		name string
+		c    color.RGBA
+		want string
+	}{
+		{"black", color.RGBA{0, 0, 0, 255}, "#000000ff"},
+		{"other", color.RGBA{200, 250, 60, 8}, "#c8fa3c08"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := formatRGBA(tt.c); got != tt.want {
+				t.Errorf("formatRGBA() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_attachmentContentType(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/a.png", "image/png"},
+		{"/a.JPG", "image/jpeg"},
+		{"/a.jpeg", "image/jpeg"},
+		{"/3D/a.model", contentType3DModel},
+		{"/a.xml", "application/xml"},
+		{"/a.bin", "application/octet-stream"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := attachmentContentType(tt.path); got != tt.want {
+				t.Errorf("attachmentContentType() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -110,10 +110,11 @@ func (t TextureCoord) V() float32 {
 
 // Texture2DGroupResource acts as a container for texture coordinate properties.
 type Texture2DGroupResource struct {
-	ID        uint32
-	ModelPath string
-	TextureID uint32
-	Coords    []TextureCoord
+	ID                  uint32
+	ModelPath           string
+	TextureID           uint32
+	DisplayPropertiesID uint32
+	Coords              []TextureCoord
 }
 
 // Identify returns the unique ID of the resource.
@@ -123,9 +124,10 @@ func (t *Texture2DGroupResource) Identify() (string, uint32) {
 
 // ColorGroupResource acts as a container for color properties.
 type ColorGroupResource struct {
-	ID        uint32
-	ModelPath string
-	Colors    []color.RGBA
+	ID                  uint32
+	ModelPath           string
+	DisplayPropertiesID uint32
+	Colors              []color.RGBA
 }
 
 // Identify returns the unique ID of the resource.
@@ -171,3 +173,97 @@ type MultiPropertiesResource struct {
 func (c *MultiPropertiesResource) Identify() (string, uint32) {
 	return c.ModelPath, c.ID
 }
+
+// PBSpecular defines the display properties of a material using the specular workflow.
+type PBSpecular struct {
+	Name          string
+	SpecularColor color.RGBA
+	Glossiness    float64
+}
+
+// PBSpecularDisplayPropertiesResource acts as a container for specular display properties.
+type PBSpecularDisplayPropertiesResource struct {
+	ID         uint32
+	ModelPath  string
+	Properties []PBSpecular
+}
+
+// Identify returns the unique ID of the resource.
+func (p *PBSpecularDisplayPropertiesResource) Identify() (string, uint32) {
+	return p.ModelPath, p.ID
+}
+
+// PBMetallic defines the display properties of a material using the metallic workflow.
+type PBMetallic struct {
+	Name         string
+	Metallicness float64
+	Roughness    float64
+}
+
+// PBMetallicDisplayPropertiesResource acts as a container for metallic display properties.
+type PBMetallicDisplayPropertiesResource struct {
+	ID         uint32
+	ModelPath  string
+	Properties []PBMetallic
+}
+
+// Identify returns the unique ID of the resource.
+func (p *PBMetallicDisplayPropertiesResource) Identify() (string, uint32) {
+	return p.ModelPath, p.ID
+}
+
+// PBSpecularTextureDisplayPropertiesResource defines the specular display properties
+// of a texture group using a specular and a glossiness texture.
+type PBSpecularTextureDisplayPropertiesResource struct {
+	ID                  uint32
+	ModelPath           string
+	Name                string
+	SpecularTextureID   uint32
+	GlossinessTextureID uint32
+	DiffuseFactor       color.RGBA
+	SpecularFactor      color.RGBA
+	GlossinessFactor    float64
+}
+
+// Identify returns the unique ID of the resource.
+func (p *PBSpecularTextureDisplayPropertiesResource) Identify() (string, uint32) {
+	return p.ModelPath, p.ID
+}
+
+// PBMetallicTextureDisplayPropertiesResource defines the metallic display properties
+// of a texture group using a metallic and a roughness texture.
+type PBMetallicTextureDisplayPropertiesResource struct {
+	ID                 uint32
+	ModelPath          string
+	Name               string
+	MetallicTextureID  uint32
+	RoughnessTextureID uint32
+	MetallicFactor     float64
+	RoughnessFactor    float64
+}
+
+// Identify returns the unique ID of the resource.
+func (p *PBMetallicTextureDisplayPropertiesResource) Identify() (string, uint32) {
+	return p.ModelPath, p.ID
+}
+
+// Translucent defines the display properties of a translucent material.
+// Attenuation and RefractiveIndex hold the red, green and blue components.
+type Translucent struct {
+	Name            string
+	Attenuation     [3]float64
+	RefractiveIndex [3]float64
+	Roughness       float64
+}
+
+// TranslucentDisplayPropertiesResource acts as a container for translucent display properties.
+type TranslucentDisplayPropertiesResource struct {
+	ID         uint32
+	ModelPath  string
+	Properties []Translucent
+}
+
+// Identify returns the unique ID of the resource.
+func (t *TranslucentDisplayPropertiesResource) Identify() (string, uint32) {
+	return t.ModelPath, t.ID
+}
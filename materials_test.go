@@ -236,3 +236,113 @@ func TestMultiPropertiesResource_Identify(t *testing.T) {
 		})
 	}
 }
+
+func TestPBSpecularDisplayPropertiesResource_Identify(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     *PBSpecularDisplayPropertiesResource
+		want  string
+		want1 uint32
+	}{
+		{"base", &PBSpecularDisplayPropertiesResource{ID: 1, ModelPath: "3d/3dmodel.model"}, "3d/3dmodel.model", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.p.Identify()
+			if got != tt.want {
+				t.Errorf("PBSpecularDisplayPropertiesResource.Identify() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("PBSpecularDisplayPropertiesResource.Identify() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestPBMetallicDisplayPropertiesResource_Identify(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     *PBMetallicDisplayPropertiesResource
+		want  string
+		want1 uint32
+	}{
+		{"base", &PBMetallicDisplayPropertiesResource{ID: 1, ModelPath: "3d/3dmodel.model"}, "3d/3dmodel.model", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.p.Identify()
+			if got != tt.want {
+				t.Errorf("PBMetallicDisplayPropertiesResource.Identify() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("PBMetallicDisplayPropertiesResource.Identify() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestPBSpecularTextureDisplayPropertiesResource_Identify(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     *PBSpecularTextureDisplayPropertiesResource
+		want  string
+		want1 uint32
+	}{
+		{"base", &PBSpecularTextureDisplayPropertiesResource{ID: 1, ModelPath: "3d/3dmodel.model"}, "3d/3dmodel.model", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.p.Identify()
+			if got != tt.want {
+				t.Errorf("PBSpecularTextureDisplayPropertiesResource.Identify() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("PBSpecularTextureDisplayPropertiesResource.Identify() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestPBMetallicTextureDisplayPropertiesResource_Identify(t *testing.T) {
+	tests := []struct {
+		name  string
+		p     *PBMetallicTextureDisplayPropertiesResource
+		want  string
+		want1 uint32
+	}{
+		{"base", &PBMetallicTextureDisplayPropertiesResource{ID: 1, ModelPath: "3d/3dmodel.model"}, "3d/3dmodel.model", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.p.Identify()
+			if got != tt.want {
+				t.Errorf("PBMetallicTextureDisplayPropertiesResource.Identify() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("PBMetallicTextureDisplayPropertiesResource.Identify() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
+
+func TestTranslucentDisplayPropertiesResource_Identify(t *testing.T) {
+	tests := []struct {
+		name  string
+		r     *TranslucentDisplayPropertiesResource
+		want  string
+		want1 uint32
+	}{
+		{"base", &TranslucentDisplayPropertiesResource{ID: 1, ModelPath: "3d/3dmodel.model"}, "3d/3dmodel.model", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, got1 := tt.r.Identify()
+			if got != tt.want {
+				t.Errorf("TranslucentDisplayPropertiesResource.Identify() got = %v, want %v", got, tt.want)
+			}
+			if got1 != tt.want1 {
+				t.Errorf("TranslucentDisplayPropertiesResource.Identify() got1 = %v, want %v", got1, tt.want1)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package go3mf
+
+// Compression defines the compression applied to an encrypted part before encrypting it.
+type Compression uint8
+
+const (
+	// CompressionNone defines an uncompressed part.
+	CompressionNone Compression = iota
+	// CompressionDeflate defines a part compressed with deflate.
+	CompressionDeflate
+)
+
+func (c Compression) String() string {
+	return map[Compression]string{
+		CompressionNone:    "none",
+		CompressionDeflate: "deflate",
+	}[c]
+}
+
+// Consumer identifies a recipient of the encrypted content,
+// which owns the key pair used to wrap the content encryption keys.
+type Consumer struct {
+	ConsumerID string
+	KeyID      string
+	KeyValue   string
+}
+
+// KEKParams defines the algorithms used to wrap a content encryption key.
+type KEKParams struct {
+	WrappingAlgorithm string
+	MgfAlgorithm      string
+	DigestMethod      string
+}
+
+// An AccessRight grants a consumer access to the content encryption key of a resource data group.
+type AccessRight struct {
+	ConsumerIndex uint32
+	KEKParams     KEKParams
+	CipherValue   []byte
+}
+
+// CEKParams defines how the content of an encrypted part has been encrypted.
+type CEKParams struct {
+	EncryptionAlgorithm string
+	Compression         Compression
+	IV                  []byte
+	Tag                 []byte
+	AAD                 []byte
+}
+
+// ResourceData defines an encrypted part of the package.
+type ResourceData struct {
+	Path      string
+	CEKParams CEKParams
+}
+
+// A ResourceDataGroup groups the parts that are encrypted with the same content encryption key.
+type ResourceDataGroup struct {
+	KeyUUID       string
+	AccessRights  []AccessRight
+	ResourceDatas []ResourceData
+}
+
+// A KeyStore is an in memory representation of the secure content keystore,
+// which lists the encrypted parts of the package and who can decrypt them.
+type KeyStore struct {
+	UUID               string
+	Consumers          []Consumer
+	ResourceDataGroups []ResourceDataGroup
+}
+
+// FindResourceData returns the resource data and the group of the encrypted part with the target path.
+func (k *KeyStore) FindResourceData(path string) (*ResourceDataGroup, *ResourceData, bool) {
+	for i := range k.ResourceDataGroups {
+		g := &k.ResourceDataGroups[i]
+		for j := range g.ResourceDatas {
+			if g.ResourceDatas[j].Path == path {
+				return g, &g.ResourceDatas[j], true
+			}
+		}
+	}
+	return nil, nil, false
+}
@@ -0,0 +1,55 @@
+package go3mf
+
+import (
+	"testing"
+)
+
+func TestKeyStore_FindResourceData(t *testing.T) {
+	ks := &KeyStore{ResourceDataGroups: []ResourceDataGroup{
+		{KeyUUID: "a", ResourceDatas: []ResourceData{{Path: "/3D/3dmodel.model"}}},
+		{KeyUUID: "b", ResourceDatas: []ResourceData{{Path: "/3D/other.model"}, {Path: "/3D/Texture/a.png"}}},
+	}}
+	tests := []struct {
+		name      string
+		path      string
+		wantGroup *ResourceDataGroup
+		wantData  *ResourceData
+		wantOk    bool
+	}{
+		{"empty", "", nil, nil, false},
+		{"notFound", "/3D/b.model", nil, nil, false},
+		{"first", "/3D/3dmodel.model", &ks.ResourceDataGroups[0], &ks.ResourceDataGroups[0].ResourceDatas[0], true},
+		{"other", "/3D/Texture/a.png", &ks.ResourceDataGroups[1], &ks.ResourceDataGroups[1].ResourceDatas[1], true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, data, ok := ks.FindResourceData(tt.path)
+			if group != tt.wantGroup {
+				t.Errorf("KeyStore.FindResourceData() group = %v, want %v", group, tt.wantGroup)
+			}
+			if data != tt.wantData {
+				t.Errorf("KeyStore.FindResourceData() data = %v, want %v", data, tt.wantData)
+			}
+			if ok != tt.wantOk {
+				t.Errorf("KeyStore.FindResourceData() ok = %v, want %v", ok, tt.wantOk)
+			}
+		})
+	}
+}
+
+func TestCompression_String(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Compression
+	}{
+		{"none", CompressionNone},
+		{"deflate", CompressionDeflate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.c.String(); got != tt.name {
+				t.Errorf("Compression.String() = %v, want %v", got, tt.name)
+			}
+		})
+	}
+}